@@ -0,0 +1,182 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"google.golang.org/grpc/status"
+)
+
+// ChangePassword client command for changing user password, sessions on other devices are revoked.
+// With "--srp" flag account is switched to zero-knowledge login. Password of account logged in
+// with zero-knowledge login is proved by SRP-6a exchange and never sent to server.
+func (c *Command) ChangePassword(ctx context.Context, args []string) error {
+	var useSRP bool
+	fs := flag.NewFlagSet("passwd", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&useSRP, "srp", false, "")
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Change password arguments error: %s\n", err.Error())
+		return nil
+	}
+
+	cred, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	var login string
+	if useSRP || cred.SRP {
+		fmt.Print("Login: ")
+		if _, err := fmt.Scanln(&login); err != nil {
+			return err
+		}
+	}
+	fmt.Print("Current password: ")
+	b, err := terminal.ReadPassword(0)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	oldPassword := string(b)
+	fmt.Print("New password: ")
+	b, err = terminal.ReadPassword(0)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	newPassword := string(b)
+	fmt.Print("Repeat new password: ")
+	b, err = terminal.ReadPassword(0)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	if string(b) != newPassword {
+		fmt.Println("Change password error: new passwords do not match")
+		return nil
+	}
+	if !confirm("Sessions on other devices will be revoked, continue?") {
+		return nil
+	}
+
+	switch {
+	case cred.SRP:
+		err = c.client.ChangeSRPPassword(ctx, cred.Token, login, oldPassword, newPassword)
+	case useSRP:
+		err = c.client.EnableSRP(ctx, cred.Token, login, oldPassword, newPassword)
+	default:
+		err = c.client.ChangePassword(ctx, cred.Token, oldPassword, newPassword)
+	}
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Change password error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	if useSRP && !cred.SRP {
+		if err := enableSRP(); err != nil {
+			return fmt.Errorf("saving credentials: %w", err)
+		}
+	}
+	fmt.Println("Password successfully changed")
+
+	return nil
+}
+
+// DeleteAccount client command for deleting user account with all items, saved credentials are removed.
+// Password of account logged in with zero-knowledge login is proved by SRP-6a exchange.
+func (c *Command) DeleteAccount(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("account delete", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Delete account arguments error: %s\n", err.Error())
+		return nil
+	}
+
+	cred, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	if !confirm("Account and all its items will be deleted permanently, continue?") {
+		return nil
+	}
+	login, password, err := readCurrentPassword(cred)
+	if err != nil {
+		return err
+	}
+
+	if cred.SRP {
+		err = c.client.DeleteAccountSRP(ctx, cred.Token, login, password)
+	} else {
+		err = c.client.DeleteAccount(ctx, cred.Token, password)
+	}
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Delete account error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	if err := removeCredentials(); err != nil {
+		return err
+	}
+	fmt.Println("Account is deleted")
+
+	return nil
+}
+
+// EnableZeroKnowledge client command for hiding item names, types and metadata of existing vault from server.
+func (c *Command) EnableZeroKnowledge(ctx context.Context) error {
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	if !confirm("Items will be hidden from server and their previous versions removed, continue?") {
+		return nil
+	}
+	if err := c.client.EnableZeroKnowledge(ctx, token, secret); err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Enabling zero-knowledge mode error: %s\n", s.Message())
+			fmt.Println("Run account zero-knowledge again to finish migration")
+			return nil
+		}
+		return err
+	}
+	fmt.Println("Zero-knowledge mode is enabled")
+
+	return nil
+}
+
+// readCurrentPassword prompt password confirming account changes, login is prompted too
+// for account with zero-knowledge login since password is proved by SRP-6a exchange.
+func readCurrentPassword(cred credentials) (string, string, error) {
+	var login string
+	if cred.SRP {
+		fmt.Print("Login: ")
+		if _, err := fmt.Scanln(&login); err != nil {
+			return "", "", err
+		}
+	}
+	fmt.Print("Password: ")
+	b, err := terminal.ReadPassword(0)
+	if err != nil {
+		return "", "", err
+	}
+	fmt.Println()
+	return login, string(b), nil
+}
+
+// confirm prompt question and return true when user answers yes.
+func confirm(question string) bool {
+	var answer string
+	fmt.Printf("%s [y/N]: ", question)
+	//goland:noinspection GoUnhandledErrorResult
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
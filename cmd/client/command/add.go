@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -153,6 +154,10 @@ func (c *Command) Add(ctx context.Context, name string, itemType string) error {
 
 	err = c.client.Add(ctx, token, secret, item)
 	if err != nil {
+		if errors.Is(err, services.ErrOfflineQueued) {
+			fmt.Printf("Item %s added locally: %s\n", name, err.Error())
+			return nil
+		}
 		return err
 	}
 
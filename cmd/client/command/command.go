@@ -3,8 +3,10 @@ package command
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 
 	"go.uber.org/zap"
@@ -14,12 +16,36 @@ import (
 
 // ClientService interface describe requirements for client service
 type ClientService interface {
-	Register(ctx context.Context, login, password string) (string, error)
-	Login(ctx context.Context, login, password string) (string, error)
-	List(ctx context.Context, token string) ([]string, error)
+	Register(ctx context.Context, login, password, secret string) (services.LoginResult, error)
+	Login(ctx context.Context, login, password, secret string, otpCode func() (string, error)) (services.LoginResult, error)
+	RegisterSRP(ctx context.Context, login, password, secret string) (services.LoginResult, error)
+	LoginSRP(ctx context.Context, login, password, secret string, otpCode func() (string, error)) (services.LoginResult, error)
+	RotateSecret(ctx context.Context, token, secret, newSecret string) (services.LoginResult, error)
+	Logout(ctx context.Context, token string) error
+	RevokeAllSessions(ctx context.Context, token string) error
+	StartOTPEnrollment(ctx context.Context, token string, password string) (string, error)
+	StartOTPEnrollmentSRP(ctx context.Context, token string, login string, password string) (string, error)
+	ConfirmOTPEnrollment(ctx context.Context, token string, code string) ([]string, error)
+	DisableOTP(ctx context.Context, token string, password string, code string) error
+	DisableOTPSRP(ctx context.Context, token string, login string, password string, code string) error
+	ChangePassword(ctx context.Context, token string, oldPassword string, newPassword string) error
+	DeleteAccount(ctx context.Context, token string, password string) error
+	EnableSRP(ctx context.Context, token string, login string, password string, newPassword string) error
+	ChangeSRPPassword(ctx context.Context, token string, login string, password string, newPassword string) error
+	DeleteAccountSRP(ctx context.Context, token string, login string, password string) error
+	ListSessions(ctx context.Context, token string) ([]services.Session, error)
+	RevokeSession(ctx context.Context, token string, id string) error
+	List(ctx context.Context, token string, secret string, pageSize int, pageToken string) ([]services.ItemSummary, string, error)
+	Search(ctx context.Context, token string, secret string, filter services.ItemFilter) ([]services.ItemSummary, error)
 	Get(ctx context.Context, token string, secret string, name string) (services.Item, error)
 	Add(ctx context.Context, token string, secret string, item services.Item) error
-	Delete(ctx context.Context, token string, name string) error
+	Update(ctx context.Context, token string, secret string, item services.Item) error
+	Delete(ctx context.Context, token string, secret string, name string) error
+	EnableZeroKnowledge(ctx context.Context, token string, secret string) error
+	History(ctx context.Context, token string, secret string, name string) ([]services.ItemVersion, error)
+	Restore(ctx context.Context, token string, secret string, name string, version int64) error
+	Sync(ctx context.Context, token string, secret string) (services.SyncResult, error)
+	Watch(ctx context.Context, token string, secret string, handler func(event services.ItemEvent) error) error
 }
 
 // Command implement logic for client commands.
@@ -36,22 +62,114 @@ func NewCommand(log *zap.SugaredLogger, client ClientService) *Command {
 	}
 }
 
+const (
+	// credentialsVersion is changed when stored secret format changes, older credentials require new login.
+	credentialsVersion = 2
+	credentialsFile    = ".credentials"
+)
+
+// credentials saved after login, SRP is set for accounts with zero-knowledge login,
+// so their password is never sent to server from this client. ZeroKnowledge is set for vault hiding
+// item identity from server, so it is never used as plain vault by this client.
 type credentials struct {
-	Token  string `json:"token"`
-	Secret string `json:"secret"`
+	Version       int    `json:"version"`
+	Token         string `json:"token"`
+	RefreshToken  string `json:"refresh_token"`
+	Secret        string `json:"secret"`
+	SRP           bool   `json:"srp,omitempty"`
+	ZeroKnowledge bool   `json:"zero_knowledge,omitempty"`
+}
+
+// CredentialsTokenStore keep tokens refreshed by client in saved credentials.
+type CredentialsTokenStore struct{}
+
+// RefreshToken return refresh token from saved credentials.
+func (CredentialsTokenStore) RefreshToken() (string, error) {
+	cred, err := loadCredentials()
+	if err != nil {
+		return "", err
+	}
+	return cred.RefreshToken, nil
+}
+
+// UpdateToken replace access and refresh tokens in saved credentials.
+func (CredentialsTokenStore) UpdateToken(token string, refreshToken string) error {
+	cred, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	cred.Token = token
+	cred.RefreshToken = refreshToken
+	return writeCredentials(cred)
 }
 
-func saveCredentials(token string, secret string) error {
+// CredentialsVaultStore keep zero-knowledge mode of vault in saved credentials with the same vault key.
+// Credentials which can't be read belong to no vault, so mode is pinned only after login saves them.
+type CredentialsVaultStore struct{}
+
+// ZeroKnowledge report that vault with given key is pinned as zero-knowledge one.
+func (CredentialsVaultStore) ZeroKnowledge(secret string) (bool, error) {
+	cred, err := loadCredentials()
+	if err != nil {
+		return false, nil
+	}
+	return cred.ZeroKnowledge && cred.Secret == secret, nil
+}
+
+// SetZeroKnowledge pin vault with given key as zero-knowledge one.
+func (CredentialsVaultStore) SetZeroKnowledge(secret string) error {
+	cred, err := loadCredentials()
+	if err != nil || cred.Secret != secret {
+		return nil
+	}
+	cred.ZeroKnowledge = true
+	return writeCredentials(cred)
+}
+
+func saveCredentials(result services.LoginResult) error {
 	cred := credentials{
-		Token:  token,
-		Secret: secret,
+		Version:       credentialsVersion,
+		Token:         result.Token,
+		RefreshToken:  result.RefreshToken,
+		Secret:        result.Key,
+		SRP:           result.SRP,
+		ZeroKnowledge: result.ZeroKnowledge,
+	}
+	return writeCredentials(cred)
+}
+
+// updateSecret replace secret in saved credentials keeping tokens that could be refreshed meanwhile.
+func updateSecret(secret string) error {
+	cred, err := loadCredentials()
+	if err != nil {
+		return err
 	}
-	f, err := os.Create(".credentials")
+	cred.Secret = secret
+	return writeCredentials(cred)
+}
+
+// enableSRP mark saved credentials as credentials of account with zero-knowledge login.
+func enableSRP() error {
+	cred, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	cred.SRP = true
+	return writeCredentials(cred)
+}
+
+// writeCredentials save credentials to file readable only by its owner,
+// mode of file created by older versions is fixed too.
+func writeCredentials(cred credentials) error {
+	f, err := os.OpenFile(credentialsFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return fmt.Errorf("creating credentials file: %w", err)
 	}
 	//goland:noinspection GoUnhandledErrorResult
 	defer f.Close()
+	if err := f.Chmod(0600); err != nil {
+		return fmt.Errorf("creating credentials file: %w", err)
+	}
 	data, err := json.Marshal(cred)
 	if err != nil {
 		return fmt.Errorf("marshaling credentials: %w", err)
@@ -62,22 +180,40 @@ func saveCredentials(token string, secret string) error {
 	return nil
 }
 
+func removeCredentials() error {
+	if err := os.Remove(credentialsFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing credentials file: %w", err)
+	}
+	return nil
+}
+
 func readCredentials() (string, string, error) {
-	f, err := os.Open(".credentials")
+	cred, err := loadCredentials()
+	if err != nil {
+		return "", "", err
+	}
+	return cred.Token, cred.Secret, nil
+}
+
+func loadCredentials() (credentials, error) {
+	f, err := os.Open(credentialsFile)
 	if err != nil {
-		return "", "", fmt.Errorf("opening credentials file: %w", err)
+		return credentials{}, fmt.Errorf("opening credentials file: %w", err)
 	}
 	//goland:noinspection GoUnhandledErrorResult
 	defer f.Close()
 	data, err := io.ReadAll(f)
 	if err != nil {
-		return "", "", fmt.Errorf("reading credentials file: %w", err)
+		return credentials{}, fmt.Errorf("reading credentials file: %w", err)
 	}
 	var cred credentials
 	if err := json.Unmarshal(data, &cred); err != nil {
-		return "", "", fmt.Errorf("unmarshaling credentials: %w", err)
+		return credentials{}, fmt.Errorf("unmarshaling credentials: %w", err)
 	}
-	return cred.Token, cred.Secret, nil
+	if cred.Version != credentialsVersion {
+		return credentials{}, errors.New("credentials are outdated, please login again")
+	}
+	return cred, nil
 }
 
 type typePassword struct {
@@ -2,14 +2,30 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
+
+	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
 )
 
 // Del client command for removing item.
 func (c *Command) Del(ctx context.Context, name string) error {
-	token, _, err := readCredentials()
-	err = c.client.Delete(ctx, token, name)
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	err = c.client.Delete(ctx, token, secret, name)
 	if err != nil {
+		if errors.Is(err, services.ErrOfflineQueued) {
+			fmt.Printf("Item %s deleted locally: %s\n", name, err.Error())
+			return nil
+		}
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Delete item error: %s\n", s.Message())
+			return nil
+		}
 		return err
 	}
 	fmt.Printf("Item %s sucessfully deleted\n", name)
@@ -1,12 +1,198 @@
 package command
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
 )
 
 // Edit client command for updating item.
 func (c *Command) Edit(ctx context.Context, name string) error {
-	fmt.Println("Command not implemented yet")
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	item, err := c.client.Get(ctx, token, secret, name)
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Get item error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+
+	fmt.Printf("Editing item \"%s\" of type \"%s\" (blank value keeps current):\n", item.Name, item.Type)
+
+	in := bufio.NewReader(os.Stdin)
+	switch item.Type {
+	case "password":
+		var pt typePassword
+		if err := json.Unmarshal(item.Data, &pt); err != nil {
+			return err
+		}
+		if pt.Login, err = readValue(in, "Login", pt.Login); err != nil {
+			return err
+		}
+		if pt.Password, err = readSecretValue("Password", pt.Password); err != nil {
+			return err
+		}
+		item.Data, err = json.Marshal(&pt)
+		if err != nil {
+			return err
+		}
+	case "text":
+		fmt.Printf("Current text:\n%s\n", string(item.Data))
+		fmt.Println("Enter new text for item (blank line keeps current): ")
+		var text string
+		for {
+			line, err := in.ReadString('\n')
+			line = strings.Trim(line, "\n")
+			if len(line) == 0 {
+				break
+			}
+			text = text + line + "\n"
+			if err != nil {
+				break
+			}
+		}
+		if len(text) > 0 {
+			item.Data = []byte(strings.Trim(text, "\n"))
+		}
+	case "card":
+		var ct typeCard
+		if err := json.Unmarshal(item.Data, &ct); err != nil {
+			return err
+		}
+		if ct.Number, err = readValue(in, "Number", ct.Number); err != nil {
+			return err
+		}
+		if ct.Holder, err = readValue(in, "Holder", ct.Holder); err != nil {
+			return err
+		}
+		if ct.ExpiryMonth, err = readValue(in, "Expiry Month", ct.ExpiryMonth); err != nil {
+			return err
+		}
+		if ct.ExpiryYear, err = readValue(in, "Expiry Year", ct.ExpiryYear); err != nil {
+			return err
+		}
+		if ct.Cvv, err = readSecretValue("CVV", ct.Cvv); err != nil {
+			return err
+		}
+		item.Data, err = json.Marshal(&ct)
+		if err != nil {
+			return err
+		}
+	case "binary":
+		filePath, err := readValue(in, "File path", "")
+		if err != nil {
+			return err
+		}
+		if len(filePath) > 0 {
+			f, err := os.Open(filePath)
+			if err != nil {
+				fmt.Printf("File open error: %s\n", err.Error())
+				return err
+			}
+			//goland:noinspection GoUnhandledErrorResult
+			defer f.Close()
+			item.Data, err = io.ReadAll(f)
+			if err != nil {
+				fmt.Printf("File read error: %s\n", err.Error())
+				return err
+			}
+		}
+	}
+
+	fmt.Println("Editing metadata (\"-\" value removes key):")
+	metadata := make([]services.Metadata, 0, len(item.Metadata))
+	for _, m := range item.Metadata {
+		value, err := readValue(in, m.Key, m.Value)
+		if err != nil {
+			return err
+		}
+		if value == "-" {
+			continue
+		}
+		m.Value = value
+		metadata = append(metadata, m)
+	}
+	fmt.Println("Adding metadata (key: value pairs) for item (blank key for end):")
+	for {
+		key, err := readValue(in, "Key", "")
+		if err != nil || len(key) == 0 {
+			break
+		}
+		value, err := readValue(in, "Value", "")
+		if err != nil {
+			return err
+		}
+		m := services.Metadata{
+			Key:   key,
+			Value: value,
+		}
+		metadata = append(metadata, m)
+	}
+	item.Metadata = metadata
+
+	err = c.client.Update(ctx, token, secret, item)
+	if err != nil {
+		if errors.Is(err, services.ErrOfflineQueued) {
+			fmt.Printf("Item %s updated locally: %s\n", item.Name, err.Error())
+			return nil
+		}
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Update item error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	fmt.Printf("Item %s sucessfully updated\n", item.Name)
+
 	return nil
 }
+
+// readValue print prompt with current value and read new one, blank input keeps current value.
+func readValue(in *bufio.Reader, prompt string, current string) (string, error) {
+	if len(current) > 0 {
+		fmt.Printf("%s [%s]: ", prompt, current)
+	} else {
+		fmt.Printf("%s: ", prompt)
+	}
+	value, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	value = strings.Trim(value, "\n")
+	if len(value) == 0 {
+		return current, nil
+	}
+	return value, nil
+}
+
+// readSecretValue read new secret value without echo, current value is never shown, blank input keeps it.
+func readSecretValue(prompt string, current string) (string, error) {
+	if len(current) > 0 {
+		fmt.Printf("%s [unchanged]: ", prompt)
+	} else {
+		fmt.Printf("%s: ", prompt)
+	}
+	b, err := terminal.ReadPassword(0)
+	if err != nil {
+		return "", err
+	}
+	fmt.Println()
+	if len(b) == 0 {
+		return current, nil
+	}
+	return string(b), nil
+}
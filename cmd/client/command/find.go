@@ -0,0 +1,76 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
+)
+
+// metadataFlag is repeatable "key=value" command flag, value part is optional.
+type metadataFlag []services.Metadata
+
+// String implement flag.Value interface.
+func (f *metadataFlag) String() string {
+	pairs := make([]string, 0, len(*f))
+	for _, m := range *f {
+		pairs = append(pairs, m.Key+"="+m.Value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set implement flag.Value interface.
+func (f *metadataFlag) Set(value string) error {
+	key, val, _ := strings.Cut(value, "=")
+	if key == "" {
+		return errors.New("metadata key should not be empty")
+	}
+	*f = append(*f, services.Metadata{Key: key, Value: val})
+	return nil
+}
+
+// Find client command for searching items by name substring or glob pattern, type and metadata.
+func (c *Command) Find(ctx context.Context, args []string) error {
+	var filter services.ItemFilter
+	var metadata metadataFlag
+	fs := flag.NewFlagSet("find", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&filter.Name, "name", "", "")
+	fs.StringVar(&filter.Type, "type", "", "")
+	fs.Var(&metadata, "meta", "")
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Find arguments error: %s\n", err.Error())
+		return nil
+	}
+	if fs.NArg() > 0 && filter.Name == "" {
+		filter.Name = fs.Arg(0)
+	}
+	filter.Metadata = metadata
+
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	items, err := c.client.Search(ctx, token, secret, filter)
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Find items error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+
+	if len(items) == 0 {
+		fmt.Println("No items found")
+		return nil
+	}
+
+	fmt.Printf("Found items [%d]:\n", len(items))
+	return printItems(items)
+}
@@ -0,0 +1,36 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// History client command for receiving item previous versions list.
+func (c *Command) History(ctx context.Context, name string) error {
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	versions, err := c.client.History(ctx, token, secret, name)
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Get item history error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+
+	if len(versions) == 0 {
+		fmt.Printf("Item %s has no previous versions\n", name)
+		return nil
+	}
+
+	fmt.Printf("Item %s versions [%d]:\n", name, len(versions))
+	for _, v := range versions {
+		fmt.Printf("\t%d\t%s\n", v.Version, v.UpdatedAt.Local().Format(time.RFC3339))
+	}
+	return nil
+}
@@ -3,23 +3,74 @@ package command
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
+)
+
+const (
+	listPageSize = 500
+)
+
+var (
+	listSortFields = map[string]func(a, b services.ItemSummary) bool{
+		"name": func(a, b services.ItemSummary) bool {
+			return a.Name < b.Name
+		},
+		"type": func(a, b services.ItemSummary) bool {
+			return a.Type < b.Type
+		},
+		"created": func(a, b services.ItemSummary) bool {
+			return a.CreatedAt.Before(b.CreatedAt)
+		},
+		"updated": func(a, b services.ItemSummary) bool {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		},
+	}
 )
 
-// List client command for receiving items list.
-func (c *Command) List(ctx context.Context) error {
-	token, _, err := readCredentials()
+// List client command for receiving items list, list is received page by page
+// and printed as table sorted by field ("name", "type", "created" or "updated") in order ("asc" or "desc").
+func (c *Command) List(ctx context.Context, sortField string, sortOrder string) error {
+	if sortField == "" {
+		sortField = "name"
+	}
+	less, ok := listSortFields[sortField]
+	if !ok {
+		fmt.Printf("Unknown sort field: %s\n", sortField)
+		return nil
+	}
+	if sortOrder != "" && sortOrder != "asc" && sortOrder != "desc" {
+		fmt.Printf("Unknown sort order: %s\n", sortOrder)
+		return nil
+	}
+
+	token, secret, err := readCredentials()
 	if err != nil {
 		return err
 	}
-	items, err := c.client.List(ctx, token)
-	if err != nil {
-		if s, ok := status.FromError(err); ok {
-			fmt.Printf("Get items list error: %s", s.Message())
-			return nil
+	var items []services.ItemSummary
+	var pageToken string
+	for {
+		page, nextPageToken, err := c.client.List(ctx, token, secret, listPageSize, pageToken)
+		if err != nil {
+			if s, ok := status.FromError(err); ok {
+				fmt.Printf("Get items list error: %s", s.Message())
+				return nil
+			}
+			return err
 		}
-		return err
+		items = append(items, page...)
+		if nextPageToken == "" {
+			break
+		}
+		pageToken = nextPageToken
 	}
 
 	if len(items) == 0 {
@@ -27,9 +78,37 @@ func (c *Command) List(ctx context.Context) error {
 		return nil
 	}
 
+	sort.SliceStable(items, func(i, j int) bool {
+		if sortOrder == "desc" {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+
 	fmt.Printf("Your items [%d]:\n", len(items))
+	return printItems(items)
+}
+
+// printItems print items summaries as table.
+func printItems(items []services.ItemSummary) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tCREATED\tUPDATED\tMETADATA")
 	for _, item := range items {
-		fmt.Printf("\t%s\n", item)
+		metadata := make([]string, 0, len(item.Metadata))
+		for _, m := range item.Metadata {
+			metadata = append(metadata, fmt.Sprintf("%s=%s", m.Key, m.Value))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			item.Name, item.Type, formatTime(item.CreatedAt), formatTime(item.UpdatedAt), strings.Join(metadata, ", "),
+		)
+	}
+	return w.Flush()
+}
+
+// formatTime format time in local zone, zero time is unknown and shown as "-".
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
 	}
-	return nil
+	return t.Local().Format(time.RFC3339)
 }
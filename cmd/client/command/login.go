@@ -2,16 +2,32 @@ package command
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 
 	"golang.org/x/crypto/ssh/terminal"
 	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
 )
 
 // Login client command for user login.
-func (c *Command) Login(ctx context.Context) error {
+// With "--srp" flag login is done by SRP-6a exchange, so password is not sent to server.
+// With "--zero-knowledge" flag login fails unless vault is zero-knowledge one, so new device can't be tricked
+// into storing plain item names.
+func (c *Command) Login(ctx context.Context, args []string) error {
+	var useSRP, zeroKnowledge bool
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&useSRP, "srp", false, "")
+	fs.BoolVar(&zeroKnowledge, "zero-knowledge", false, "")
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Login arguments error: %s\n", err.Error())
+		return nil
+	}
+
 	var login, password string
 	fmt.Print("Login: ")
 	if _, err := fmt.Scanln(&login); err != nil {
@@ -30,19 +46,42 @@ func (c *Command) Login(ctx context.Context) error {
 		return err
 	}
 	fmt.Println()
-	hash := md5.Sum(b)
-	secret := hex.EncodeToString(hash[:])
+	secret := string(b)
 
-	token, err := c.client.Login(ctx, login, password)
+	var result services.LoginResult
+	if useSRP {
+		result, err = c.client.LoginSRP(ctx, login, password, secret, readOTPCode)
+	} else {
+		result, err = c.client.Login(ctx, login, password, secret, readOTPCode)
+	}
 	if err != nil {
+		if errors.Is(err, services.ErrSRPServerProof) {
+			fmt.Printf("Login error: %s\n", err.Error())
+			return nil
+		}
+		if errors.Is(err, services.ErrIndexKeyMissing) {
+			fmt.Printf("Login error: %s\n", err.Error())
+			return nil
+		}
+		if errors.Is(err, services.ErrSecretMismatch) {
+			fmt.Println("Login error: secret is wrong or its rotation was interrupted, finish it with rotate-secret")
+			return nil
+		}
 		if s, ok := status.FromError(err); ok {
 			fmt.Printf("Login error: %s\n", s.Message())
 			return nil
 		}
 		return err
 	}
+	if zeroKnowledge && !result.ZeroKnowledge {
+		fmt.Printf("Login error: %s\n", services.ErrIndexKeyMissing.Error())
+		return nil
+	}
+	if result.PreviousKey != "" {
+		fmt.Println("Vault encryption key was upgraded")
+	}
 
-	if err := saveCredentials(token, secret); err != nil {
+	if err := saveCredentials(result); err != nil {
 		return fmt.Errorf("saving credentials: %w", err)
 	}
 
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/status"
+)
+
+// Logout client command for revoking token and removing saved credentials.
+// With "--all" flag all user sessions are revoked, e.g. when other device is lost.
+// Credentials are removed even when token revoking failed.
+func (c *Command) Logout(ctx context.Context, args []string) error {
+	var all bool
+	fs := flag.NewFlagSet("logout", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&all, "all", false, "")
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Logout arguments error: %s\n", err.Error())
+		return nil
+	}
+
+	token, _, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	if all {
+		err = c.client.RevokeAllSessions(ctx, token)
+	} else {
+		err = c.client.Logout(ctx, token)
+	}
+	if err := removeCredentials(); err != nil {
+		return err
+	}
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Logout error: %s, local credentials are removed\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	if all {
+		fmt.Println("All sessions are revoked")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"google.golang.org/grpc/status"
+)
+
+// EnableOTP client command for enabling two-factor authentication with authenticator application,
+// current password is required.
+func (c *Command) EnableOTP(ctx context.Context) error {
+	cred, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	token := cred.Token
+	login, password, err := readCurrentPassword(cred)
+	if err != nil {
+		return err
+	}
+	var uri string
+	if cred.SRP {
+		uri, err = c.client.StartOTPEnrollmentSRP(ctx, token, login, password)
+	} else {
+		uri, err = c.client.StartOTPEnrollment(ctx, token, password)
+	}
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Enable two-factor authentication error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+
+	fmt.Println("Add account to authenticator application by URI:")
+	fmt.Printf("\t%s\n", uri)
+	if u, err := url.Parse(uri); err == nil {
+		fmt.Printf("or enter secret manually: %s\n", u.Query().Get("secret"))
+	}
+	code, err := readOTPCode()
+	if err != nil {
+		return err
+	}
+	recoveryCodes, err := c.client.ConfirmOTPEnrollment(ctx, token, code)
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Enable two-factor authentication error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+
+	fmt.Println("Two-factor authentication is enabled")
+	fmt.Println("Recovery codes, each of them can be used once instead of one-time password:")
+	for _, code := range recoveryCodes {
+		fmt.Printf("\t%s\n", code)
+	}
+	fmt.Println("Keep them in safe place, they are not shown again")
+	return nil
+}
+
+// DisableOTP client command for disabling two-factor authentication, current password and one-time password
+// are required.
+func (c *Command) DisableOTP(ctx context.Context) error {
+	cred, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	login, password, err := readCurrentPassword(cred)
+	if err != nil {
+		return err
+	}
+	code, err := readOTPCode()
+	if err != nil {
+		return err
+	}
+	if cred.SRP {
+		err = c.client.DisableOTPSRP(ctx, cred.Token, login, password, code)
+	} else {
+		err = c.client.DisableOTP(ctx, cred.Token, password, code)
+	}
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Disable two-factor authentication error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	fmt.Println("Two-factor authentication is disabled")
+	return nil
+}
+
+// readOTPCode prompt one-time password or recovery code.
+func readOTPCode() (string, error) {
+	var code string
+	fmt.Print("One-time password or recovery code: ")
+	if _, err := fmt.Scanln(&code); err != nil {
+		return "", err
+	}
+	return code, nil
+}
@@ -2,14 +2,30 @@ package command
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 
 	"golang.org/x/crypto/ssh/terminal"
 	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
 )
 
 // Register client command for user registration.
-func (c *Command) Register(ctx context.Context) error {
+// With "--zero-knowledge" flag item names, types and metadata are hidden from server.
+// With "--srp" flag server stores only password verifier and login is done by SRP-6a exchange.
+func (c *Command) Register(ctx context.Context, args []string) error {
+	var zeroKnowledge, useSRP bool
+	fs := flag.NewFlagSet("register", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&zeroKnowledge, "zero-knowledge", false, "")
+	fs.BoolVar(&useSRP, "srp", false, "")
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Register arguments error: %s\n", err.Error())
+		return nil
+	}
+
 	var login, password string
 	fmt.Print("Login: ")
 	if _, err := fmt.Scanln(&login); err != nil {
@@ -31,9 +47,15 @@ func (c *Command) Register(ctx context.Context) error {
 	secret := string(b)
 	if len(secret) < 4 {
 		fmt.Println("Registration error: secret: length should be greater or equal 4")
+		return nil
 	}
 
-	token, err := c.client.Register(ctx, login, password)
+	var result services.LoginResult
+	if useSRP {
+		result, err = c.client.RegisterSRP(ctx, login, password, secret)
+	} else {
+		result, err = c.client.Register(ctx, login, password, secret)
+	}
 	if err != nil {
 		if s, ok := status.FromError(err); ok {
 			fmt.Printf("Registration error: %s\n", s.Message())
@@ -42,9 +64,18 @@ func (c *Command) Register(ctx context.Context) error {
 		return err
 	}
 
-	if err := saveCredentials(token, secret); err != nil {
+	if err := saveCredentials(result); err != nil {
 		return fmt.Errorf("saving credentials: %w", err)
 	}
+	if zeroKnowledge {
+		if err := c.client.EnableZeroKnowledge(ctx, result.Token, result.Key); err != nil {
+			if s, ok := status.FromError(err); ok {
+				fmt.Printf("Enabling zero-knowledge mode error: %s\n", s.Message())
+				return nil
+			}
+			return err
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,39 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
+)
+
+// Restore client command for restoring item previous version.
+func (c *Command) Restore(ctx context.Context, name string, version string) error {
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		fmt.Println("Version should be a number from item history")
+		return nil
+	}
+	err = c.client.Restore(ctx, token, secret, name, v)
+	if err != nil {
+		if errors.Is(err, services.ErrVersionSecretMismatch) {
+			fmt.Printf("Restore item error: %s\n", err.Error())
+			return nil
+		}
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Restore item error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	fmt.Printf("Item %s sucessfully restored to version %d\n", name, v)
+	return nil
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
+)
+
+// RotateSecret client command for changing secret for encryption and re-encrypting all items.
+func (c *Command) RotateSecret(ctx context.Context) error {
+	token, _, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	fmt.Print("Current secret for encryption: ")
+	b, err := terminal.ReadPassword(0)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	secret := string(b)
+	fmt.Print("New secret for encryption: ")
+	b, err = terminal.ReadPassword(0)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	newSecret := string(b)
+	fmt.Print("Repeat new secret for encryption: ")
+	b, err = terminal.ReadPassword(0)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	if string(b) != newSecret {
+		fmt.Println("Rotate secret error: new secrets do not match")
+		return nil
+	}
+	if len(newSecret) < 4 {
+		fmt.Println("Rotate secret error: secret: length should be greater or equal 4")
+		return nil
+	}
+
+	result, err := c.client.RotateSecret(ctx, token, secret, newSecret)
+	if err != nil {
+		if errors.Is(err, services.ErrSecretMismatch) {
+			fmt.Println("Rotate secret error: current or new secret is wrong, items were not changed")
+			return nil
+		}
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Rotate secret error: %s\n", s.Message())
+			fmt.Println("Run rotate-secret with the same secrets again to finish rotation")
+			return nil
+		}
+		return err
+	}
+
+	if err := updateSecret(result.Key); err != nil {
+		return fmt.Errorf("saving credentials: %w", err)
+	}
+	fmt.Println("Secret for encryption sucessfully changed")
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// Sessions client command for listing account sessions with devices they were created on.
+func (c *Command) Sessions(ctx context.Context) error {
+	token, _, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	sessions, err := c.client.ListSessions(ctx, token)
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("List sessions error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+
+	fmt.Printf("Sessions [%d]:\n", len(sessions))
+	for _, s := range sessions {
+		current := ""
+		if s.Current {
+			current = " (current)"
+		}
+		fmt.Printf("\t%s%s\n", s.ID, current)
+		fmt.Printf("\t\tDevice: %s, version %s\n", s.Device.Name, s.Device.ClientVersion)
+		fmt.Printf("\t\tIP: %s\n", s.Device.IP)
+		fmt.Printf("\t\tCreated: %s\n", s.CreatedAt.Local().Format(time.RFC3339))
+		fmt.Printf("\t\tLast used: %s\n", s.LastUsedAt.Local().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// RevokeSession client command for revoking account session by ID, e.g. session of lost device.
+func (c *Command) RevokeSession(ctx context.Context, id string) error {
+	token, _, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	if err := c.client.RevokeSession(ctx, token, id); err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Revoke session error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	fmt.Printf("Session %s is revoked\n", id)
+	return nil
+}
@@ -0,0 +1,31 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/status"
+)
+
+// Sync client command for syncing local vault with server.
+func (c *Command) Sync(ctx context.Context) error {
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	result, err := c.client.Sync(ctx, token, secret)
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Sync error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+
+	fmt.Printf("Local changes sent: %d\n", result.Pushed)
+	for _, name := range result.Conflicts {
+		fmt.Printf("\tConflict for item %s, server version kept\n", name)
+	}
+	fmt.Printf("Server changes received: %d updated, %d deleted\n", result.Pulled, result.Deleted)
+	return nil
+}
@@ -0,0 +1,32 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	"keeper/internal/services"
+)
+
+// Watch client command for printing items changes made on any device.
+func (c *Command) Watch(ctx context.Context) error {
+	token, secret, err := readCredentials()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Watching items changes (Ctrl+C for exit):")
+	err = c.client.Watch(ctx, token, secret, func(event services.ItemEvent) error {
+		fmt.Printf("\t%s\t%s\t%s\trevision %d\n", event.UpdatedAt.Local().Format(time.RFC3339), event.Type, event.Name, event.Revision)
+		return nil
+	})
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Printf("Watch items error: %s\n", s.Message())
+			return nil
+		}
+		return err
+	}
+	return nil
+}
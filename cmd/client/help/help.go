@@ -17,27 +17,81 @@ func Usage() {
 	fmt.Println(Yellow + "Commands:" + Reset)
 	fmt.Println("\t" + Green + "register" + Reset + " - register new account by login and password")
 	fmt.Println("\t" + Green + "login" + Reset + "    - login via existed login and password")
+	fmt.Println("\t" + Green + "logout" + Reset + "   - revoke session and remove saved credentials")
+	fmt.Println("\t" + Green + "sessions" + Reset + " - list and revoke sessions of the account")
+	fmt.Println("\t" + Green + "2fa" + Reset + "      - enable or disable two-factor authentication")
+	fmt.Println("\t" + Green + "passwd" + Reset + "   - change account password")
+	fmt.Println("\t" + Green + "account" + Reset + "  - delete account with all items or hide items from server")
 	fmt.Println("\t" + Green + "ls" + Reset + "       - list items")
+	fmt.Println("\t" + Green + "find" + Reset + "     - search items by name, type and metadata")
 	fmt.Println("\t" + Green + "get" + Reset + "      - get item details")
 	fmt.Println("\t" + Green + "add" + Reset + "      - create item")
 	fmt.Println("\t" + Green + "edit" + Reset + "     - edit item")
 	fmt.Println("\t" + Green + "del" + Reset + "      - remove item")
+	fmt.Println("\t" + Green + "history" + Reset + "  - list item previous versions")
+	fmt.Println("\t" + Green + "restore" + Reset + "  - restore item previous version")
+	fmt.Println("\t" + Green + "sync" + Reset + "     - sync local vault with server")
+	fmt.Println("\t" + Green + "rotate-secret" + Reset + " - change secret for encryption")
+	fmt.Println("\t" + Green + "watch" + Reset + "    - watch items changes")
 	fmt.Println("\tGet command help: " + Cyan + "keeper <command> help" + Reset)
 }
 
 // RegisterUsage show "register" command usage help text.
 func RegisterUsage() {
-	fmt.Println(Yellow + "Register account: " + Cyan + "keeper [options] register" + Reset)
+	fmt.Println(Yellow + "Register account: " + Cyan + "keeper [options] register [--zero-knowledge] [--srp]" + Reset)
+	fmt.Println("\t" + Green + "--zero-knowledge" + Reset + " - hide item names, types and metadata from server")
+	fmt.Println("\t" + Green + "--srp" + Reset + "            - never send password to server, login with --srp flag then")
 }
 
 // LoginUsage show "login" command usage help text.
 func LoginUsage() {
-	fmt.Println(Yellow + "Login: " + Cyan + "keeper [options] login" + Reset)
+	fmt.Println(Yellow + "Login: " + Cyan + "keeper [options] login [--srp] [--zero-knowledge]" + Reset)
+	fmt.Println("\t" + Green + "--srp" + Reset + "            - login by SRP-6a exchange without sending password to server")
+	fmt.Println("\t" + Green + "--zero-knowledge" + Reset + " - fail unless vault hides item names from server")
+}
+
+// LogoutUsage show "logout" command usage help text.
+func LogoutUsage() {
+	fmt.Println(Yellow + "Logout: " + Cyan + "keeper [options] logout [--all]" + Reset)
+	fmt.Println("\t" + Green + "--all" + Reset + " - revoke all sessions of the account")
+}
+
+// SessionsUsage show "sessions" command usage help text.
+func SessionsUsage() {
+	fmt.Println(Yellow + "List sessions: " + Cyan + "keeper [options] sessions" + Reset)
+	fmt.Println(Yellow + "Revoke session: " + Cyan + "keeper [options] sessions revoke <session id>" + Reset)
+}
+
+// TwoFactorUsage show "2fa" command usage help text.
+func TwoFactorUsage() {
+	fmt.Println(Yellow + "Enable two-factor authentication: " + Cyan + "keeper [options] 2fa enable" + Reset)
+	fmt.Println(Yellow + "Disable two-factor authentication: " + Cyan + "keeper [options] 2fa disable" + Reset)
+	fmt.Println("\tCurrent password is required for both")
+}
+
+// PasswdUsage show "passwd" command usage help text.
+func PasswdUsage() {
+	fmt.Println(Yellow + "Change password: " + Cyan + "keeper [options] passwd [--srp]" + Reset)
+	fmt.Println("\t" + Green + "--srp" + Reset + " - switch to login without sending password to server")
+	fmt.Println("\tPassword of account logged in with --srp is never sent to server")
+}
+
+// AccountUsage show "account" command usage help text.
+func AccountUsage() {
+	fmt.Println(Yellow + "Delete account: " + Cyan + "keeper [options] account delete" + Reset)
+	fmt.Println("\tPassword of account logged in with --srp is never sent to server")
+	fmt.Println(Yellow + "Enable zero-knowledge mode: " + Cyan + "keeper [options] account zero-knowledge" + Reset)
+	fmt.Println("\tItem names, types and metadata are hidden from server, previous versions of items are removed")
 }
 
 // LsUsage show "ls" (list) command usage help text.
 func LsUsage() {
-	fmt.Println(Yellow + "List items: " + Cyan + "keeper [options] ls" + Reset)
+	fmt.Println(Yellow + "List items: " + Cyan + "keeper [options] ls [sort field: name, type, created, updated] [sort order: asc, desc]" + Reset)
+}
+
+// FindUsage show "find" command usage help text.
+func FindUsage() {
+	fmt.Println(Yellow + "Find items: " + Cyan + "keeper [options] find [--type <item type>] [--meta <key>[=<value>]]... [<name substring or glob>]" + Reset)
 }
 
 // GetUsage show "get" command usage help text.
@@ -59,3 +113,28 @@ func EditUsage() {
 func DelUsage() {
 	fmt.Println(Yellow + "Remote item: " + Cyan + "keeper [options] del <item name>" + Reset)
 }
+
+// HistoryUsage show "history" command usage help text.
+func HistoryUsage() {
+	fmt.Println(Yellow + "List item versions: " + Cyan + "keeper [options] history <item name>" + Reset)
+}
+
+// RestoreUsage show "restore" command usage help text.
+func RestoreUsage() {
+	fmt.Println(Yellow + "Restore item version: " + Cyan + "keeper [options] restore <item name> <version>" + Reset)
+}
+
+// SyncUsage show "sync" command usage help text.
+func SyncUsage() {
+	fmt.Println(Yellow + "Sync local vault: " + Cyan + "keeper [options] sync" + Reset)
+}
+
+// RotateSecretUsage show "rotate-secret" command usage help text.
+func RotateSecretUsage() {
+	fmt.Println(Yellow + "Change secret for encryption: " + Cyan + "keeper [options] rotate-secret" + Reset)
+}
+
+// WatchUsage show "watch" command usage help text.
+func WatchUsage() {
+	fmt.Println(Yellow + "Watch items changes: " + Cyan + "keeper [options] watch" + Reset)
+}
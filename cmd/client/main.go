@@ -5,16 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
 
 	"github.com/ardanlabs/conf/v3"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"keeper/cmd/client/command"
 	"keeper/cmd/client/help"
 	pb "keeper/gen/service"
 	"keeper/internal/services"
+	"keeper/pkg/certs"
 	"keeper/pkg/logger"
 )
 
@@ -23,7 +26,15 @@ var build = "develop"
 type config struct {
 	conf.Version
 	Address string `conf:"default:localhost:3200,help:Server address"`
-	Args    conf.Args
+	Vault   string `conf:"default:.vault,help:Local vault replica path"`
+	TLS     struct {
+		Enabled        bool   `conf:"help:Use TLS with system CA bundle when CA file is not set"`
+		CAFile         string `conf:"help:CA bundle path for server certificate verification"`
+		ClientCertFile string `conf:"help:Client certificate path for servers with mutual TLS"`
+		ClientKeyFile  string `conf:"help:Client certificate private key path"`
+		ServerName     string `conf:"help:Server name for certificate verification instead of host from address"`
+	}
+	Args conf.Args
 }
 
 func main() {
@@ -73,8 +84,20 @@ func run(log *zap.SugaredLogger) error {
 			help.RegisterUsage()
 		case "login":
 			help.LoginUsage()
+		case "logout":
+			help.LogoutUsage()
+		case "sessions":
+			help.SessionsUsage()
+		case "2fa":
+			help.TwoFactorUsage()
+		case "passwd":
+			help.PasswdUsage()
+		case "account":
+			help.AccountUsage()
 		case "ls":
 			help.LsUsage()
+		case "find":
+			help.FindUsage()
 		case "get":
 			help.GetUsage()
 		case "add":
@@ -83,31 +106,94 @@ func run(log *zap.SugaredLogger) error {
 			help.EditUsage()
 		case "del":
 			help.DelUsage()
+		case "history":
+			help.HistoryUsage()
+		case "restore":
+			help.RestoreUsage()
+		case "sync":
+			help.SyncUsage()
+		case "rotate-secret":
+			help.RotateSecretUsage()
+		case "watch":
+			help.WatchUsage()
 		default:
 			help.Usage()
 		}
 		return nil
 	}
 
-	conn, err := grpc.Dial(cfg.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	transportCredentials := insecure.NewCredentials()
+	if cfg.TLS.Enabled || cfg.TLS.CAFile != "" || cfg.TLS.ClientCertFile != "" {
+		tlsConfig, err := certs.ClientConfig(cfg.TLS.CAFile, cfg.TLS.ClientCertFile, cfg.TLS.ClientKeyFile, cfg.TLS.ServerName)
+		if err != nil {
+			return fmt.Errorf("tls config: %w", err)
+		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+	refresher := services.NewTokenRefresher(command.CredentialsTokenStore{})
+	conn, err := grpc.Dial(
+		cfg.Address,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithUnaryInterceptor(refresher.UnaryInterceptor()),
+		grpc.WithStreamInterceptor(refresher.StreamInterceptor()),
+	)
 	if err != nil {
 		return err
 	}
 	//goland:noinspection GoUnhandledErrorResult
 	defer conn.Close()
+	refresher.SetConn(conn)
 	grpcClient := pb.NewKeeperServiceClient(conn)
-	client := services.NewClientService(grpcClient)
+	clientService := services.NewClientService(grpcClient, clientDevice())
+	clientService.SetVaultStore(command.CredentialsVaultStore{})
+	client := services.NewOfflineClientService(clientService, cfg.Vault)
 	cmd := command.NewCommand(log, client)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	switch cfg.Args.Num(0) {
 	case "register":
-		return cmd.Register(ctx)
+		return cmd.Register(ctx, cfg.Args[1:])
 	case "login":
-		return cmd.Login(ctx)
+		return cmd.Login(ctx, cfg.Args[1:])
+	case "logout":
+		return cmd.Logout(ctx, cfg.Args[1:])
+	case "sessions":
+		switch {
+		case len(cfg.Args) == 1:
+			return cmd.Sessions(ctx)
+		case len(cfg.Args) == 3 && cfg.Args.Num(1) == "revoke":
+			return cmd.RevokeSession(ctx, cfg.Args.Num(2))
+		default:
+			help.SessionsUsage()
+			return nil
+		}
+	case "2fa":
+		switch cfg.Args.Num(1) {
+		case "enable":
+			return cmd.EnableOTP(ctx)
+		case "disable":
+			return cmd.DisableOTP(ctx)
+		default:
+			help.TwoFactorUsage()
+			return nil
+		}
+	case "passwd":
+		return cmd.ChangePassword(ctx, cfg.Args[1:])
+	case "account":
+		switch cfg.Args.Num(1) {
+		case "delete":
+			return cmd.DeleteAccount(ctx, cfg.Args[2:])
+		case "zero-knowledge":
+			return cmd.EnableZeroKnowledge(ctx)
+		default:
+			help.AccountUsage()
+			return nil
+		}
 	case "ls":
-		return cmd.List(ctx)
+		return cmd.List(ctx, cfg.Args.Num(1), cfg.Args.Num(2))
+	case "find":
+		return cmd.Find(ctx, cfg.Args[1:])
 	case "get":
 		if len(cfg.Args) < 2 {
 			help.GetUsage()
@@ -132,8 +218,38 @@ func run(log *zap.SugaredLogger) error {
 			return nil
 		}
 		return cmd.Del(ctx, cfg.Args.Num(1))
+	case "history":
+		if len(cfg.Args) < 2 {
+			help.HistoryUsage()
+			return nil
+		}
+		return cmd.History(ctx, cfg.Args.Num(1))
+	case "restore":
+		if len(cfg.Args) < 3 {
+			help.RestoreUsage()
+			return nil
+		}
+		return cmd.Restore(ctx, cfg.Args.Num(1), cfg.Args.Num(2))
+	case "sync":
+		return cmd.Sync(ctx)
+	case "rotate-secret":
+		return cmd.RotateSecret(ctx)
+	case "watch":
+		return cmd.Watch(ctx)
 	default:
 		help.Usage()
 		return nil
 	}
 }
+
+// clientDevice return device info shown in user sessions list.
+func clientDevice() services.Device {
+	name, err := os.Hostname()
+	if err != nil {
+		name = "unknown"
+	}
+	return services.Device{
+		Name:          fmt.Sprintf("%s (%s/%s)", name, runtime.GOOS, runtime.GOARCH),
+		ClientVersion: build,
+	}
+}
@@ -24,70 +24,273 @@ var File_keeper_proto protoreflect.FileDescriptor
 var file_keeper_proto_rawDesc = []byte{
 	0x0a, 0x0c, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06,
 	0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x1a, 0x0a, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x1a, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0xb8,
-	0x03, 0x0a, 0x0d, 0x4b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x74, 0x6f, 0x1a, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x9d,
+	0x11, 0x0a, 0x0d, 0x4b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
 	0x12, 0x30, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x12, 0x2e, 0x61, 0x75, 0x74, 0x68,
 	0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e,
 	0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
 	0x73, 0x65, 0x12, 0x33, 0x0a, 0x08, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x12, 0x12,
 	0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
 	0x73, 0x74, 0x1a, 0x13, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x17, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x43, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
-	0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x17, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x18, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65,
-	0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x07, 0x47, 0x65, 0x74,
-	0x49, 0x74, 0x65, 0x6d, 0x12, 0x14, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49,
-	0x74, 0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x69, 0x74, 0x65,
-	0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12,
-	0x17, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x74, 0x65,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x0b, 0x52, 0x65, 0x67, 0x69, 0x73,
+	0x74, 0x65, 0x72, 0x53, 0x72, 0x70, 0x12, 0x18, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x53, 0x72, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x13, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x53, 0x74, 0x61, 0x72, 0x74, 0x53, 0x72,
+	0x70, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x1a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x53, 0x74,
+	0x61, 0x72, 0x74, 0x53, 0x72, 0x70, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x53,
+	0x72, 0x70, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x42, 0x0a, 0x0e, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x53, 0x72, 0x70, 0x4c, 0x6f, 0x67, 0x69,
+	0x6e, 0x12, 0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x53,
+	0x72, 0x70, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13,
+	0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x4f, 0x74, 0x70,
+	0x12, 0x16, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x4f, 0x74,
+	0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e,
+	0x4c, 0x6f, 0x67, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a,
+	0x06, 0x47, 0x65, 0x74, 0x4b, 0x64, 0x66, 0x12, 0x13, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x47,
+	0x65, 0x74, 0x4b, 0x64, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x2e, 0x47, 0x65, 0x74, 0x4b, 0x64, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x54, 0x0a, 0x11, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4b, 0x64, 0x66, 0x4d, 0x69,
+	0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x4b, 0x64, 0x66, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x4b, 0x64, 0x66, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x57, 0x0a, 0x12, 0x46, 0x69, 0x6e, 0x69,
+	0x73, 0x68, 0x4b, 0x64, 0x66, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f,
+	0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x4b, 0x64, 0x66, 0x4d,
+	0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x20, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x4b, 0x64, 0x66,
+	0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x45, 0x0a, 0x0c, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x19, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x06, 0x4c, 0x6f, 0x67, 0x6f,
+	0x75, 0x74, 0x12, 0x13, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c,
+	0x6f, 0x67, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a,
+	0x11, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x41, 0x6c, 0x6c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x1e, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65,
+	0x41, 0x6c, 0x6c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65,
+	0x41, 0x6c, 0x6c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x19, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a,
+	0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x52, 0x65,
+	0x76, 0x6f, 0x6b, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x2e, 0x61, 0x75,
+	0x74, 0x68, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x52,
+	0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x57, 0x0a, 0x12, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4f, 0x74, 0x70,
+	0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1f, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4f, 0x74, 0x70, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x61, 0x75,
+	0x74, 0x68, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4f, 0x74, 0x70, 0x45, 0x6e, 0x72, 0x6f, 0x6c,
+	0x6c, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5d, 0x0a,
+	0x14, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x4f, 0x74, 0x70, 0x45, 0x6e, 0x72, 0x6f, 0x6c,
+	0x6c, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x21, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x72, 0x6d, 0x4f, 0x74, 0x70, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x4f, 0x74, 0x70, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c,
+	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a,
+	0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x4f, 0x74, 0x70, 0x12, 0x17, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x2e, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x4f, 0x74, 0x70, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x44, 0x69, 0x73, 0x61, 0x62,
+	0x6c, 0x65, 0x4f, 0x74, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a,
+	0x0e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12,
+	0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61, 0x73,
+	0x73, 0x77, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x0e, 0x53, 0x65,
+	0x74, 0x53, 0x72, 0x70, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x1b, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x2e, 0x53, 0x65, 0x74, 0x53, 0x72, 0x70, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x75, 0x74, 0x68,
+	0x2e, 0x53, 0x65, 0x74, 0x53, 0x72, 0x70, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12,
+	0x17, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65,
 	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e,
-	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x45, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x4c, 0x69,
-	0x73, 0x74, 0x12, 0x19, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65,
-	0x6d, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e,
-	0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x4c, 0x69, 0x73,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x14, 0x5a, 0x12, 0x6b, 0x65, 0x65,
-	0x70, 0x65, 0x72, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d,
+	0x12, 0x17, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x74,
+	0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x69, 0x74, 0x65, 0x6d,
+	0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x0d, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x74, 0x65,
+	0x6d, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1b, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x49, 0x74,
+	0x65, 0x6d, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a,
+	0x07, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x14, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e,
+	0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15,
+	0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49,
+	0x74, 0x65, 0x6d, 0x12, 0x17, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x69,
+	0x74, 0x65, 0x6d, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65,
+	0x6d, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x19, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65,
+	0x74, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1a, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d,
+	0x73, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x42, 0x0a,
+	0x0b, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x18, 0x2e, 0x69,
+	0x74, 0x65, 0x6d, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x53, 0x65,
+	0x61, 0x72, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x4b, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x48, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x12, 0x1b, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74,
+	0x65, 0x6d, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1c, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x57,
+	0x0a, 0x12, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x52, 0x65, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x52, 0x65, 0x73,
+	0x74, 0x6f, 0x72, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x49, 0x74,
+	0x65, 0x6d, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x1b, 0x2e, 0x69, 0x74, 0x65, 0x6d,
+	0x2e, 0x47, 0x65, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x47, 0x65,
+	0x74, 0x49, 0x74, 0x65, 0x6d, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x0a, 0x57, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65,
+	0x6d, 0x73, 0x12, 0x17, 0x2e, 0x69, 0x74, 0x65, 0x6d, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x49,
+	0x74, 0x65, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x69, 0x74,
+	0x65, 0x6d, 0x2e, 0x49, 0x74, 0x65, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x14,
+	0x5a, 0x12, 0x6b, 0x65, 0x65, 0x70, 0x65, 0x72, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_keeper_proto_goTypes = []interface{}{
-	(*LoginRequest)(nil),         // 0: auth.LoginRequest
-	(*CreateItemRequest)(nil),    // 1: item.CreateItemRequest
-	(*UpdateItemRequest)(nil),    // 2: item.UpdateItemRequest
-	(*GetItemRequest)(nil),       // 3: item.GetItemRequest
-	(*DeleteItemRequest)(nil),    // 4: item.DeleteItemRequest
-	(*GetItemsListRequest)(nil),  // 5: item.GetItemsListRequest
-	(*LoginResponse)(nil),        // 6: auth.LoginResponse
-	(*CreateItemResponse)(nil),   // 7: item.CreateItemResponse
-	(*UpdateItemResponse)(nil),   // 8: item.UpdateItemResponse
-	(*GetItemResponse)(nil),      // 9: item.GetItemResponse
-	(*DeleteItemResponse)(nil),   // 10: item.DeleteItemResponse
-	(*GetItemsListResponse)(nil), // 11: item.GetItemsListResponse
+	(*LoginRequest)(nil),                 // 0: auth.LoginRequest
+	(*RegisterSrpRequest)(nil),           // 1: auth.RegisterSrpRequest
+	(*StartSrpLoginRequest)(nil),         // 2: auth.StartSrpLoginRequest
+	(*FinishSrpLoginRequest)(nil),        // 3: auth.FinishSrpLoginRequest
+	(*VerifyOtpRequest)(nil),             // 4: auth.VerifyOtpRequest
+	(*GetKdfRequest)(nil),                // 5: auth.GetKdfRequest
+	(*StartKdfMigrationRequest)(nil),     // 6: auth.StartKdfMigrationRequest
+	(*FinishKdfMigrationRequest)(nil),    // 7: auth.FinishKdfMigrationRequest
+	(*RefreshTokenRequest)(nil),          // 8: auth.RefreshTokenRequest
+	(*LogoutRequest)(nil),                // 9: auth.LogoutRequest
+	(*RevokeAllSessionsRequest)(nil),     // 10: auth.RevokeAllSessionsRequest
+	(*ListSessionsRequest)(nil),          // 11: auth.ListSessionsRequest
+	(*RevokeSessionRequest)(nil),         // 12: auth.RevokeSessionRequest
+	(*StartOtpEnrollmentRequest)(nil),    // 13: auth.StartOtpEnrollmentRequest
+	(*ConfirmOtpEnrollmentRequest)(nil),  // 14: auth.ConfirmOtpEnrollmentRequest
+	(*DisableOtpRequest)(nil),            // 15: auth.DisableOtpRequest
+	(*ChangePasswordRequest)(nil),        // 16: auth.ChangePasswordRequest
+	(*SetSrpVerifierRequest)(nil),        // 17: auth.SetSrpVerifierRequest
+	(*DeleteAccountRequest)(nil),         // 18: auth.DeleteAccountRequest
+	(*CreateItemRequest)(nil),            // 19: item.CreateItemRequest
+	(*UpdateItemRequest)(nil),            // 20: item.UpdateItemRequest
+	(*UpdateItemKeyRequest)(nil),         // 21: item.UpdateItemKeyRequest
+	(*GetItemRequest)(nil),               // 22: item.GetItemRequest
+	(*DeleteItemRequest)(nil),            // 23: item.DeleteItemRequest
+	(*GetItemsListRequest)(nil),          // 24: item.GetItemsListRequest
+	(*SearchItemsRequest)(nil),           // 25: item.SearchItemsRequest
+	(*GetItemHistoryRequest)(nil),        // 26: item.GetItemHistoryRequest
+	(*RestoreItemVersionRequest)(nil),    // 27: item.RestoreItemVersionRequest
+	(*GetItemChangesRequest)(nil),        // 28: item.GetItemChangesRequest
+	(*WatchItemsRequest)(nil),            // 29: item.WatchItemsRequest
+	(*LoginResponse)(nil),                // 30: auth.LoginResponse
+	(*StartSrpLoginResponse)(nil),        // 31: auth.StartSrpLoginResponse
+	(*GetKdfResponse)(nil),               // 32: auth.GetKdfResponse
+	(*StartKdfMigrationResponse)(nil),    // 33: auth.StartKdfMigrationResponse
+	(*FinishKdfMigrationResponse)(nil),   // 34: auth.FinishKdfMigrationResponse
+	(*RefreshTokenResponse)(nil),         // 35: auth.RefreshTokenResponse
+	(*LogoutResponse)(nil),               // 36: auth.LogoutResponse
+	(*RevokeAllSessionsResponse)(nil),    // 37: auth.RevokeAllSessionsResponse
+	(*ListSessionsResponse)(nil),         // 38: auth.ListSessionsResponse
+	(*RevokeSessionResponse)(nil),        // 39: auth.RevokeSessionResponse
+	(*StartOtpEnrollmentResponse)(nil),   // 40: auth.StartOtpEnrollmentResponse
+	(*ConfirmOtpEnrollmentResponse)(nil), // 41: auth.ConfirmOtpEnrollmentResponse
+	(*DisableOtpResponse)(nil),           // 42: auth.DisableOtpResponse
+	(*ChangePasswordResponse)(nil),       // 43: auth.ChangePasswordResponse
+	(*SetSrpVerifierResponse)(nil),       // 44: auth.SetSrpVerifierResponse
+	(*DeleteAccountResponse)(nil),        // 45: auth.DeleteAccountResponse
+	(*CreateItemResponse)(nil),           // 46: item.CreateItemResponse
+	(*UpdateItemResponse)(nil),           // 47: item.UpdateItemResponse
+	(*UpdateItemKeyResponse)(nil),        // 48: item.UpdateItemKeyResponse
+	(*GetItemResponse)(nil),              // 49: item.GetItemResponse
+	(*DeleteItemResponse)(nil),           // 50: item.DeleteItemResponse
+	(*GetItemsListResponse)(nil),         // 51: item.GetItemsListResponse
+	(*SearchItemsResponse)(nil),          // 52: item.SearchItemsResponse
+	(*GetItemHistoryResponse)(nil),       // 53: item.GetItemHistoryResponse
+	(*RestoreItemVersionResponse)(nil),   // 54: item.RestoreItemVersionResponse
+	(*GetItemChangesResponse)(nil),       // 55: item.GetItemChangesResponse
+	(*ItemEvent)(nil),                    // 56: item.ItemEvent
 }
 var file_keeper_proto_depIdxs = []int32{
 	0,  // 0: keeper.KeeperService.Login:input_type -> auth.LoginRequest
 	0,  // 1: keeper.KeeperService.Register:input_type -> auth.LoginRequest
-	1,  // 2: keeper.KeeperService.CreateItem:input_type -> item.CreateItemRequest
-	2,  // 3: keeper.KeeperService.UpdateItem:input_type -> item.UpdateItemRequest
-	3,  // 4: keeper.KeeperService.GetItem:input_type -> item.GetItemRequest
-	4,  // 5: keeper.KeeperService.DeleteItem:input_type -> item.DeleteItemRequest
-	5,  // 6: keeper.KeeperService.GetItemsList:input_type -> item.GetItemsListRequest
-	6,  // 7: keeper.KeeperService.Login:output_type -> auth.LoginResponse
-	6,  // 8: keeper.KeeperService.Register:output_type -> auth.LoginResponse
-	7,  // 9: keeper.KeeperService.CreateItem:output_type -> item.CreateItemResponse
-	8,  // 10: keeper.KeeperService.UpdateItem:output_type -> item.UpdateItemResponse
-	9,  // 11: keeper.KeeperService.GetItem:output_type -> item.GetItemResponse
-	10, // 12: keeper.KeeperService.DeleteItem:output_type -> item.DeleteItemResponse
-	11, // 13: keeper.KeeperService.GetItemsList:output_type -> item.GetItemsListResponse
-	7,  // [7:14] is the sub-list for method output_type
-	0,  // [0:7] is the sub-list for method input_type
+	1,  // 2: keeper.KeeperService.RegisterSrp:input_type -> auth.RegisterSrpRequest
+	2,  // 3: keeper.KeeperService.StartSrpLogin:input_type -> auth.StartSrpLoginRequest
+	3,  // 4: keeper.KeeperService.FinishSrpLogin:input_type -> auth.FinishSrpLoginRequest
+	4,  // 5: keeper.KeeperService.VerifyOtp:input_type -> auth.VerifyOtpRequest
+	5,  // 6: keeper.KeeperService.GetKdf:input_type -> auth.GetKdfRequest
+	6,  // 7: keeper.KeeperService.StartKdfMigration:input_type -> auth.StartKdfMigrationRequest
+	7,  // 8: keeper.KeeperService.FinishKdfMigration:input_type -> auth.FinishKdfMigrationRequest
+	8,  // 9: keeper.KeeperService.RefreshToken:input_type -> auth.RefreshTokenRequest
+	9,  // 10: keeper.KeeperService.Logout:input_type -> auth.LogoutRequest
+	10, // 11: keeper.KeeperService.RevokeAllSessions:input_type -> auth.RevokeAllSessionsRequest
+	11, // 12: keeper.KeeperService.ListSessions:input_type -> auth.ListSessionsRequest
+	12, // 13: keeper.KeeperService.RevokeSession:input_type -> auth.RevokeSessionRequest
+	13, // 14: keeper.KeeperService.StartOtpEnrollment:input_type -> auth.StartOtpEnrollmentRequest
+	14, // 15: keeper.KeeperService.ConfirmOtpEnrollment:input_type -> auth.ConfirmOtpEnrollmentRequest
+	15, // 16: keeper.KeeperService.DisableOtp:input_type -> auth.DisableOtpRequest
+	16, // 17: keeper.KeeperService.ChangePassword:input_type -> auth.ChangePasswordRequest
+	17, // 18: keeper.KeeperService.SetSrpVerifier:input_type -> auth.SetSrpVerifierRequest
+	18, // 19: keeper.KeeperService.DeleteAccount:input_type -> auth.DeleteAccountRequest
+	19, // 20: keeper.KeeperService.CreateItem:input_type -> item.CreateItemRequest
+	20, // 21: keeper.KeeperService.UpdateItem:input_type -> item.UpdateItemRequest
+	21, // 22: keeper.KeeperService.UpdateItemKey:input_type -> item.UpdateItemKeyRequest
+	22, // 23: keeper.KeeperService.GetItem:input_type -> item.GetItemRequest
+	23, // 24: keeper.KeeperService.DeleteItem:input_type -> item.DeleteItemRequest
+	24, // 25: keeper.KeeperService.GetItemsList:input_type -> item.GetItemsListRequest
+	25, // 26: keeper.KeeperService.SearchItems:input_type -> item.SearchItemsRequest
+	26, // 27: keeper.KeeperService.GetItemHistory:input_type -> item.GetItemHistoryRequest
+	27, // 28: keeper.KeeperService.RestoreItemVersion:input_type -> item.RestoreItemVersionRequest
+	28, // 29: keeper.KeeperService.GetItemChanges:input_type -> item.GetItemChangesRequest
+	29, // 30: keeper.KeeperService.WatchItems:input_type -> item.WatchItemsRequest
+	30, // 31: keeper.KeeperService.Login:output_type -> auth.LoginResponse
+	30, // 32: keeper.KeeperService.Register:output_type -> auth.LoginResponse
+	30, // 33: keeper.KeeperService.RegisterSrp:output_type -> auth.LoginResponse
+	31, // 34: keeper.KeeperService.StartSrpLogin:output_type -> auth.StartSrpLoginResponse
+	30, // 35: keeper.KeeperService.FinishSrpLogin:output_type -> auth.LoginResponse
+	30, // 36: keeper.KeeperService.VerifyOtp:output_type -> auth.LoginResponse
+	32, // 37: keeper.KeeperService.GetKdf:output_type -> auth.GetKdfResponse
+	33, // 38: keeper.KeeperService.StartKdfMigration:output_type -> auth.StartKdfMigrationResponse
+	34, // 39: keeper.KeeperService.FinishKdfMigration:output_type -> auth.FinishKdfMigrationResponse
+	35, // 40: keeper.KeeperService.RefreshToken:output_type -> auth.RefreshTokenResponse
+	36, // 41: keeper.KeeperService.Logout:output_type -> auth.LogoutResponse
+	37, // 42: keeper.KeeperService.RevokeAllSessions:output_type -> auth.RevokeAllSessionsResponse
+	38, // 43: keeper.KeeperService.ListSessions:output_type -> auth.ListSessionsResponse
+	39, // 44: keeper.KeeperService.RevokeSession:output_type -> auth.RevokeSessionResponse
+	40, // 45: keeper.KeeperService.StartOtpEnrollment:output_type -> auth.StartOtpEnrollmentResponse
+	41, // 46: keeper.KeeperService.ConfirmOtpEnrollment:output_type -> auth.ConfirmOtpEnrollmentResponse
+	42, // 47: keeper.KeeperService.DisableOtp:output_type -> auth.DisableOtpResponse
+	43, // 48: keeper.KeeperService.ChangePassword:output_type -> auth.ChangePasswordResponse
+	44, // 49: keeper.KeeperService.SetSrpVerifier:output_type -> auth.SetSrpVerifierResponse
+	45, // 50: keeper.KeeperService.DeleteAccount:output_type -> auth.DeleteAccountResponse
+	46, // 51: keeper.KeeperService.CreateItem:output_type -> item.CreateItemResponse
+	47, // 52: keeper.KeeperService.UpdateItem:output_type -> item.UpdateItemResponse
+	48, // 53: keeper.KeeperService.UpdateItemKey:output_type -> item.UpdateItemKeyResponse
+	49, // 54: keeper.KeeperService.GetItem:output_type -> item.GetItemResponse
+	50, // 55: keeper.KeeperService.DeleteItem:output_type -> item.DeleteItemResponse
+	51, // 56: keeper.KeeperService.GetItemsList:output_type -> item.GetItemsListResponse
+	52, // 57: keeper.KeeperService.SearchItems:output_type -> item.SearchItemsResponse
+	53, // 58: keeper.KeeperService.GetItemHistory:output_type -> item.GetItemHistoryResponse
+	54, // 59: keeper.KeeperService.RestoreItemVersion:output_type -> item.RestoreItemVersionResponse
+	55, // 60: keeper.KeeperService.GetItemChanges:output_type -> item.GetItemChangesResponse
+	56, // 61: keeper.KeeperService.WatchItems:output_type -> item.ItemEvent
+	31, // [31:62] is the sub-list for method output_type
+	0,  // [0:31] is the sub-list for method input_type
 	0,  // [0:0] is the sub-list for extension type_name
 	0,  // [0:0] is the sub-list for extension extendee
 	0,  // [0:0] is the sub-list for field type_name
@@ -24,11 +24,51 @@ const _ = grpc.SupportPackageIsVersion7
 type KeeperServiceClient interface {
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 	Register(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// RegisterSrp register account with zero-knowledge login, server receives only password verifier.
+	RegisterSrp(ctx context.Context, in *RegisterSrpRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// StartSrpLogin and FinishSrpLogin login by SRP-6a exchange, so password is never sent to server.
+	StartSrpLogin(ctx context.Context, in *StartSrpLoginRequest, opts ...grpc.CallOption) (*StartSrpLoginResponse, error)
+	FinishSrpLogin(ctx context.Context, in *FinishSrpLoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// VerifyOtp finish login of account with two-factor authentication.
+	VerifyOtp(ctx context.Context, in *VerifyOtpRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	GetKdf(ctx context.Context, in *GetKdfRequest, opts ...grpc.CallOption) (*GetKdfResponse, error)
+	StartKdfMigration(ctx context.Context, in *StartKdfMigrationRequest, opts ...grpc.CallOption) (*StartKdfMigrationResponse, error)
+	FinishKdfMigration(ctx context.Context, in *FinishKdfMigrationRequest, opts ...grpc.CallOption) (*FinishKdfMigrationResponse, error)
+	// RefreshToken return new access and refresh tokens of the session refresh token belongs to, previous ones are revoked.
+	// Reuse of a replaced refresh token revokes the session.
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error)
+	// Logout revoke token of the call.
+	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*LogoutResponse, error)
+	// RevokeAllSessions revoke all tokens of the user including token of the call.
+	RevokeAllSessions(ctx context.Context, in *RevokeAllSessionsRequest, opts ...grpc.CallOption) (*RevokeAllSessionsResponse, error)
+	// ListSessions return not expired tokens of the user with devices they were issued to.
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	// RevokeSession revoke one of the user tokens by session ID.
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error)
+	// StartOtpEnrollment generate two-factor authentication secret after checking current password,
+	// it is enabled by ConfirmOtpEnrollment.
+	StartOtpEnrollment(ctx context.Context, in *StartOtpEnrollmentRequest, opts ...grpc.CallOption) (*StartOtpEnrollmentResponse, error)
+	ConfirmOtpEnrollment(ctx context.Context, in *ConfirmOtpEnrollmentRequest, opts ...grpc.CallOption) (*ConfirmOtpEnrollmentResponse, error)
+	// DisableOtp disable two-factor authentication, current password and one-time password are required.
+	DisableOtp(ctx context.Context, in *DisableOtpRequest, opts ...grpc.CallOption) (*DisableOtpResponse, error)
+	// ChangePassword replace the user password, tokens of the user except token of the call are revoked.
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error)
+	// SetSrpVerifier switch the user to zero-knowledge login or change its password, tokens of the user
+	// except token of the call are revoked.
+	SetSrpVerifier(ctx context.Context, in *SetSrpVerifierRequest, opts ...grpc.CallOption) (*SetSrpVerifierResponse, error)
+	// DeleteAccount remove the user with all tokens and items.
+	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*DeleteAccountResponse, error)
 	CreateItem(ctx context.Context, in *CreateItemRequest, opts ...grpc.CallOption) (*CreateItemResponse, error)
 	UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*UpdateItemResponse, error)
+	UpdateItemKey(ctx context.Context, in *UpdateItemKeyRequest, opts ...grpc.CallOption) (*UpdateItemKeyResponse, error)
 	GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*GetItemResponse, error)
 	DeleteItem(ctx context.Context, in *DeleteItemRequest, opts ...grpc.CallOption) (*DeleteItemResponse, error)
 	GetItemsList(ctx context.Context, in *GetItemsListRequest, opts ...grpc.CallOption) (*GetItemsListResponse, error)
+	SearchItems(ctx context.Context, in *SearchItemsRequest, opts ...grpc.CallOption) (*SearchItemsResponse, error)
+	GetItemHistory(ctx context.Context, in *GetItemHistoryRequest, opts ...grpc.CallOption) (*GetItemHistoryResponse, error)
+	RestoreItemVersion(ctx context.Context, in *RestoreItemVersionRequest, opts ...grpc.CallOption) (*RestoreItemVersionResponse, error)
+	GetItemChanges(ctx context.Context, in *GetItemChangesRequest, opts ...grpc.CallOption) (*GetItemChangesResponse, error)
+	WatchItems(ctx context.Context, in *WatchItemsRequest, opts ...grpc.CallOption) (KeeperService_WatchItemsClient, error)
 }
 
 type keeperServiceClient struct {
@@ -57,6 +97,168 @@ func (c *keeperServiceClient) Register(ctx context.Context, in *LoginRequest, op
 	return out, nil
 }
 
+func (c *keeperServiceClient) RegisterSrp(ctx context.Context, in *RegisterSrpRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/RegisterSrp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) StartSrpLogin(ctx context.Context, in *StartSrpLoginRequest, opts ...grpc.CallOption) (*StartSrpLoginResponse, error) {
+	out := new(StartSrpLoginResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/StartSrpLogin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) FinishSrpLogin(ctx context.Context, in *FinishSrpLoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/FinishSrpLogin", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) VerifyOtp(ctx context.Context, in *VerifyOtpRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/VerifyOtp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) GetKdf(ctx context.Context, in *GetKdfRequest, opts ...grpc.CallOption) (*GetKdfResponse, error) {
+	out := new(GetKdfResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/GetKdf", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) StartKdfMigration(ctx context.Context, in *StartKdfMigrationRequest, opts ...grpc.CallOption) (*StartKdfMigrationResponse, error) {
+	out := new(StartKdfMigrationResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/StartKdfMigration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) FinishKdfMigration(ctx context.Context, in *FinishKdfMigrationRequest, opts ...grpc.CallOption) (*FinishKdfMigrationResponse, error) {
+	out := new(FinishKdfMigrationResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/FinishKdfMigration", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error) {
+	out := new(RefreshTokenResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/RefreshToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*LogoutResponse, error) {
+	out := new(LogoutResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/Logout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) RevokeAllSessions(ctx context.Context, in *RevokeAllSessionsRequest, opts ...grpc.CallOption) (*RevokeAllSessionsResponse, error) {
+	out := new(RevokeAllSessionsResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/RevokeAllSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/ListSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error) {
+	out := new(RevokeSessionResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/RevokeSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) StartOtpEnrollment(ctx context.Context, in *StartOtpEnrollmentRequest, opts ...grpc.CallOption) (*StartOtpEnrollmentResponse, error) {
+	out := new(StartOtpEnrollmentResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/StartOtpEnrollment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) ConfirmOtpEnrollment(ctx context.Context, in *ConfirmOtpEnrollmentRequest, opts ...grpc.CallOption) (*ConfirmOtpEnrollmentResponse, error) {
+	out := new(ConfirmOtpEnrollmentResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/ConfirmOtpEnrollment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) DisableOtp(ctx context.Context, in *DisableOtpRequest, opts ...grpc.CallOption) (*DisableOtpResponse, error) {
+	out := new(DisableOtpResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/DisableOtp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error) {
+	out := new(ChangePasswordResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/ChangePassword", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) SetSrpVerifier(ctx context.Context, in *SetSrpVerifierRequest, opts ...grpc.CallOption) (*SetSrpVerifierResponse, error) {
+	out := new(SetSrpVerifierResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/SetSrpVerifier", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*DeleteAccountResponse, error) {
+	out := new(DeleteAccountResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/DeleteAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *keeperServiceClient) CreateItem(ctx context.Context, in *CreateItemRequest, opts ...grpc.CallOption) (*CreateItemResponse, error) {
 	out := new(CreateItemResponse)
 	err := c.cc.Invoke(ctx, "/keeper.KeeperService/CreateItem", in, out, opts...)
@@ -75,6 +277,15 @@ func (c *keeperServiceClient) UpdateItem(ctx context.Context, in *UpdateItemRequ
 	return out, nil
 }
 
+func (c *keeperServiceClient) UpdateItemKey(ctx context.Context, in *UpdateItemKeyRequest, opts ...grpc.CallOption) (*UpdateItemKeyResponse, error) {
+	out := new(UpdateItemKeyResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/UpdateItemKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *keeperServiceClient) GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*GetItemResponse, error) {
 	out := new(GetItemResponse)
 	err := c.cc.Invoke(ctx, "/keeper.KeeperService/GetItem", in, out, opts...)
@@ -102,17 +313,125 @@ func (c *keeperServiceClient) GetItemsList(ctx context.Context, in *GetItemsList
 	return out, nil
 }
 
+func (c *keeperServiceClient) SearchItems(ctx context.Context, in *SearchItemsRequest, opts ...grpc.CallOption) (*SearchItemsResponse, error) {
+	out := new(SearchItemsResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/SearchItems", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) GetItemHistory(ctx context.Context, in *GetItemHistoryRequest, opts ...grpc.CallOption) (*GetItemHistoryResponse, error) {
+	out := new(GetItemHistoryResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/GetItemHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) RestoreItemVersion(ctx context.Context, in *RestoreItemVersionRequest, opts ...grpc.CallOption) (*RestoreItemVersionResponse, error) {
+	out := new(RestoreItemVersionResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/RestoreItemVersion", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) GetItemChanges(ctx context.Context, in *GetItemChangesRequest, opts ...grpc.CallOption) (*GetItemChangesResponse, error) {
+	out := new(GetItemChangesResponse)
+	err := c.cc.Invoke(ctx, "/keeper.KeeperService/GetItemChanges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keeperServiceClient) WatchItems(ctx context.Context, in *WatchItemsRequest, opts ...grpc.CallOption) (KeeperService_WatchItemsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &KeeperService_ServiceDesc.Streams[0], "/keeper.KeeperService/WatchItems", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keeperServiceWatchItemsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeeperService_WatchItemsClient interface {
+	Recv() (*ItemEvent, error)
+	grpc.ClientStream
+}
+
+type keeperServiceWatchItemsClient struct {
+	grpc.ClientStream
+}
+
+func (x *keeperServiceWatchItemsClient) Recv() (*ItemEvent, error) {
+	m := new(ItemEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // KeeperServiceServer is the server API for KeeperService service.
 // All implementations must embed UnimplementedKeeperServiceServer
 // for forward compatibility
 type KeeperServiceServer interface {
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 	Register(context.Context, *LoginRequest) (*LoginResponse, error)
+	// RegisterSrp register account with zero-knowledge login, server receives only password verifier.
+	RegisterSrp(context.Context, *RegisterSrpRequest) (*LoginResponse, error)
+	// StartSrpLogin and FinishSrpLogin login by SRP-6a exchange, so password is never sent to server.
+	StartSrpLogin(context.Context, *StartSrpLoginRequest) (*StartSrpLoginResponse, error)
+	FinishSrpLogin(context.Context, *FinishSrpLoginRequest) (*LoginResponse, error)
+	// VerifyOtp finish login of account with two-factor authentication.
+	VerifyOtp(context.Context, *VerifyOtpRequest) (*LoginResponse, error)
+	GetKdf(context.Context, *GetKdfRequest) (*GetKdfResponse, error)
+	StartKdfMigration(context.Context, *StartKdfMigrationRequest) (*StartKdfMigrationResponse, error)
+	FinishKdfMigration(context.Context, *FinishKdfMigrationRequest) (*FinishKdfMigrationResponse, error)
+	// RefreshToken return new access and refresh tokens of the session refresh token belongs to, previous ones are revoked.
+	// Reuse of a replaced refresh token revokes the session.
+	RefreshToken(context.Context, *RefreshTokenRequest) (*RefreshTokenResponse, error)
+	// Logout revoke token of the call.
+	Logout(context.Context, *LogoutRequest) (*LogoutResponse, error)
+	// RevokeAllSessions revoke all tokens of the user including token of the call.
+	RevokeAllSessions(context.Context, *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error)
+	// ListSessions return not expired tokens of the user with devices they were issued to.
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	// RevokeSession revoke one of the user tokens by session ID.
+	RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error)
+	// StartOtpEnrollment generate two-factor authentication secret after checking current password,
+	// it is enabled by ConfirmOtpEnrollment.
+	StartOtpEnrollment(context.Context, *StartOtpEnrollmentRequest) (*StartOtpEnrollmentResponse, error)
+	ConfirmOtpEnrollment(context.Context, *ConfirmOtpEnrollmentRequest) (*ConfirmOtpEnrollmentResponse, error)
+	// DisableOtp disable two-factor authentication, current password and one-time password are required.
+	DisableOtp(context.Context, *DisableOtpRequest) (*DisableOtpResponse, error)
+	// ChangePassword replace the user password, tokens of the user except token of the call are revoked.
+	ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponse, error)
+	// SetSrpVerifier switch the user to zero-knowledge login or change its password, tokens of the user
+	// except token of the call are revoked.
+	SetSrpVerifier(context.Context, *SetSrpVerifierRequest) (*SetSrpVerifierResponse, error)
+	// DeleteAccount remove the user with all tokens and items.
+	DeleteAccount(context.Context, *DeleteAccountRequest) (*DeleteAccountResponse, error)
 	CreateItem(context.Context, *CreateItemRequest) (*CreateItemResponse, error)
 	UpdateItem(context.Context, *UpdateItemRequest) (*UpdateItemResponse, error)
+	UpdateItemKey(context.Context, *UpdateItemKeyRequest) (*UpdateItemKeyResponse, error)
 	GetItem(context.Context, *GetItemRequest) (*GetItemResponse, error)
 	DeleteItem(context.Context, *DeleteItemRequest) (*DeleteItemResponse, error)
 	GetItemsList(context.Context, *GetItemsListRequest) (*GetItemsListResponse, error)
+	SearchItems(context.Context, *SearchItemsRequest) (*SearchItemsResponse, error)
+	GetItemHistory(context.Context, *GetItemHistoryRequest) (*GetItemHistoryResponse, error)
+	RestoreItemVersion(context.Context, *RestoreItemVersionRequest) (*RestoreItemVersionResponse, error)
+	GetItemChanges(context.Context, *GetItemChangesRequest) (*GetItemChangesResponse, error)
+	WatchItems(*WatchItemsRequest, KeeperService_WatchItemsServer) error
 	mustEmbedUnimplementedKeeperServiceServer()
 }
 
@@ -126,12 +445,69 @@ func (UnimplementedKeeperServiceServer) Login(context.Context, *LoginRequest) (*
 func (UnimplementedKeeperServiceServer) Register(context.Context, *LoginRequest) (*LoginResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
 }
+func (UnimplementedKeeperServiceServer) RegisterSrp(context.Context, *RegisterSrpRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterSrp not implemented")
+}
+func (UnimplementedKeeperServiceServer) StartSrpLogin(context.Context, *StartSrpLoginRequest) (*StartSrpLoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartSrpLogin not implemented")
+}
+func (UnimplementedKeeperServiceServer) FinishSrpLogin(context.Context, *FinishSrpLoginRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinishSrpLogin not implemented")
+}
+func (UnimplementedKeeperServiceServer) VerifyOtp(context.Context, *VerifyOtpRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyOtp not implemented")
+}
+func (UnimplementedKeeperServiceServer) GetKdf(context.Context, *GetKdfRequest) (*GetKdfResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKdf not implemented")
+}
+func (UnimplementedKeeperServiceServer) StartKdfMigration(context.Context, *StartKdfMigrationRequest) (*StartKdfMigrationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartKdfMigration not implemented")
+}
+func (UnimplementedKeeperServiceServer) FinishKdfMigration(context.Context, *FinishKdfMigrationRequest) (*FinishKdfMigrationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinishKdfMigration not implemented")
+}
+func (UnimplementedKeeperServiceServer) RefreshToken(context.Context, *RefreshTokenRequest) (*RefreshTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedKeeperServiceServer) Logout(context.Context, *LogoutRequest) (*LogoutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedKeeperServiceServer) RevokeAllSessions(context.Context, *RevokeAllSessionsRequest) (*RevokeAllSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAllSessions not implemented")
+}
+func (UnimplementedKeeperServiceServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedKeeperServiceServer) RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedKeeperServiceServer) StartOtpEnrollment(context.Context, *StartOtpEnrollmentRequest) (*StartOtpEnrollmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartOtpEnrollment not implemented")
+}
+func (UnimplementedKeeperServiceServer) ConfirmOtpEnrollment(context.Context, *ConfirmOtpEnrollmentRequest) (*ConfirmOtpEnrollmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmOtpEnrollment not implemented")
+}
+func (UnimplementedKeeperServiceServer) DisableOtp(context.Context, *DisableOtpRequest) (*DisableOtpResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableOtp not implemented")
+}
+func (UnimplementedKeeperServiceServer) ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedKeeperServiceServer) SetSrpVerifier(context.Context, *SetSrpVerifierRequest) (*SetSrpVerifierResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSrpVerifier not implemented")
+}
+func (UnimplementedKeeperServiceServer) DeleteAccount(context.Context, *DeleteAccountRequest) (*DeleteAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
 func (UnimplementedKeeperServiceServer) CreateItem(context.Context, *CreateItemRequest) (*CreateItemResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateItem not implemented")
 }
 func (UnimplementedKeeperServiceServer) UpdateItem(context.Context, *UpdateItemRequest) (*UpdateItemResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateItem not implemented")
 }
+func (UnimplementedKeeperServiceServer) UpdateItemKey(context.Context, *UpdateItemKeyRequest) (*UpdateItemKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateItemKey not implemented")
+}
 func (UnimplementedKeeperServiceServer) GetItem(context.Context, *GetItemRequest) (*GetItemResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetItem not implemented")
 }
@@ -141,6 +517,21 @@ func (UnimplementedKeeperServiceServer) DeleteItem(context.Context, *DeleteItemR
 func (UnimplementedKeeperServiceServer) GetItemsList(context.Context, *GetItemsListRequest) (*GetItemsListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetItemsList not implemented")
 }
+func (UnimplementedKeeperServiceServer) SearchItems(context.Context, *SearchItemsRequest) (*SearchItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchItems not implemented")
+}
+func (UnimplementedKeeperServiceServer) GetItemHistory(context.Context, *GetItemHistoryRequest) (*GetItemHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItemHistory not implemented")
+}
+func (UnimplementedKeeperServiceServer) RestoreItemVersion(context.Context, *RestoreItemVersionRequest) (*RestoreItemVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreItemVersion not implemented")
+}
+func (UnimplementedKeeperServiceServer) GetItemChanges(context.Context, *GetItemChangesRequest) (*GetItemChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItemChanges not implemented")
+}
+func (UnimplementedKeeperServiceServer) WatchItems(*WatchItemsRequest, KeeperService_WatchItemsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchItems not implemented")
+}
 func (UnimplementedKeeperServiceServer) mustEmbedUnimplementedKeeperServiceServer() {}
 
 // UnsafeKeeperServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -190,132 +581,665 @@ func _KeeperService_Register_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeeperService_CreateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateItemRequest)
+func _KeeperService_RegisterSrp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterSrpRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeeperServiceServer).CreateItem(ctx, in)
+		return srv.(KeeperServiceServer).RegisterSrp(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/keeper.KeeperService/CreateItem",
+		FullMethod: "/keeper.KeeperService/RegisterSrp",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeeperServiceServer).CreateItem(ctx, req.(*CreateItemRequest))
+		return srv.(KeeperServiceServer).RegisterSrp(ctx, req.(*RegisterSrpRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeeperService_UpdateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateItemRequest)
+func _KeeperService_StartSrpLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartSrpLoginRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeeperServiceServer).UpdateItem(ctx, in)
+		return srv.(KeeperServiceServer).StartSrpLogin(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/keeper.KeeperService/UpdateItem",
+		FullMethod: "/keeper.KeeperService/StartSrpLogin",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeeperServiceServer).UpdateItem(ctx, req.(*UpdateItemRequest))
+		return srv.(KeeperServiceServer).StartSrpLogin(ctx, req.(*StartSrpLoginRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeeperService_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetItemRequest)
+func _KeeperService_FinishSrpLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinishSrpLoginRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeeperServiceServer).GetItem(ctx, in)
+		return srv.(KeeperServiceServer).FinishSrpLogin(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/keeper.KeeperService/GetItem",
+		FullMethod: "/keeper.KeeperService/FinishSrpLogin",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeeperServiceServer).GetItem(ctx, req.(*GetItemRequest))
+		return srv.(KeeperServiceServer).FinishSrpLogin(ctx, req.(*FinishSrpLoginRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeeperService_DeleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteItemRequest)
+func _KeeperService_VerifyOtp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyOtpRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeeperServiceServer).DeleteItem(ctx, in)
+		return srv.(KeeperServiceServer).VerifyOtp(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/keeper.KeeperService/DeleteItem",
+		FullMethod: "/keeper.KeeperService/VerifyOtp",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeeperServiceServer).DeleteItem(ctx, req.(*DeleteItemRequest))
+		return srv.(KeeperServiceServer).VerifyOtp(ctx, req.(*VerifyOtpRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeeperService_GetItemsList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetItemsListRequest)
+func _KeeperService_GetKdf_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKdfRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeeperServiceServer).GetItemsList(ctx, in)
+		return srv.(KeeperServiceServer).GetKdf(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/keeper.KeeperService/GetItemsList",
+		FullMethod: "/keeper.KeeperService/GetKdf",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeeperServiceServer).GetItemsList(ctx, req.(*GetItemsListRequest))
+		return srv.(KeeperServiceServer).GetKdf(ctx, req.(*GetKdfRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// KeeperService_ServiceDesc is the grpc.ServiceDesc for KeeperService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var KeeperService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "keeper.KeeperService",
-	HandlerType: (*KeeperServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Login",
-			Handler:    _KeeperService_Login_Handler,
-		},
-		{
-			MethodName: "Register",
-			Handler:    _KeeperService_Register_Handler,
-		},
-		{
-			MethodName: "CreateItem",
-			Handler:    _KeeperService_CreateItem_Handler,
-		},
-		{
-			MethodName: "UpdateItem",
-			Handler:    _KeeperService_UpdateItem_Handler,
-		},
-		{
-			MethodName: "GetItem",
-			Handler:    _KeeperService_GetItem_Handler,
-		},
-		{
-			MethodName: "DeleteItem",
-			Handler:    _KeeperService_DeleteItem_Handler,
-		},
-		{
-			MethodName: "GetItemsList",
-			Handler:    _KeeperService_GetItemsList_Handler,
+func _KeeperService_StartKdfMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartKdfMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).StartKdfMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/StartKdfMigration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).StartKdfMigration(ctx, req.(*StartKdfMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_FinishKdfMigration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinishKdfMigrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).FinishKdfMigration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/FinishKdfMigration",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).FinishKdfMigration(ctx, req.(*FinishKdfMigrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/RefreshToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/Logout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).Logout(ctx, req.(*LogoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_RevokeAllSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeAllSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).RevokeAllSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/RevokeAllSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).RevokeAllSessions(ctx, req.(*RevokeAllSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/ListSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/RevokeSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_StartOtpEnrollment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartOtpEnrollmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).StartOtpEnrollment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/StartOtpEnrollment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).StartOtpEnrollment(ctx, req.(*StartOtpEnrollmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_ConfirmOtpEnrollment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmOtpEnrollmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).ConfirmOtpEnrollment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/ConfirmOtpEnrollment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).ConfirmOtpEnrollment(ctx, req.(*ConfirmOtpEnrollmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_DisableOtp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableOtpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).DisableOtp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/DisableOtp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).DisableOtp(ctx, req.(*DisableOtpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_SetSrpVerifier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSrpVerifierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).SetSrpVerifier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/SetSrpVerifier",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).SetSrpVerifier(ctx, req.(*SetSrpVerifierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/DeleteAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).DeleteAccount(ctx, req.(*DeleteAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_CreateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).CreateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/CreateItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).CreateItem(ctx, req.(*CreateItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_UpdateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).UpdateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/UpdateItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).UpdateItem(ctx, req.(*UpdateItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_UpdateItemKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateItemKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).UpdateItemKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/UpdateItemKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).UpdateItemKey(ctx, req.(*UpdateItemKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).GetItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/GetItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).GetItem(ctx, req.(*GetItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_DeleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).DeleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/DeleteItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).DeleteItem(ctx, req.(*DeleteItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_GetItemsList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemsListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).GetItemsList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/GetItemsList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).GetItemsList(ctx, req.(*GetItemsListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_SearchItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).SearchItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/SearchItems",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).SearchItems(ctx, req.(*SearchItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_GetItemHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).GetItemHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/GetItemHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).GetItemHistory(ctx, req.(*GetItemHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_RestoreItemVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreItemVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).RestoreItemVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/RestoreItemVersion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).RestoreItemVersion(ctx, req.(*RestoreItemVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_GetItemChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeeperServiceServer).GetItemChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/keeper.KeeperService/GetItemChanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeeperServiceServer).GetItemChanges(ctx, req.(*GetItemChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeeperService_WatchItems_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchItemsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeeperServiceServer).WatchItems(m, &keeperServiceWatchItemsServer{stream})
+}
+
+type KeeperService_WatchItemsServer interface {
+	Send(*ItemEvent) error
+	grpc.ServerStream
+}
+
+type keeperServiceWatchItemsServer struct {
+	grpc.ServerStream
+}
+
+func (x *keeperServiceWatchItemsServer) Send(m *ItemEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// KeeperService_ServiceDesc is the grpc.ServiceDesc for KeeperService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var KeeperService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keeper.KeeperService",
+	HandlerType: (*KeeperServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Login",
+			Handler:    _KeeperService_Login_Handler,
+		},
+		{
+			MethodName: "Register",
+			Handler:    _KeeperService_Register_Handler,
+		},
+		{
+			MethodName: "RegisterSrp",
+			Handler:    _KeeperService_RegisterSrp_Handler,
+		},
+		{
+			MethodName: "StartSrpLogin",
+			Handler:    _KeeperService_StartSrpLogin_Handler,
+		},
+		{
+			MethodName: "FinishSrpLogin",
+			Handler:    _KeeperService_FinishSrpLogin_Handler,
+		},
+		{
+			MethodName: "VerifyOtp",
+			Handler:    _KeeperService_VerifyOtp_Handler,
+		},
+		{
+			MethodName: "GetKdf",
+			Handler:    _KeeperService_GetKdf_Handler,
+		},
+		{
+			MethodName: "StartKdfMigration",
+			Handler:    _KeeperService_StartKdfMigration_Handler,
+		},
+		{
+			MethodName: "FinishKdfMigration",
+			Handler:    _KeeperService_FinishKdfMigration_Handler,
+		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _KeeperService_RefreshToken_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _KeeperService_Logout_Handler,
+		},
+		{
+			MethodName: "RevokeAllSessions",
+			Handler:    _KeeperService_RevokeAllSessions_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _KeeperService_ListSessions_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _KeeperService_RevokeSession_Handler,
+		},
+		{
+			MethodName: "StartOtpEnrollment",
+			Handler:    _KeeperService_StartOtpEnrollment_Handler,
+		},
+		{
+			MethodName: "ConfirmOtpEnrollment",
+			Handler:    _KeeperService_ConfirmOtpEnrollment_Handler,
+		},
+		{
+			MethodName: "DisableOtp",
+			Handler:    _KeeperService_DisableOtp_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _KeeperService_ChangePassword_Handler,
+		},
+		{
+			MethodName: "SetSrpVerifier",
+			Handler:    _KeeperService_SetSrpVerifier_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _KeeperService_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "CreateItem",
+			Handler:    _KeeperService_CreateItem_Handler,
+		},
+		{
+			MethodName: "UpdateItem",
+			Handler:    _KeeperService_UpdateItem_Handler,
+		},
+		{
+			MethodName: "UpdateItemKey",
+			Handler:    _KeeperService_UpdateItemKey_Handler,
+		},
+		{
+			MethodName: "GetItem",
+			Handler:    _KeeperService_GetItem_Handler,
+		},
+		{
+			MethodName: "DeleteItem",
+			Handler:    _KeeperService_DeleteItem_Handler,
+		},
+		{
+			MethodName: "GetItemsList",
+			Handler:    _KeeperService_GetItemsList_Handler,
+		},
+		{
+			MethodName: "SearchItems",
+			Handler:    _KeeperService_SearchItems_Handler,
+		},
+		{
+			MethodName: "GetItemHistory",
+			Handler:    _KeeperService_GetItemHistory_Handler,
+		},
+		{
+			MethodName: "RestoreItemVersion",
+			Handler:    _KeeperService_RestoreItemVersion_Handler,
+		},
+		{
+			MethodName: "GetItemChanges",
+			Handler:    _KeeperService_GetItemChanges_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchItems",
+			Handler:       _KeeperService_WatchItems_Handler,
+			ServerStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "keeper.proto",
 }
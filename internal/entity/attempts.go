@@ -0,0 +1,12 @@
+package entity
+
+import (
+	"time"
+)
+
+// Attempts struct represent counter of failed login attempts of user login or client IP.
+type Attempts struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+}
@@ -6,12 +6,35 @@ import (
 
 // Item struct represent business entity for stored items in Keeper.
 type Item struct {
-	ID        string
-	UserID    string
-	Name      string
-	Type      string
-	Data      []byte
-	Metadata  []Metadata
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         string
+	UserID     string
+	Name       string
+	Type       string
+	Data       []byte
+	DataKey    []byte
+	Attributes []byte
+	Metadata   []Metadata
+	Revision   int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ItemSummary struct represent stored item without its secret data.
+type ItemSummary struct {
+	Name       string
+	Type       string
+	DataKey    []byte
+	Attributes []byte
+	Metadata   []Metadata
+	Revision   int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ItemFilter struct represent items search conditions, empty condition matches all items.
+// Name is item name substring or glob pattern, metadata record with empty value matches any value of its key.
+type ItemFilter struct {
+	Name     string
+	Type     string
+	Metadata []Metadata
 }
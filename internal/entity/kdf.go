@@ -0,0 +1,11 @@
+package entity
+
+// KDF struct represent parameters of user vault key derivation from user secret.
+// Parameters are stored with user, so every user client derives the same key.
+type KDF struct {
+	Version int32
+	Salt    []byte
+	Time    uint32
+	Memory  uint32
+	Threads uint32
+}
@@ -4,9 +4,24 @@ import (
 	"time"
 )
 
-// Token struct represent business entity for stored user token.
+// Token struct represent business entity for stored user session tokens.
+// Only hashes of access and refresh tokens secrets are stored.
+// PreviousRefreshHash is hash of refresh token replaced by the last rotation, it detects reuse of stolen token.
 type Token struct {
-	ID        string
-	UserLogin string
-	CreatedAt time.Time
+	ID                  string
+	UserLogin           string
+	AccessHash          string
+	RefreshHash         string
+	PreviousRefreshHash string
+	Device              Device
+	CreatedAt           time.Time
+	LastUsedAt          time.Time
+	AccessExpiresAt     time.Time
+}
+
+// Device struct represent client device user token was issued to.
+type Device struct {
+	Name          string
+	ClientVersion string
+	IP            string
 }
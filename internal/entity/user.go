@@ -5,9 +5,45 @@ import (
 )
 
 // User struct represent business entity for stored Keeper user.
+// PreviousKDF is set while user items are migrated to vault key derived by KDF,
+// OTP is set when user started two-factor authentication enrollment.
+// SRP is set for users with zero-knowledge login, PasswordHash is empty for them.
 type User struct {
 	ID           string
 	Login        string
 	PasswordHash string
+	KDF          KDF
+	PreviousKDF  *KDF
+	OTP          *OTP
+	SRP          *SRP
 	CreatedAt    time.Time
 }
+
+// SRP struct represent user SRP-6a password verifier. Password is stretched by KDF before verifier calculation,
+// KDF salt is used as SRP salt.
+type SRP struct {
+	KDF      KDF
+	Verifier []byte
+}
+
+// SRPExchange struct represent started SRP-6a login exchange waiting for client proof.
+type SRPExchange struct {
+	ID           string
+	UserLogin    string
+	ClientPublic []byte
+	ServerSecret []byte
+	ExpiresAt    time.Time
+}
+
+// OTP struct represent user TOTP two-factor authentication state.
+// Secret is set when two-factor authentication is enabled, PendingSecret is set while enrollment is not confirmed.
+// Challenge fields belong to login waiting for one-time password.
+type OTP struct {
+	Secret             []byte
+	PendingSecret      []byte
+	RecoveryCodeHashes []string
+	LastStep           int64
+	ChallengeHash      string
+	ChallengeExpiresAt time.Time
+	ChallengeAttempts  int
+}
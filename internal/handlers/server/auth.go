@@ -3,31 +3,353 @@ package server
 import (
 	"context"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	pb "keeper/gen/service"
+	"keeper/internal/handlers/server/interceptor"
+	"keeper/internal/services"
 )
 
 // Login implement rpc for user login call.
 func (s *KeeperServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
-	token, err := s.authService.Auth(ctx, in.GetLogin(), in.GetPassword())
+	result, err := s.authService.Auth(ctx, in.GetLogin(), in.GetPassword(), requestDevice(ctx, in.GetDevice()))
 	if err != nil {
 		return nil, err
 	}
 
-	response := pb.LoginResponse{
-		Token: token,
-	}
-	return &response, nil
+	return authResultToLoginResponse(result), nil
 }
 
 // Register implement rpc for user registration call.
 func (s *KeeperServer) Register(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
-	toke, err := s.authService.Register(ctx, in.GetLogin(), in.GetPassword())
+	result, err := s.authService.Register(ctx, in.GetLogin(), in.GetPassword(), requestDevice(ctx, in.GetDevice()))
 	if err != nil {
 		return nil, err
 	}
 
-	response := pb.LoginResponse{
-		Token: toke,
+	return authResultToLoginResponse(result), nil
+}
+
+// VerifyOtp implement rpc for finishing login with one-time password call.
+func (s *KeeperServer) VerifyOtp(ctx context.Context, in *pb.VerifyOtpRequest) (*pb.LoginResponse, error) {
+	device := requestDevice(ctx, in.GetDevice())
+	result, err := s.authService.VerifyOTP(ctx, in.GetLogin(), in.GetOtpChallenge(), in.GetCode(), device)
+	if err != nil {
+		return nil, err
+	}
+
+	return authResultToLoginResponse(result), nil
+}
+
+// GetKdf implement rpc for receiving user vault key derivation parameters call.
+func (s *KeeperServer) GetKdf(ctx context.Context, _ *pb.GetKdfRequest) (*pb.GetKdfResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	kdf, previousKDF, err := s.authService.GetKDF(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	response := pb.GetKdfResponse{
+		Kdf: kdfToKDFMessage(kdf),
+	}
+	if previousKDF != nil {
+		response.PreviousKdf = kdfToKDFMessage(*previousKDF)
+	}
+	return &response, nil
+}
+
+// StartKdfMigration implement rpc for starting user vault key derivation parameters change call.
+func (s *KeeperServer) StartKdfMigration(ctx context.Context, in *pb.StartKdfMigrationRequest) (*pb.StartKdfMigrationResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	if err := s.authService.StartKDFMigration(ctx, login, kdfMessageToKDF(in.GetKdf())); err != nil {
+		return nil, err
+	}
+
+	var response pb.StartKdfMigrationResponse
+	return &response, nil
+}
+
+// FinishKdfMigration implement rpc for finishing user vault key derivation parameters change call.
+func (s *KeeperServer) FinishKdfMigration(ctx context.Context, _ *pb.FinishKdfMigrationRequest) (*pb.FinishKdfMigrationResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	if err := s.authService.FinishKDFMigration(ctx, login); err != nil {
+		return nil, err
+	}
+
+	var response pb.FinishKdfMigrationResponse
+	return &response, nil
+}
+
+// RefreshToken implement rpc for receiving new access and refresh tokens by refresh token call.
+func (s *KeeperServer) RefreshToken(ctx context.Context, in *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	token, refreshToken, err := s.authService.RefreshToken(ctx, in.GetRefreshToken())
+	if err != nil {
+		return nil, err
+	}
+
+	response := pb.RefreshTokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}
+	return &response, nil
+}
+
+// Logout implement rpc for current token revoking call.
+func (s *KeeperServer) Logout(ctx context.Context, _ *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	if err := s.authService.Logout(ctx, getTokenFromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	var response pb.LogoutResponse
+	return &response, nil
+}
+
+// RevokeAllSessions implement rpc for all user tokens revoking call.
+func (s *KeeperServer) RevokeAllSessions(ctx context.Context, _ *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	if err := s.authService.RevokeAllSessions(ctx, login); err != nil {
+		return nil, err
+	}
+
+	var response pb.RevokeAllSessionsResponse
+	return &response, nil
+}
+
+// ListSessions implement rpc for receiving user not expired tokens call.
+func (s *KeeperServer) ListSessions(ctx context.Context, _ *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	sessions, err := s.authService.ListSessions(ctx, login, getTokenFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	response := pb.ListSessionsResponse{
+		Sessions: make([]*pb.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, sessionToSessionMessage(session))
+	}
+	return &response, nil
+}
+
+// RevokeSession implement rpc for user token revoking by session ID call.
+func (s *KeeperServer) RevokeSession(ctx context.Context, in *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	if err := s.authService.RevokeSession(ctx, login, in.GetId()); err != nil {
+		return nil, err
+	}
+
+	var response pb.RevokeSessionResponse
+	return &response, nil
+}
+
+// StartOtpEnrollment implement rpc for starting two-factor authentication enrollment call.
+func (s *KeeperServer) StartOtpEnrollment(ctx context.Context, in *pb.StartOtpEnrollmentRequest) (*pb.StartOtpEnrollmentResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	proof := services.SRPProof{
+		ExchangeID: in.GetSrpExchangeId(),
+		Proof:      in.GetSrpClientProof(),
+	}
+	uri, err := s.authService.StartOTPEnrollment(ctx, login, in.GetPassword(), proof, interceptor.ClientIP(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	response := pb.StartOtpEnrollmentResponse{
+		Uri: uri,
+	}
+	return &response, nil
+}
+
+// ConfirmOtpEnrollment implement rpc for enabling two-factor authentication call.
+func (s *KeeperServer) ConfirmOtpEnrollment(ctx context.Context, in *pb.ConfirmOtpEnrollmentRequest) (*pb.ConfirmOtpEnrollmentResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	codes, err := s.authService.ConfirmOTPEnrollment(ctx, login, in.GetCode())
+	if err != nil {
+		return nil, err
+	}
+
+	response := pb.ConfirmOtpEnrollmentResponse{
+		RecoveryCodes: codes,
+	}
+	return &response, nil
+}
+
+// DisableOtp implement rpc for disabling two-factor authentication call.
+func (s *KeeperServer) DisableOtp(ctx context.Context, in *pb.DisableOtpRequest) (*pb.DisableOtpResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	proof := services.SRPProof{
+		ExchangeID: in.GetSrpExchangeId(),
+		Proof:      in.GetSrpClientProof(),
+	}
+	if err := s.authService.DisableOTP(ctx, login, in.GetPassword(), proof, in.GetCode(), interceptor.ClientIP(ctx)); err != nil {
+		return nil, err
+	}
+
+	var response pb.DisableOtpResponse
+	return &response, nil
+}
+
+// ChangePassword implement rpc for user password change call.
+func (s *KeeperServer) ChangePassword(ctx context.Context, in *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	err := s.authService.ChangePassword(
+		ctx,
+		login,
+		getTokenFromContext(ctx),
+		in.GetOldPassword(),
+		in.GetNewPassword(),
+		interceptor.ClientIP(ctx),
+	)
+	if err != nil {
+		return nil, err
 	}
+
+	var response pb.ChangePasswordResponse
 	return &response, nil
 }
+
+// DeleteAccount implement rpc for user account deletion call.
+func (s *KeeperServer) DeleteAccount(ctx context.Context, in *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	proof := services.SRPProof{
+		ExchangeID: in.GetSrpExchangeId(),
+		Proof:      in.GetSrpClientProof(),
+	}
+	err := s.authService.DeleteAccount(ctx, login, in.GetPassword(), proof, interceptor.ClientIP(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var response pb.DeleteAccountResponse
+	return &response, nil
+}
+
+// RegisterSrp implement rpc for user registration with zero-knowledge login call.
+func (s *KeeperServer) RegisterSrp(ctx context.Context, in *pb.RegisterSrpRequest) (*pb.LoginResponse, error) {
+	device := requestDevice(ctx, in.GetDevice())
+	result, err := s.authService.RegisterSRP(ctx, in.GetLogin(), srpVerifierMessageToSRPVerifier(in.GetVerifier()), device)
+	if err != nil {
+		return nil, err
+	}
+
+	return authResultToLoginResponse(result), nil
+}
+
+// StartSrpLogin implement rpc for starting zero-knowledge login call.
+func (s *KeeperServer) StartSrpLogin(ctx context.Context, in *pb.StartSrpLoginRequest) (*pb.StartSrpLoginResponse, error) {
+	challenge, err := s.authService.StartSRPLogin(ctx, in.GetLogin(), in.GetClientPublic(), interceptor.ClientIP(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	response := pb.StartSrpLoginResponse{
+		Kdf:          kdfToKDFMessage(challenge.KDF),
+		ServerPublic: challenge.ServerPublic,
+		ExchangeId:   challenge.ExchangeID,
+	}
+	return &response, nil
+}
+
+// FinishSrpLogin implement rpc for finishing zero-knowledge login call.
+func (s *KeeperServer) FinishSrpLogin(ctx context.Context, in *pb.FinishSrpLoginRequest) (*pb.LoginResponse, error) {
+	device := requestDevice(ctx, in.GetDevice())
+	proof := services.SRPProof{
+		ExchangeID: in.GetExchangeId(),
+		Proof:      in.GetClientProof(),
+	}
+	result, err := s.authService.FinishSRPLogin(ctx, in.GetLogin(), proof, device)
+	if err != nil {
+		return nil, err
+	}
+
+	return authResultToLoginResponse(result), nil
+}
+
+// SetSrpVerifier implement rpc for switching user to zero-knowledge login or changing its password call.
+func (s *KeeperServer) SetSrpVerifier(ctx context.Context, in *pb.SetSrpVerifierRequest) (*pb.SetSrpVerifierResponse, error) {
+	login := getUserLoginFromContext(ctx)
+	proof := services.SRPProof{
+		ExchangeID: in.GetSrpExchangeId(),
+		Proof:      in.GetSrpClientProof(),
+	}
+	err := s.authService.SetSRPVerifier(
+		ctx,
+		login,
+		getTokenFromContext(ctx),
+		in.GetPassword(),
+		proof,
+		srpVerifierMessageToSRPVerifier(in.GetVerifier()),
+		interceptor.ClientIP(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var response pb.SetSrpVerifierResponse
+	return &response, nil
+}
+
+// requestDevice return device from request with client IP from request peer.
+func requestDevice(ctx context.Context, msg *pb.Device) services.Device {
+	return services.Device{
+		Name:          msg.GetName(),
+		ClientVersion: msg.GetClientVersion(),
+		IP:            interceptor.ClientIP(ctx),
+	}
+}
+
+func sessionToSessionMessage(session services.Session) *pb.Session {
+	return &pb.Session{
+		Id:            session.ID,
+		DeviceName:    session.Device.Name,
+		ClientVersion: session.Device.ClientVersion,
+		Ip:            session.Device.IP,
+		CreatedAt:     timestamppb.New(session.CreatedAt),
+		LastUsedAt:    timestamppb.New(session.LastUsedAt),
+		Current:       session.Current,
+	}
+}
+
+func authResultToLoginResponse(result services.AuthResult) *pb.LoginResponse {
+	if result.OTPChallenge != "" {
+		return &pb.LoginResponse{OtpChallenge: result.OTPChallenge, SrpServerProof: result.SRPServerProof}
+	}
+	response := pb.LoginResponse{
+		Token:          result.Token,
+		RefreshToken:   result.RefreshToken,
+		Kdf:            kdfToKDFMessage(result.KDF),
+		SrpServerProof: result.SRPServerProof,
+	}
+	if result.PreviousKDF != nil {
+		response.PreviousKdf = kdfToKDFMessage(*result.PreviousKDF)
+	}
+	return &response
+}
+
+func kdfToKDFMessage(kdf services.KDF) *pb.Kdf {
+	return &pb.Kdf{
+		Version: kdf.Version,
+		Salt:    kdf.Salt,
+		Time:    kdf.Time,
+		Memory:  kdf.Memory,
+		Threads: kdf.Threads,
+	}
+}
+
+func kdfMessageToKDF(msg *pb.Kdf) services.KDF {
+	return services.KDF{
+		Version: msg.GetVersion(),
+		Salt:    msg.GetSalt(),
+		Time:    msg.GetTime(),
+		Memory:  msg.GetMemory(),
+		Threads: msg.GetThreads(),
+	}
+}
+
+func srpVerifierMessageToSRPVerifier(msg *pb.SrpVerifier) services.SRPVerifier {
+	return services.SRPVerifier{
+		KDF:      kdfMessageToKDF(msg.GetKdf()),
+		Verifier: msg.GetVerifier(),
+	}
+}
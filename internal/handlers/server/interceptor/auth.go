@@ -2,10 +2,12 @@ package interceptor
 
 import (
 	"context"
+	"net"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	"keeper/internal/entity"
@@ -17,7 +19,20 @@ const (
 
 // TokenService interface set requirements for Auth interceptor.
 type TokenService interface {
-	GetUser(ctx context.Context, token string) (entity.User, error)
+	GetUser(ctx context.Context, token string, ip string) (entity.User, error)
+}
+
+// ClientIP return request client IP without port, empty when peer is unknown.
+func ClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
 }
 
 // Auth interceptor implement user auth validation.
@@ -26,25 +41,49 @@ func Auth(tokenService TokenService, skips map[string]bool) grpc.UnaryServerInte
 		if skip, ok := skips[info.FullMethod]; ok && skip {
 			return handler(ctx, req)
 		}
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, status.Error(codes.Internal, "empty metadata")
-		}
-		values := md.Get(tokenField)
-		if len(values) == 0 {
-			return nil, status.Error(codes.Unauthenticated, "token not provided")
-		}
-		token := values[0]
-		if token == "" {
-			return nil, status.Error(codes.Unauthenticated, "empty token provided")
-		}
-
-		user, err := tokenService.GetUser(ctx, token)
+		ctx, err := authenticate(ctx, tokenService)
 		if err != nil {
 			return nil, err
 		}
-		ctx = context.WithValue(ctx, "userID", user.ID)
 
 		return handler(ctx, req)
 	}
 }
+
+// AuthStream interceptor implement user auth validation for streams.
+func AuthStream(tokenService TokenService, skips map[string]bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if skip, ok := skips[info.FullMethod]; ok && skip {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), tokenService)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, tokenService TokenService) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Internal, "empty metadata")
+	}
+	values := md.Get(tokenField)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "token not provided")
+	}
+	token := values[0]
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "empty token provided")
+	}
+
+	user, err := tokenService.GetUser(ctx, token, ClientIP(ctx))
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, "token", token)
+	ctx = context.WithValue(ctx, "userID", user.ID)
+	return context.WithValue(ctx, "userLogin", user.Login), nil
+}
@@ -33,12 +33,32 @@ func GetValues(ctx context.Context) *Values {
 // Context interceptor inject additional values to GRPC request context.
 func Context() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		v := Values{
-			TraceID: uuid.NewString(),
-			Now:     time.Now(),
-		}
-		ctx = context.WithValue(ctx, key, &v)
+		return handler(withValues(ctx), req)
+	}
+}
 
-		return handler(ctx, req)
+// ContextStream interceptor inject additional values to GRPC stream context.
+func ContextStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withValues(ss.Context())})
 	}
 }
+
+func withValues(ctx context.Context) context.Context {
+	v := Values{
+		TraceID: uuid.NewString(),
+		Now:     time.Now(),
+	}
+	return context.WithValue(ctx, key, &v)
+}
+
+// serverStream wrap grpc.ServerStream for replacing its context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns replaced stream context.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
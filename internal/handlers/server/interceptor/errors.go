@@ -3,10 +3,13 @@ package interceptor
 import (
 	"context"
 	"errors"
+	"math"
+	"strconv"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"keeper/internal/repository"
@@ -18,35 +21,83 @@ func Errors(log *zap.SugaredLogger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		resp, err := handler(ctx, req)
 		if err != nil {
-			v := GetValues(ctx)
-			log.Errorw("ERROR", "trace_d", v.TraceID, "message", err)
-
-			_, isStatusError := status.FromError(err)
-			var wrappedError error
-			switch {
-			case isStatusError:
-				wrappedError = err
-			case errors.Is(err, repository.ErrItemAlreadyExist):
-				wrappedError = status.Error(codes.AlreadyExists, err.Error())
-			case errors.Is(err, repository.ErrItemNotFound):
-				wrappedError = status.Error(codes.NotFound, err.Error())
-			case errors.Is(err, repository.ErrTokenNotFound):
-				wrappedError = status.Error(codes.Unauthenticated, "authentication required")
-			case errors.Is(err, repository.ErrTokenExpired):
-				wrappedError = status.Error(codes.Unauthenticated, "authentication required")
-			case errors.Is(err, repository.ErrUserNotFound):
-				wrappedError = status.Error(codes.Unauthenticated, "wrong login or password")
-			case errors.Is(err, services.ErrUserInvalidPassword):
-				wrappedError = status.Error(codes.Unauthenticated, "wrong login or password")
-			case errors.Is(err, repository.ErrUserAlreadyExist):
-				wrappedError = status.Error(codes.AlreadyExists, "user login already exist")
-			case services.IsFieldErrors(err):
-				wrappedError = status.Error(codes.InvalidArgument, err.Error())
-			default:
-				wrappedError = status.Error(codes.Internal, "internal server error")
-			}
-			return nil, wrappedError
+			return nil, wrapError(ctx, log, err)
 		}
 		return resp, nil
 	}
 }
+
+// ErrorsStream interceptor wrap stream errors in GRPC codes and log original error message.
+func ErrorsStream(log *zap.SugaredLogger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return wrapError(ss.Context(), log, err)
+		}
+		return nil
+	}
+}
+
+func wrapError(ctx context.Context, log *zap.SugaredLogger, err error) error {
+	v := GetValues(ctx)
+	log.Errorw("ERROR", "trace_d", v.TraceID, "message", err)
+
+	_, isStatusError := status.FromError(err)
+	var attemptsError *services.AttemptsError
+	var wrappedError error
+	switch {
+	case isStatusError:
+		wrappedError = err
+	case errors.Is(err, repository.ErrItemAlreadyExist):
+		wrappedError = status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, repository.ErrItemNotFound):
+		wrappedError = status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrItemRevisionMismatch):
+		wrappedError = status.Error(codes.FailedPrecondition, "item was changed by another client")
+	case errors.Is(err, services.ErrItemVersionNotFound):
+		wrappedError = status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrOTPInvalid):
+		wrappedError = status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrOTPEnabled):
+		wrappedError = status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrOTPNotEnabled):
+		wrappedError = status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrOTPEnrollmentNotStarted):
+		wrappedError = status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrOTPChanged):
+		wrappedError = status.Error(codes.Aborted, "two-factor authentication state was changed by another request")
+	case errors.As(err, &attemptsError):
+		retryAfter := strconv.Itoa(int(math.Ceil(attemptsError.RetryAfter.Seconds())))
+		//goland:noinspection GoUnhandledErrorResult
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+		wrappedError = status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrSRPProofRequired):
+		wrappedError = status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrSRPInvalidRequest):
+		wrappedError = status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrCurrentPasswordInvalid):
+		wrappedError = status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrSessionNotFound):
+		wrappedError = status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrItemEventsOverflow):
+		wrappedError = status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, services.ErrRefreshTokenReused):
+		wrappedError = status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, repository.ErrTokenNotFound):
+		wrappedError = status.Error(codes.Unauthenticated, "authentication required")
+	case errors.Is(err, repository.ErrTokenExpired):
+		wrappedError = status.Error(codes.Unauthenticated, "authentication required")
+	case errors.Is(err, repository.ErrUserNotFound):
+		wrappedError = status.Error(codes.Unauthenticated, "wrong login or password")
+	case errors.Is(err, services.ErrUserInvalidPassword):
+		wrappedError = status.Error(codes.Unauthenticated, "wrong login or password")
+	case errors.Is(err, repository.ErrUserAlreadyExist):
+		wrappedError = status.Error(codes.AlreadyExists, "user login already exist")
+	case errors.Is(err, services.ErrKDFMigrationInProgress):
+		wrappedError = status.Error(codes.FailedPrecondition, err.Error())
+	case services.IsFieldErrors(err):
+		wrappedError = status.Error(codes.InvalidArgument, err.Error())
+	default:
+		wrappedError = status.Error(codes.Internal, "internal server error")
+	}
+	return wrappedError
+}
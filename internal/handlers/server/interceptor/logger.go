@@ -13,18 +13,37 @@ import (
 // Logger interceptor log all GRPC requests.
 func Logger(log *zap.SugaredLogger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		v := GetValues(ctx)
-		var clientIP string
-		if p, ok := peer.FromContext(ctx); ok {
-			clientIP = p.Addr.String()
-		}
-		log.Infow(
-			"request started",
-			"trace_id", v.TraceID,
-			"method", info.FullMethod,
-			"client", clientIP,
-		)
+		completed := logStarted(ctx, log, info.FullMethod)
 		h, err := handler(ctx, req)
+		completed(err)
+		return h, err
+	}
+}
+
+// LoggerStream interceptor log all GRPC streams.
+func LoggerStream(log *zap.SugaredLogger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		completed := logStarted(ss.Context(), log, info.FullMethod)
+		err := handler(srv, ss)
+		completed(err)
+		return err
+	}
+}
+
+// logStarted log request start and return function for logging request completion.
+func logStarted(ctx context.Context, log *zap.SugaredLogger, method string) func(err error) {
+	v := GetValues(ctx)
+	var clientIP string
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = p.Addr.String()
+	}
+	log.Infow(
+		"request started",
+		"trace_id", v.TraceID,
+		"method", method,
+		"client", clientIP,
+	)
+	return func(err error) {
 		code := "OK"
 		st, ok := status.FromError(err)
 		if ok {
@@ -33,11 +52,10 @@ func Logger(log *zap.SugaredLogger) grpc.UnaryServerInterceptor {
 		log.Infow(
 			"request completed",
 			"trace_id", v.TraceID,
-			"method", info.FullMethod,
+			"method", method,
 			"client", clientIP,
 			"code", code,
 			"since", time.Since(v.Now),
 		)
-		return h, err
 	}
 }
@@ -11,12 +11,22 @@ import (
 // Panics interceptor catch all panics and wrap it into error.
 func Panics() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		defer func() {
-			if rec := recover(); rec != nil {
-				trace := debug.Stack()
-				err = fmt.Errorf("PANIC [%v] TRACE [%s]", rec, string(trace))
-			}
-		}()
+		defer recoverPanic(&err)
 		return handler(ctx, req)
 	}
 }
+
+// PanicsStream interceptor catch all stream panics and wrap it into error.
+func PanicsStream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(&err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverPanic(err *error) {
+	if rec := recover(); rec != nil {
+		trace := debug.Stack()
+		*err = fmt.Errorf("PANIC [%v] TRACE [%s]", rec, string(trace))
+	}
+}
@@ -3,10 +3,20 @@ package server
 import (
 	"context"
 
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	pb "keeper/gen/service"
 	"keeper/internal/services"
 )
 
+var (
+	itemEventTypes = map[string]pb.ItemEvent_Type{
+		services.ItemEventCreated: pb.ItemEvent_CREATED,
+		services.ItemEventUpdated: pb.ItemEvent_UPDATED,
+		services.ItemEventDeleted: pb.ItemEvent_DELETED,
+	}
+)
+
 // CreateItem implement rpc for item creation call.
 func (s *KeeperServer) CreateItem(ctx context.Context, in *pb.CreateItemRequest) (*pb.CreateItemResponse, error) {
 	userID := getUserIDFromContext(ctx)
@@ -23,7 +33,11 @@ func (s *KeeperServer) CreateItem(ctx context.Context, in *pb.CreateItemRequest)
 func (s *KeeperServer) UpdateItem(ctx context.Context, in *pb.UpdateItemRequest) (*pb.UpdateItemResponse, error) {
 	userID := getUserIDFromContext(ctx)
 	item := itemMessageToItemEntity(in.GetItem())
-	if err := s.itemService.Update(ctx, userID, item); err != nil {
+	update := s.itemService.Update
+	if in.GetReplace() {
+		update = s.itemService.Replace
+	}
+	if err := update(ctx, userID, item, in.GetRevision()); err != nil {
 		return nil, err
 	}
 
@@ -31,6 +45,17 @@ func (s *KeeperServer) UpdateItem(ctx context.Context, in *pb.UpdateItemRequest)
 	return &response, nil
 }
 
+// UpdateItemKey implement rpc for item data key replacing call.
+func (s *KeeperServer) UpdateItemKey(ctx context.Context, in *pb.UpdateItemKeyRequest) (*pb.UpdateItemKeyResponse, error) {
+	userID := getUserIDFromContext(ctx)
+	if err := s.itemService.UpdateKey(ctx, userID, in.GetName(), in.GetDataKey(), in.GetVersionDataKeys(), in.GetRevision()); err != nil {
+		return nil, err
+	}
+
+	var response pb.UpdateItemKeyResponse
+	return &response, nil
+}
+
 // GetItem implement rpc for item receiving call.
 func (s *KeeperServer) GetItem(ctx context.Context, in *pb.GetItemRequest) (*pb.GetItemResponse, error) {
 	userID := getUserIDFromContext(ctx)
@@ -48,7 +73,7 @@ func (s *KeeperServer) GetItem(ctx context.Context, in *pb.GetItemRequest) (*pb.
 // DeleteItem implement rpc for item deletion call.
 func (s *KeeperServer) DeleteItem(ctx context.Context, in *pb.DeleteItemRequest) (*pb.DeleteItemResponse, error) {
 	userID := getUserIDFromContext(ctx)
-	if err := s.itemService.Delete(ctx, userID, in.GetName()); err != nil {
+	if err := s.itemService.Delete(ctx, userID, in.GetName(), in.GetRevision()); err != nil {
 		return nil, err
 	}
 
@@ -57,23 +82,130 @@ func (s *KeeperServer) DeleteItem(ctx context.Context, in *pb.DeleteItemRequest)
 }
 
 // GetItemsList implement rpc for receiving items list call.
-func (s *KeeperServer) GetItemsList(ctx context.Context, _ *pb.GetItemsListRequest) (*pb.GetItemsListResponse, error) {
+func (s *KeeperServer) GetItemsList(ctx context.Context, in *pb.GetItemsListRequest) (*pb.GetItemsListResponse, error) {
 	userID := getUserIDFromContext(ctx)
-	items, err := s.itemService.List(ctx, userID)
+	items, nextPageToken, err := s.itemService.List(ctx, userID, int(in.GetPageSize()), in.GetPageToken())
 	if err != nil {
 		return nil, err
 	}
 	response := pb.GetItemsListResponse{
-		Names: items,
+		NextPageToken: nextPageToken,
+	}
+	for _, item := range items {
+		response.Items = append(response.Items, itemSummaryToItemSummaryMessage(item))
 	}
 	return &response, nil
 }
 
+// SearchItems implement rpc for receiving items matching filter call.
+func (s *KeeperServer) SearchItems(ctx context.Context, in *pb.SearchItemsRequest) (*pb.SearchItemsResponse, error) {
+	userID := getUserIDFromContext(ctx)
+	filter := services.ItemFilter{
+		Name: in.GetName(),
+		Type: in.GetType(),
+	}
+	for _, m := range in.GetMetadata() {
+		metadata := services.Metadata{
+			Key:   m.GetKey(),
+			Value: m.GetValue(),
+		}
+		filter.Metadata = append(filter.Metadata, metadata)
+	}
+	items, err := s.itemService.Search(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	var response pb.SearchItemsResponse
+	for _, item := range items {
+		response.Items = append(response.Items, itemSummaryToItemSummaryMessage(item))
+	}
+	return &response, nil
+}
+
+// GetItemHistory implement rpc for receiving item previous versions call.
+func (s *KeeperServer) GetItemHistory(ctx context.Context, in *pb.GetItemHistoryRequest) (*pb.GetItemHistoryResponse, error) {
+	userID := getUserIDFromContext(ctx)
+	versions, err := s.itemService.History(ctx, userID, in.GetName())
+	if err != nil {
+		return nil, err
+	}
+	var response pb.GetItemHistoryResponse
+	for _, v := range versions {
+		version := pb.ItemVersion{
+			Version:   v.Version,
+			UpdatedAt: timestamppb.New(v.UpdatedAt),
+			DataKey:   v.DataKey,
+		}
+		response.Versions = append(response.Versions, &version)
+	}
+	return &response, nil
+}
+
+// RestoreItemVersion implement rpc for item previous version restoring call.
+func (s *KeeperServer) RestoreItemVersion(ctx context.Context, in *pb.RestoreItemVersionRequest) (*pb.RestoreItemVersionResponse, error) {
+	userID := getUserIDFromContext(ctx)
+	if err := s.itemService.Restore(ctx, userID, in.GetName(), in.GetVersion(), in.GetRevision()); err != nil {
+		return nil, err
+	}
+
+	var response pb.RestoreItemVersionResponse
+	return &response, nil
+}
+
+// GetItemChanges implement rpc for receiving items changed after cursor call.
+func (s *KeeperServer) GetItemChanges(ctx context.Context, in *pb.GetItemChangesRequest) (*pb.GetItemChangesResponse, error) {
+	userID := getUserIDFromContext(ctx)
+	changes, err := s.itemService.Changes(ctx, userID, in.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+	response := pb.GetItemChangesResponse{
+		Deleted: changes.Deleted,
+		Cursor:  changes.Cursor,
+	}
+	for _, item := range changes.Items {
+		response.Items = append(response.Items, itemEntityToItemMessage(item))
+	}
+	return &response, nil
+}
+
+// WatchItems implement rpc for streaming user item events.
+func (s *KeeperServer) WatchItems(_ *pb.WatchItemsRequest, stream pb.KeeperService_WatchItemsServer) error {
+	ctx := stream.Context()
+	userID := getUserIDFromContext(ctx)
+	events, unsubscribe := s.itemService.Watch(userID)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return services.ErrItemEventsOverflow
+			}
+			msg := pb.ItemEvent{
+				Type:      itemEventTypes[event.Type],
+				Name:      event.Name,
+				UpdatedAt: timestamppb.New(event.UpdatedAt),
+				Revision:  event.Revision,
+			}
+			if err := stream.Send(&msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func itemMessageToItemEntity(msg *pb.Item) services.Item {
 	item := services.Item{
-		Name: msg.GetName(),
-		Type: msg.GetType(),
-		Data: msg.GetData(),
+		Name:       msg.GetName(),
+		Type:       msg.GetType(),
+		Data:       msg.GetData(),
+		DataKey:    msg.GetDataKey(),
+		Attributes: msg.GetAttributes(),
 	}
 	for _, m := range msg.GetMetadata() {
 		metadata := services.Metadata{
@@ -87,9 +219,32 @@ func itemMessageToItemEntity(msg *pb.Item) services.Item {
 
 func itemEntityToItemMessage(item services.Item) *pb.Item {
 	msg := pb.Item{
-		Name: item.Name,
-		Type: item.Type,
-		Data: item.Data,
+		Name:       item.Name,
+		Type:       item.Type,
+		Data:       item.Data,
+		DataKey:    item.DataKey,
+		Attributes: item.Attributes,
+		Revision:   item.Revision,
+	}
+	for _, m := range item.Metadata {
+		metadata := pb.Metadata{
+			Key:   m.Key,
+			Value: m.Value,
+		}
+		msg.Metadata = append(msg.Metadata, &metadata)
+	}
+	return &msg
+}
+
+func itemSummaryToItemSummaryMessage(item services.ItemSummary) *pb.ItemSummary {
+	msg := pb.ItemSummary{
+		Name:       item.Name,
+		Type:       item.Type,
+		DataKey:    item.DataKey,
+		Attributes: item.Attributes,
+		Revision:   item.Revision,
+		CreatedAt:  timestamppb.New(item.CreatedAt),
+		UpdatedAt:  timestamppb.New(item.UpdatedAt),
 	}
 	for _, m := range item.Metadata {
 		metadata := pb.Metadata{
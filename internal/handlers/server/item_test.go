@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	pb "keeper/gen/service"
+	"keeper/internal/repository/memory"
+	"keeper/internal/services"
+)
+
+// blockedWatchStream hold sending of item events till released.
+type blockedWatchStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sending chan struct{}
+	release chan struct{}
+}
+
+func (s *blockedWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *blockedWatchStream) Send(*pb.ItemEvent) error {
+	select {
+	case s.sending <- struct{}{}:
+	default:
+	}
+	<-s.release
+	return nil
+}
+
+func TestWatchItemsOverflow(t *testing.T) {
+	// User ID is put to context by auth interceptor.
+	ctx := context.WithValue(context.Background(), "userID", "user")
+	items := services.NewItemService(&services.UuidGenerator{}, memory.NewItemRepository())
+	s := NewKeeperServer(KeeperServerConfig{Item: items})
+	stream := blockedWatchStream{
+		ctx:     ctx,
+		sending: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchItems(&pb.WatchItemsRequest{}, &stream)
+	}()
+
+	item := services.Item{Name: "note", Type: "text", Data: []byte("data")}
+	if err := items.Create(ctx, "user", item); err != nil {
+		t.Fatal(err)
+	}
+	// Events published before handler subscribed are not received, so changes are repeated till one is sent.
+	for sending := false; !sending; {
+		select {
+		case <-stream.sending:
+			sending = true
+		case <-time.After(10 * time.Millisecond):
+			if err := items.Update(ctx, "user", item, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// Handler is blocked by the first event, so the rest overflow subscriber buffer of 16 events.
+	for i := 0; i < 100; i++ {
+		if err := items.Update(ctx, "user", item, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stream.release)
+
+	if err := <-done; !errors.Is(err, services.ErrItemEventsOverflow) {
+		t.Errorf("WatchItems() error = %v, want %v", err, services.ErrItemEventsOverflow)
+	}
+}
@@ -2,11 +2,13 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	pb "keeper/gen/service"
 	"keeper/internal/entity"
@@ -16,22 +18,47 @@ import (
 
 // AuthService interface set requirements for auth rpc.
 type AuthService interface {
-	Auth(ctx context.Context, login string, password string) (string, error)
-	Register(ctx context.Context, login string, password string) (string, error)
+	Auth(ctx context.Context, login string, password string, device services.Device) (services.AuthResult, error)
+	Register(ctx context.Context, login string, password string, device services.Device) (services.AuthResult, error)
+	GetKDF(ctx context.Context, login string) (services.KDF, *services.KDF, error)
+	StartKDFMigration(ctx context.Context, login string, kdf services.KDF) error
+	FinishKDFMigration(ctx context.Context, login string) error
+	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	VerifyOTP(ctx context.Context, login string, challenge string, code string, device services.Device) (services.AuthResult, error)
+	StartOTPEnrollment(ctx context.Context, login string, password string, proof services.SRPProof, ip string) (string, error)
+	ConfirmOTPEnrollment(ctx context.Context, login string, code string) ([]string, error)
+	DisableOTP(ctx context.Context, login string, password string, proof services.SRPProof, code string, ip string) error
+	Logout(ctx context.Context, token string) error
+	RevokeAllSessions(ctx context.Context, login string) error
+	ListSessions(ctx context.Context, login string, token string) ([]services.Session, error)
+	RevokeSession(ctx context.Context, login string, id string) error
+	ChangePassword(ctx context.Context, login string, token string, oldPassword string, newPassword string, ip string) error
+	DeleteAccount(ctx context.Context, login string, password string, proof services.SRPProof, ip string) error
+	RegisterSRP(ctx context.Context, login string, verifier services.SRPVerifier, device services.Device) (services.AuthResult, error)
+	StartSRPLogin(ctx context.Context, login string, clientPublic []byte, ip string) (services.SRPChallenge, error)
+	FinishSRPLogin(ctx context.Context, login string, proof services.SRPProof, device services.Device) (services.AuthResult, error)
+	SetSRPVerifier(ctx context.Context, login string, token string, password string, proof services.SRPProof, verifier services.SRPVerifier, ip string) error
 }
 
 // TokenService interface set requirements for auth rpc.
 type TokenService interface {
-	GetUser(ctx context.Context, token string) (entity.User, error)
+	GetUser(ctx context.Context, token string, ip string) (entity.User, error)
 }
 
 // ItemService interface set requirements for item rpc.
 type ItemService interface {
 	Create(ctx context.Context, userID string, item services.Item) error
-	Update(ctx context.Context, userID string, item services.Item) error
+	Update(ctx context.Context, userID string, item services.Item, revision int64) error
+	Replace(ctx context.Context, userID string, item services.Item, revision int64) error
+	UpdateKey(ctx context.Context, userID string, name string, dataKey []byte, versionKeys [][]byte, revision int64) error
 	Get(ctx context.Context, userID string, name string) (services.Item, error)
-	Delete(ctx context.Context, userID string, name string) error
-	List(ctx context.Context, userID string) ([]string, error)
+	Delete(ctx context.Context, userID string, name string, revision int64) error
+	List(ctx context.Context, userID string, pageSize int, pageToken string) ([]services.ItemSummary, string, error)
+	Search(ctx context.Context, userID string, filter services.ItemFilter) ([]services.ItemSummary, error)
+	History(ctx context.Context, userID string, name string) ([]services.ItemVersion, error)
+	Restore(ctx context.Context, userID string, name string, version int64, revision int64) error
+	Changes(ctx context.Context, userID string, cursor int64) (services.ItemChanges, error)
+	Watch(userID string) (<-chan services.ItemEvent, func())
 }
 
 // KeeperServerConfig contains required dependencies for KeeperServer.
@@ -40,53 +67,75 @@ type KeeperServerConfig struct {
 	Auth  AuthService
 	Token TokenService
 	Item  ItemService
+	// TLS config enables TLS for server connections, nil config means plaintext connections.
+	TLS *tls.Config
 }
 
 // KeeperServer implement GRPC handlers for Keeper server.
 type KeeperServer struct {
 	pb.UnimplementedKeeperServiceServer
 	server       *grpc.Server
+	done         chan struct{}
 	log          *zap.SugaredLogger
 	authService  AuthService
 	tokenService TokenService
 	itemService  ItemService
+	tlsConfig    *tls.Config
 }
 
 // NewKeeperServer constructs new KeeperServer.
 func NewKeeperServer(cfg KeeperServerConfig) *KeeperServer {
 	s := KeeperServer{
+		done:         make(chan struct{}),
 		log:          cfg.Log,
 		authService:  cfg.Auth,
 		tokenService: cfg.Token,
 		itemService:  cfg.Item,
+		tlsConfig:    cfg.TLS,
 	}
 	return &s
 }
 
 // Serve run GRPC server.
 func (s *KeeperServer) Serve(listen net.Listener) error {
-	server := grpc.NewServer(
+	skips := map[string]bool{
+		"/keeper.KeeperService/Login":          true,
+		"/keeper.KeeperService/Register":       true,
+		"/keeper.KeeperService/VerifyOtp":      true,
+		"/keeper.KeeperService/RegisterSrp":    true,
+		"/keeper.KeeperService/StartSrpLogin":  true,
+		"/keeper.KeeperService/FinishSrpLogin": true,
+		// Refresh token is checked by handler, access token of the call is expired.
+		"/keeper.KeeperService/RefreshToken": true,
+	}
+	options := []grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(
 			interceptor.Context(),
 			interceptor.Logger(s.log),
 			interceptor.Errors(s.log),
 			interceptor.Panics(),
-			interceptor.Auth(
-				s.tokenService,
-				map[string]bool{
-					"/keeper.KeeperService/Login":    true,
-					"/keeper.KeeperService/Register": true,
-				},
-			),
+			interceptor.Auth(s.tokenService, skips),
+		),
+		grpc.ChainStreamInterceptor(
+			interceptor.ContextStream(),
+			interceptor.LoggerStream(s.log),
+			interceptor.ErrorsStream(s.log),
+			interceptor.PanicsStream(),
+			interceptor.AuthStream(s.tokenService, skips),
 		),
-	)
+	}
+	if s.tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
+	}
+	server := grpc.NewServer(options...)
 	pb.RegisterKeeperServiceServer(server, s)
 	s.server = server
 	return server.Serve(listen)
 }
 
-// Shutdown run graceful shutdown for GRPC server.
+// Shutdown run graceful shutdown for GRPC server, opened watch streams are finished.
 func (s *KeeperServer) Shutdown(ctx context.Context) error {
+	close(s.done)
 	wait := make(chan struct{})
 	go func() {
 		s.server.GracefulStop()
@@ -109,6 +158,14 @@ func (s *KeeperServer) Close() {
 	s.server.Stop()
 }
 
+func getTokenFromContext(ctx context.Context) string {
+	token, ok := ctx.Value("token").(string)
+	if !ok {
+		return ""
+	}
+	return token
+}
+
 func getUserIDFromContext(ctx context.Context) string {
 	userID, ok := ctx.Value("userID").(string)
 	if !ok {
@@ -116,3 +173,11 @@ func getUserIDFromContext(ctx context.Context) string {
 	}
 	return userID
 }
+
+func getUserLoginFromContext(ctx context.Context) string {
+	userLogin, ok := ctx.Value("userLogin").(string)
+	if !ok {
+		return ""
+	}
+	return userLogin
+}
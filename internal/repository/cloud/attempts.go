@@ -0,0 +1,170 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"keeper/internal/entity"
+)
+
+// attemptUpdateRetries is number of attempts to update failed attempts counter object changed concurrently.
+const attemptUpdateRetries = 10
+
+// AttemptRepository S3 failed login attempts storage, counters are shared by all server replicas.
+type AttemptRepository struct {
+	bucket string
+	client *s3.Client
+}
+
+// NewAttemptRepository construct AttemptRepository.
+func NewAttemptRepository(client *s3.Client, bucket string) *AttemptRepository {
+	return &AttemptRepository{
+		bucket: bucket,
+		client: client,
+	}
+}
+
+// GetAttempts return failed attempts counter by key, counter is empty when there were no failures.
+func (r *AttemptRepository) GetAttempts(ctx context.Context, key string) (entity.Attempts, error) {
+	attempts, _, err := r.getAttempts(ctx, key)
+	return attempts, err
+}
+
+// ReserveAttempt increment failed attempts counter when allowed accepts it and return counter,
+// counter older than window starts from scratch. Object is replaced only if it was not changed
+// since it was read (If-Match or If-None-Match for new counter), otherwise reserve is repeated.
+func (r *AttemptRepository) ReserveAttempt(ctx context.Context, key string, at time.Time, window time.Duration, allowed func(entity.Attempts) bool) (entity.Attempts, bool, error) {
+	var err error
+	for i := 0; i < attemptUpdateRetries; i++ {
+		attempts, etag, getErr := r.getAttempts(ctx, key)
+		if getErr != nil {
+			return entity.Attempts{}, false, getErr
+		}
+		if at.Sub(attempts.LastFailureAt) > window {
+			attempts = entity.Attempts{Key: key}
+		}
+		if !allowed(attempts) {
+			return attempts, false, nil
+		}
+		attempts.Failures++
+		attempts.LastFailureAt = at
+		err = r.putAttempts(ctx, attempts, etag)
+		if err == nil {
+			return attempts, true, nil
+		}
+		if !isPreconditionFailed(err) {
+			return entity.Attempts{}, false, err
+		}
+	}
+	return entity.Attempts{}, false, err
+}
+
+// ReleaseAttempt decrement failed attempts counter, counter is removed when it drops to zero.
+func (r *AttemptRepository) ReleaseAttempt(ctx context.Context, key string) error {
+	var err error
+	for i := 0; i < attemptUpdateRetries; i++ {
+		attempts, etag, getErr := r.getAttempts(ctx, key)
+		if getErr != nil {
+			return getErr
+		}
+		if etag == "" {
+			return nil
+		}
+		attempts.Failures--
+		if attempts.Failures <= 0 {
+			err = r.deleteAttempts(ctx, key, etag)
+		} else {
+			err = r.putAttempts(ctx, attempts, etag)
+		}
+		if !isPreconditionFailed(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// ResetAttempts remove failed attempts counter.
+func (r *AttemptRepository) ResetAttempts(ctx context.Context, key string) error {
+	return r.deleteAttempts(ctx, key, "")
+}
+
+// getAttempts return failed attempts counter with its ETag, ETag is empty when there is no counter object.
+func (r *AttemptRepository) getAttempts(ctx context.Context, key string) (entity.Attempts, string, error) {
+	attemptsFileName := getAttemptsFileName(key)
+	params := s3.GetObjectInput{
+		Bucket: &r.bucket,
+		Key:    &attemptsFileName,
+	}
+	out, err := r.client.GetObject(ctx, &params)
+	if err != nil {
+		return entity.Attempts{Key: key}, "", nil
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer out.Body.Close()
+	attemptsFileData, err := io.ReadAll(out.Body)
+	if err != nil {
+		return entity.Attempts{}, "", fmt.Errorf("read attempts file body: %w", err)
+	}
+	var attempts entity.Attempts
+	if err := json.Unmarshal(attemptsFileData, &attempts); err != nil {
+		return entity.Attempts{}, "", fmt.Errorf("unmarshal attempts data: %w", err)
+	}
+
+	var etag string
+	if out.ETag != nil {
+		etag = *out.ETag
+	}
+
+	return attempts, etag, nil
+}
+
+// putAttempts store failed attempts counter object, empty etag makes write allowed only for new object.
+func (r *AttemptRepository) putAttempts(ctx context.Context, attempts entity.Attempts, etag string) error {
+	attemptsFileName := getAttemptsFileName(attempts.Key)
+	attemptsFileData, err := json.Marshal(attempts)
+	if err != nil {
+		return fmt.Errorf("marshal attempts entity: %w", err)
+	}
+	params := s3.PutObjectInput{
+		Bucket: &r.bucket,
+		Key:    &attemptsFileName,
+		Body:   bytes.NewReader(attemptsFileData),
+	}
+	condition := withIfMatch(etag)
+	if etag == "" {
+		condition = withIfNoneMatch("*")
+	}
+	if _, err := r.client.PutObject(ctx, &params, condition); err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+
+	return nil
+}
+
+// deleteAttempts remove failed attempts counter object, non-empty etag makes removal conditional.
+func (r *AttemptRepository) deleteAttempts(ctx context.Context, key string, etag string) error {
+	attemptsFileName := getAttemptsFileName(key)
+	params := s3.DeleteObjectInput{
+		Bucket: &r.bucket,
+		Key:    &attemptsFileName,
+	}
+	if _, err := r.client.DeleteObject(ctx, &params, withIfMatch(etag)); err != nil {
+		return fmt.Errorf("delete object: %w", err)
+	}
+
+	return nil
+}
+
+// getAttemptsFileName return counter object name, key is hashed since it contains login and client IP.
+func getAttemptsFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("_attempts/%s.json", hex.EncodeToString(sum[:]))
+}
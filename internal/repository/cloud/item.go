@@ -4,16 +4,32 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 
 	"keeper/internal/entity"
 	"keeper/internal/repository"
 )
 
+const (
+	// changesClockSkew allow difference between S3 object modification time and item update time.
+	changesClockSkew = time.Minute
+)
+
+// deletedItem S3 tombstone object for deleted item.
+type deletedItem struct {
+	Name      string
+	DeletedAt time.Time
+}
+
 // ItemRepository S3 item storage.
 type ItemRepository struct {
 	bucket string
@@ -29,119 +45,529 @@ func NewItemRepository(client *s3.Client, bucket string) *ItemRepository {
 }
 
 // Create store item in storage.
+// Object is stored only if it doesn't exist yet (If-None-Match), so concurrent creates don't overwrite each other.
 func (r *ItemRepository) Create(ctx context.Context, item entity.Item) error {
 	if _, err := r.GetByUserIDAndName(ctx, item.UserID, item.Name); err == nil {
 		return repository.ErrItemAlreadyExist
 	}
 
 	itemFileName := getItemFileName(item.UserID, item.Name)
-	itemFileData, err := json.Marshal(item)
-	if err != nil {
-		return fmt.Errorf("marshal item entity: %w", err)
+	if err := r.createItem(ctx, itemFileName, item); err != nil {
+		return err
 	}
-	itemReader := bytes.NewReader(itemFileData)
-	params := s3.PutObjectInput{
+
+	deletedFileName := getDeletedItemFileName(item.UserID, item.Name)
+	params := s3.DeleteObjectInput{
 		Bucket: &r.bucket,
-		Key:    &itemFileName,
-		Body:   itemReader,
+		Key:    &deletedFileName,
 	}
-	_, err = r.client.PutObject(ctx, &params)
-	if err != nil {
-		return fmt.Errorf("put object: %w", err)
+	if _, err := r.client.DeleteObject(ctx, &params); err != nil {
+		return fmt.Errorf("delete item tombstone object: %w", err)
 	}
 
 	return nil
 }
 
-// Update store new version item in storage.
-func (r *ItemRepository) Update(ctx context.Context, item entity.Item) error {
-	if _, err := r.GetByUserIDAndName(ctx, item.UserID, item.Name); err != nil {
+// Update store new version item in storage, previous version is kept in item history.
+// Stored item revision should match provided revision, zero revision skips the check.
+// Stored object is replaced only if its ETag is still the same (If-Match), previous version is stored
+// only after that, so updates lost to concurrent ones don't leave versions behind.
+func (r *ItemRepository) Update(ctx context.Context, item entity.Item, revision int64) error {
+	itemFileName := getItemFileName(item.UserID, item.Name)
+	existedItem, etag, err := r.getItem(ctx, itemFileName)
+	if err != nil {
 		return repository.ErrItemNotFound
 	}
+	if revision != 0 && existedItem.Revision != revision {
+		return repository.ErrItemRevisionMismatch
+	}
 
+	item.Revision = existedItem.Revision + 1
+	if err := r.putItem(ctx, itemFileName, item, etag); err != nil {
+		return err
+	}
+
+	versionFileName := getItemVersionFileName(existedItem)
+	if err := r.putItem(ctx, versionFileName, existedItem, ""); err != nil {
+		return fmt.Errorf("store item version: %w", err)
+	}
+	return nil
+}
+
+// Replace store new version item in storage, current version and item history are removed.
+// Stored item revision should match provided revision, zero revision skips the check.
+// Stored object is replaced only if its ETag is still the same (If-Match).
+func (r *ItemRepository) Replace(ctx context.Context, item entity.Item, revision int64) error {
 	itemFileName := getItemFileName(item.UserID, item.Name)
-	itemFileData, err := json.Marshal(item)
+	existedItem, etag, err := r.getItem(ctx, itemFileName)
 	if err != nil {
-		return fmt.Errorf("marshal item entity: %w", err)
+		return repository.ErrItemNotFound
 	}
-	itemReader := bytes.NewReader(itemFileData)
-	params := s3.PutObjectInput{
-		Bucket: &r.bucket,
-		Key:    &itemFileName,
-		Body:   itemReader,
+	if revision != 0 && existedItem.Revision != revision {
+		return repository.ErrItemRevisionMismatch
+	}
+
+	item.Revision = existedItem.Revision + 1
+	if err := r.putItem(ctx, itemFileName, item, etag); err != nil {
+		return err
 	}
-	_, err = r.client.PutObject(ctx, &params)
+	return r.deleteVersions(ctx, item.UserID, item.Name)
+}
+
+// UpdateDataKey store new item data key and update time, previous item version is not kept as item data is the same.
+// Data keys of previous versions are replaced by not empty version keys, oldest first,
+// versions without data key are removed as they are encrypted directly with replaced vault key.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) UpdateDataKey(ctx context.Context, item entity.Item, versionKeys [][]byte, revision int64) error {
+	itemFileName := getItemFileName(item.UserID, item.Name)
+	existedItem, etag, err := r.getItem(ctx, itemFileName)
 	if err != nil {
-		return fmt.Errorf("put object: %w", err)
+		return repository.ErrItemNotFound
+	}
+	if revision != 0 && existedItem.Revision != revision {
+		return repository.ErrItemRevisionMismatch
 	}
 
+	existedItem.DataKey = item.DataKey
+	existedItem.UpdatedAt = item.UpdatedAt
+	existedItem.Revision++
+	if err := r.putItem(ctx, itemFileName, existedItem, etag); err != nil {
+		return err
+	}
+
+	// Versions are updated after item, so interrupted update is finished by repeating it with the new revision.
+	versionsFolderName := getItemVersionsFolderName(item.UserID, item.Name)
+	versionFileNames, err := r.listFileNames(ctx, versionsFolderName)
+	if err != nil {
+		return fmt.Errorf("get item versions list: %w", err)
+	}
+	for i, versionFileName := range versionFileNames {
+		version, versionEtag, err := r.getItem(ctx, versionFileName)
+		if err != nil {
+			return fmt.Errorf("get item version: %w", err)
+		}
+		if len(version.DataKey) == 0 {
+			if err := r.deleteObject(ctx, versionFileName); err != nil {
+				return fmt.Errorf("delete item version object: %w", err)
+			}
+			continue
+		}
+		if i >= len(versionKeys) || len(versionKeys[i]) == 0 {
+			continue
+		}
+		version.DataKey = versionKeys[i]
+		if err := r.putItem(ctx, versionFileName, version, versionEtag); err != nil {
+			return fmt.Errorf("store item version: %w", err)
+		}
+	}
 	return nil
 }
 
 // GetByUserIDAndName return item from storage by user ID and item name.
 func (r *ItemRepository) GetByUserIDAndName(ctx context.Context, userID string, name string) (entity.Item, error) {
 	itemFileName := getItemFileName(userID, name)
-	params := s3.GetObjectInput{
+	item, _, err := r.getItem(ctx, itemFileName)
+	return item, err
+}
+
+// Delete remove item from storage.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Delete(ctx context.Context, item entity.Item, revision int64) error {
+	itemFileName := getItemFileName(item.UserID, item.Name)
+	existedItem, etag, err := r.getItem(ctx, itemFileName)
+	if err != nil {
+		return repository.ErrItemNotFound
+	}
+	if revision != 0 && existedItem.Revision != revision {
+		return repository.ErrItemRevisionMismatch
+	}
+
+	params := s3.DeleteObjectInput{
 		Bucket: &r.bucket,
 		Key:    &itemFileName,
 	}
-	out, err := r.client.GetObject(ctx, &params)
+	_, err = r.client.DeleteObject(ctx, &params, withIfMatch(etag))
 	if err != nil {
-		return entity.Item{}, repository.ErrItemNotFound
+		if isPreconditionFailed(err) {
+			return repository.ErrItemRevisionMismatch
+		}
+		return fmt.Errorf("delete object: %w", err)
 	}
-	itemFileData, err := io.ReadAll(out.Body)
+
+	if err := r.deleteVersions(ctx, item.UserID, item.Name); err != nil {
+		return err
+	}
+
+	deletedFileName := getDeletedItemFileName(item.UserID, item.Name)
+	deletedFileData, err := json.Marshal(deletedItem{Name: item.Name, DeletedAt: time.Now()})
 	if err != nil {
-		return entity.Item{}, fmt.Errorf("read item file body: %w", err)
+		return fmt.Errorf("marshal item tombstone: %w", err)
+	}
+	putParams := s3.PutObjectInput{
+		Bucket: &r.bucket,
+		Key:    &deletedFileName,
+		Body:   bytes.NewReader(deletedFileData),
+	}
+	if _, err := r.client.PutObject(ctx, &putParams); err != nil {
+		return fmt.Errorf("put item tombstone object: %w", err)
 	}
-	//goland:noinspection GoUnhandledErrorResult
-	defer out.Body.Close()
 
-	var item entity.Item
-	err = json.Unmarshal(itemFileData, &item)
+	return nil
+}
+
+// DeleteByUser remove all user items with their history and tombstones from storage.
+func (r *ItemRepository) DeleteByUser(ctx context.Context, userID string) error {
+	prefixes := []string{
+		getUserFolderName(userID) + "/",
+		fmt.Sprintf("_versions/%s/", userID),
+		getDeletedFolderName(userID),
+	}
+	for _, prefix := range prefixes {
+		fileNames, err := r.listFileNames(ctx, prefix)
+		if err != nil {
+			return fmt.Errorf("get user objects list: %w", err)
+		}
+		for _, fileName := range fileNames {
+			fileName := fileName
+			params := s3.DeleteObjectInput{
+				Bucket: &r.bucket,
+				Key:    &fileName,
+			}
+			if _, err := r.client.DeleteObject(ctx, &params); err != nil {
+				return fmt.Errorf("delete object: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// FindByUser return item summaries list from storage for user ID.
+// Items are returned in name order starting after provided name, not positive limit returns all items.
+// Listing follows S3 continuation tokens, so users with more than 1000 items get full list.
+// S3 listing has no object content, so every listed item object is read for its summary.
+func (r *ItemRepository) FindByUser(ctx context.Context, userID string, limit int, after string) ([]entity.ItemSummary, error) {
+	userFolderName := getUserFolderName(userID) + "/"
+	params := s3.ListObjectsV2Input{
+		Bucket: &r.bucket,
+		Prefix: &userFolderName,
+	}
+	if after != "" {
+		startAfter := getItemFileName(userID, after)
+		params.StartAfter = &startAfter
+	}
+
+	re, _ := regexp.Compile(`^_items/[a-z0-9]{8}-[a-z0-9]{4}-[a-z0-9]{4}-[a-z0-9]{4}-[a-z0-9]{12}/.*\.json$`)
+	var fileNames []string
+	paginator := s3.NewListObjectsV2Paginator(r.client, &params)
+	for paginator.HasMorePages() && (limit <= 0 || len(fileNames) < limit) {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return []entity.ItemSummary{}, fmt.Errorf("get items list: %w", err)
+		}
+		for _, obj := range out.Contents {
+			if re.MatchString(*obj.Key) {
+				fileNames = append(fileNames, *obj.Key)
+			}
+		}
+	}
+	if limit > 0 && len(fileNames) > limit {
+		fileNames = fileNames[:limit]
+	}
+
+	list := make([]entity.ItemSummary, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		item, _, err := r.getItem(ctx, fileName)
+		if err != nil {
+			return []entity.ItemSummary{}, fmt.Errorf("get item: %w", err)
+		}
+		list = append(list, itemSummary(item))
+	}
+
+	return list, nil
+}
+
+// FindByFilter return summaries of user items matching filter, ordered by name.
+// Only keys with glob pattern literal prefix are listed and only objects with matching names are read.
+func (r *ItemRepository) FindByFilter(ctx context.Context, userID string, filter entity.ItemFilter) ([]entity.ItemSummary, error) {
+	matcher, err := repository.NewItemMatcher(filter)
+	if err != nil {
+		return []entity.ItemSummary{}, err
+	}
+
+	userFolderName := getUserFolderName(userID) + "/"
+	prefix := userFolderName + matcher.NamePrefix()
+	params := s3.ListObjectsV2Input{
+		Bucket: &r.bucket,
+		Prefix: &prefix,
+	}
+
+	list := []entity.ItemSummary{}
+	paginator := s3.NewListObjectsV2Paginator(r.client, &params)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return []entity.ItemSummary{}, fmt.Errorf("get items list: %w", err)
+		}
+		for _, obj := range out.Contents {
+			name := strings.TrimSuffix(strings.TrimPrefix(*obj.Key, userFolderName), ".json")
+			if !matcher.MatchName(name) {
+				continue
+			}
+			item, _, err := r.getItem(ctx, *obj.Key)
+			if err != nil {
+				return []entity.ItemSummary{}, fmt.Errorf("get item: %w", err)
+			}
+			summary := itemSummary(item)
+			if matcher.Match(summary) {
+				list = append(list, summary)
+			}
+		}
+	}
+
+	return list, nil
+}
+
+// FindVersions return previous versions of item from storage, oldest first.
+func (r *ItemRepository) FindVersions(ctx context.Context, userID string, name string) ([]entity.Item, error) {
+	if _, err := r.GetByUserIDAndName(ctx, userID, name); err != nil {
+		return nil, err
+	}
+
+	versionsFolderName := getItemVersionsFolderName(userID, name)
+	versionFileNames, err := r.listFileNames(ctx, versionsFolderName)
 	if err != nil {
-		return entity.Item{}, fmt.Errorf("unmarshal item data: %w", err)
+		return nil, fmt.Errorf("get item versions list: %w", err)
 	}
 
-	return item, nil
+	versions := make([]entity.Item, 0, len(versionFileNames))
+	for _, versionFileName := range versionFileNames {
+		item, _, err := r.getItem(ctx, versionFileName)
+		if err != nil {
+			return nil, fmt.Errorf("get item version: %w", err)
+		}
+		versions = append(versions, item)
+	}
+
+	return versions, nil
 }
 
-// Delete remove item from storage.
-func (r *ItemRepository) Delete(ctx context.Context, item entity.Item) error {
-	itemFileName := getItemFileName(item.UserID, item.Name)
+// FindChanges return items updated and names of items deleted after since time.
+// Objects modified long before since time are skipped without reading them.
+func (r *ItemRepository) FindChanges(ctx context.Context, userID string, since time.Time) ([]entity.Item, []string, error) {
+	modifiedSince := since.Add(-changesClockSkew)
+
+	userFolderName := getUserFolderName(userID) + "/"
+	params := s3.ListObjectsV2Input{
+		Bucket: &r.bucket,
+		Prefix: &userFolderName,
+	}
+	var items []entity.Item
+	paginator := s3.NewListObjectsV2Paginator(r.client, &params)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get items list: %w", err)
+		}
+		for _, obj := range out.Contents {
+			if obj.LastModified != nil && obj.LastModified.Before(modifiedSince) {
+				continue
+			}
+			item, _, err := r.getItem(ctx, *obj.Key)
+			if err != nil {
+				return nil, nil, fmt.Errorf("get changed item: %w", err)
+			}
+			if item.UpdatedAt.After(since) {
+				items = append(items, item)
+			}
+		}
+	}
+
+	deletedFolderName := getDeletedFolderName(userID)
+	params = s3.ListObjectsV2Input{
+		Bucket: &r.bucket,
+		Prefix: &deletedFolderName,
+	}
+	var deleted []string
+	paginator = s3.NewListObjectsV2Paginator(r.client, &params)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get deleted items list: %w", err)
+		}
+		for _, obj := range out.Contents {
+			if obj.LastModified != nil && obj.LastModified.Before(modifiedSince) {
+				continue
+			}
+			getParams := s3.GetObjectInput{
+				Bucket: &r.bucket,
+				Key:    obj.Key,
+			}
+			getOut, err := r.client.GetObject(ctx, &getParams)
+			if err != nil {
+				return nil, nil, fmt.Errorf("get item tombstone object: %w", err)
+			}
+			var d deletedItem
+			err = json.NewDecoder(getOut.Body).Decode(&d)
+			//goland:noinspection GoUnhandledErrorResult
+			getOut.Body.Close()
+			if err != nil {
+				return nil, nil, fmt.Errorf("unmarshal item tombstone: %w", err)
+			}
+			if d.DeletedAt.After(since) {
+				deleted = append(deleted, d.Name)
+			}
+		}
+	}
+
+	return items, deleted, nil
+}
+
+// deleteVersions remove all previous versions of item.
+func (r *ItemRepository) deleteVersions(ctx context.Context, userID string, name string) error {
+	versionsFolderName := getItemVersionsFolderName(userID, name)
+	versionFileNames, err := r.listFileNames(ctx, versionsFolderName)
+	if err != nil {
+		return fmt.Errorf("get item versions list: %w", err)
+	}
+	for _, versionFileName := range versionFileNames {
+		if err := r.deleteObject(ctx, versionFileName); err != nil {
+			return fmt.Errorf("delete item version object: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteObject remove object from bucket.
+func (r *ItemRepository) deleteObject(ctx context.Context, fileName string) error {
 	params := s3.DeleteObjectInput{
 		Bucket: &r.bucket,
-		Key:    &itemFileName,
+		Key:    &fileName,
 	}
 	_, err := r.client.DeleteObject(ctx, &params)
+	return err
+}
+
+// putItem store item object, non-empty etag makes write conditional.
+func (r *ItemRepository) putItem(ctx context.Context, fileName string, item entity.Item, etag string) error {
+	err := r.writeItem(ctx, fileName, item, withIfMatch(etag))
+	if isPreconditionFailed(err) {
+		return repository.ErrItemRevisionMismatch
+	}
+	return err
+}
+
+// createItem store item object only if it doesn't exist.
+func (r *ItemRepository) createItem(ctx context.Context, fileName string, item entity.Item) error {
+	err := r.writeItem(ctx, fileName, item, withIfNoneMatch("*"))
+	if isPreconditionFailed(err) {
+		return repository.ErrItemAlreadyExist
+	}
+	return err
+}
+
+func (r *ItemRepository) writeItem(ctx context.Context, fileName string, item entity.Item, condition func(*s3.Options)) error {
+	itemFileData, err := json.Marshal(item)
 	if err != nil {
-		return fmt.Errorf("delete object: %w", err)
+		return fmt.Errorf("marshal item entity: %w", err)
+	}
+	itemReader := bytes.NewReader(itemFileData)
+	params := s3.PutObjectInput{
+		Bucket: &r.bucket,
+		Key:    &fileName,
+		Body:   itemReader,
+	}
+	if _, err := r.client.PutObject(ctx, &params, condition); err != nil {
+		return fmt.Errorf("put object: %w", err)
 	}
 
 	return nil
 }
 
-// FindByUser return item names list from storage for user ID.
-func (r *ItemRepository) FindByUser(ctx context.Context, userID string) ([]string, error) {
-	userFolderName := getUserFolderName(userID)
-	params := s3.ListObjectsV2Input{
+// getItem return item object with its ETag.
+func (r *ItemRepository) getItem(ctx context.Context, fileName string) (entity.Item, string, error) {
+	params := s3.GetObjectInput{
 		Bucket: &r.bucket,
-		Prefix: &userFolderName,
+		Key:    &fileName,
+	}
+	out, err := r.client.GetObject(ctx, &params)
+	if err != nil {
+		return entity.Item{}, "", repository.ErrItemNotFound
 	}
-	out, err := r.client.ListObjectsV2(ctx, &params)
+	//goland:noinspection GoUnhandledErrorResult
+	defer out.Body.Close()
+	itemFileData, err := io.ReadAll(out.Body)
 	if err != nil {
-		return []string{}, fmt.Errorf("get items list: %w", err)
+		return entity.Item{}, "", fmt.Errorf("read item file body: %w", err)
+	}
+
+	var item entity.Item
+	err = json.Unmarshal(itemFileData, &item)
+	if err != nil {
+		return entity.Item{}, "", fmt.Errorf("unmarshal item data: %w", err)
+	}
+
+	var etag string
+	if out.ETag != nil {
+		etag = *out.ETag
+	}
+
+	return item, etag, nil
+}
+
+// listFileNames return all object keys with prefix, S3 returns them in ascending order.
+func (r *ItemRepository) listFileNames(ctx context.Context, prefix string) ([]string, error) {
+	params := s3.ListObjectsV2Input{
+		Bucket: &r.bucket,
+		Prefix: &prefix,
 	}
+	var fileNames []string
+	paginator := s3.NewListObjectsV2Paginator(r.client, &params)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range out.Contents {
+			fileNames = append(fileNames, *obj.Key)
+		}
+	}
+
+	return fileNames, nil
+}
 
-	re, _ := regexp.Compile(`^_items/[a-z0-9]{8}-[a-z0-9]{4}-[a-z0-9]{4}-[a-z0-9]{4}-[a-z0-9]{12}/(.*)\.json$`)
-	var list []string
-	for _, obj := range out.Contents {
-		sm := re.FindStringSubmatch(*obj.Key)
-		if len(sm) == 2 {
-			list = append(list, sm[1])
+// withIfMatch add If-Match header to S3 request, empty etag leaves request unconditional.
+func withIfMatch(etag string) func(*s3.Options) {
+	return func(o *s3.Options) {
+		if etag != "" {
+			o.APIOptions = append(o.APIOptions, smithyhttp.SetHeaderValue("If-Match", etag))
 		}
 	}
+}
 
-	return list, nil
+// withIfNoneMatch add If-None-Match header to S3 request, "*" makes write fail when object exists.
+func withIfNoneMatch(etag string) func(*s3.Options) {
+	return func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, smithyhttp.SetHeaderValue("If-None-Match", etag))
+	}
+}
+
+func isPreconditionFailed(err error) bool {
+	var re *smithyhttp.ResponseError
+	return errors.As(err, &re) && re.HTTPStatusCode() == http.StatusPreconditionFailed
+}
+
+func itemSummary(item entity.Item) entity.ItemSummary {
+	return entity.ItemSummary{
+		Name:       item.Name,
+		Type:       item.Type,
+		DataKey:    item.DataKey,
+		Attributes: item.Attributes,
+		Metadata:   item.Metadata,
+		Revision:   item.Revision,
+		CreatedAt:  item.CreatedAt,
+		UpdatedAt:  item.UpdatedAt,
+	}
 }
 
 func getItemFileName(userID string, name string) string {
@@ -151,3 +577,19 @@ func getItemFileName(userID string, name string) string {
 func getUserFolderName(userID string) string {
 	return fmt.Sprintf("_items/%s", userID)
 }
+
+func getItemVersionsFolderName(userID string, name string) string {
+	return fmt.Sprintf("_versions/%s/%s/", userID, name)
+}
+
+func getItemVersionFileName(item entity.Item) string {
+	return fmt.Sprintf("%s%020d.json", getItemVersionsFolderName(item.UserID, item.Name), item.UpdatedAt.UnixNano())
+}
+
+func getDeletedFolderName(userID string) string {
+	return fmt.Sprintf("_deleted/%s/", userID)
+}
+
+func getDeletedItemFileName(userID string, name string) string {
+	return fmt.Sprintf("%s%s.json", getDeletedFolderName(userID), name)
+}
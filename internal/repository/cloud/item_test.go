@@ -0,0 +1,112 @@
+package cloud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"keeper/internal/entity"
+)
+
+// newTestItemRepository return item repository stored in fake S3 server.
+func newTestItemRepository(t *testing.T, pageSize int) (*ItemRepository, *fakeS3) {
+	t.Helper()
+	client, s3 := newTestS3(t, pageSize)
+	return NewItemRepository(client, testBucket), s3
+}
+
+func TestItemRepositoryFindByUserPages(t *testing.T) {
+	ctx := context.Background()
+	r, s3 := newTestItemRepository(t, 2)
+	userID := "00000000-0000-0000-0000-000000000001"
+	names := []string{"a", "b", "c", "d", "e"}
+	for _, name := range names {
+		item := entity.Item{UserID: userID, Name: name, Type: "text", Revision: 1}
+		if err := r.Create(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := entity.Item{UserID: "00000000-0000-0000-0000-000000000002", Name: "other", Revision: 1}
+	if err := r.Create(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		after string
+		want  []string
+	}{
+		{name: "all items over truncated pages", limit: 0, want: names},
+		{name: "limit", limit: 3, want: []string{"a", "b", "c"}},
+		{name: "after", limit: 0, after: "b", want: []string{"c", "d", "e"}},
+		{name: "limit after", limit: 2, after: "c", want: []string{"d", "e"}},
+		{name: "after last", limit: 0, after: "e", want: []string{}},
+	}
+	for _, tt := range tests {
+		s3.lists = 0
+		list, err := r.FindByUser(ctx, userID, tt.limit, tt.after)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := make([]string, 0, len(list))
+		for _, summary := range list {
+			got = append(got, summary.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: FindByUser() = %v, want %v", tt.name, got, tt.want)
+		}
+		// Limited listing stops once enough items are listed.
+		if tt.limit > 0 && s3.lists > (tt.limit+s3.pageSize-1)/s3.pageSize {
+			t.Errorf("%s: %d list requests for %d items", tt.name, s3.lists, tt.limit)
+		}
+	}
+}
+
+func TestItemRepositoryFindByFilter(t *testing.T) {
+	ctx := context.Background()
+	r, s3 := newTestItemRepository(t, 2)
+	userID := "00000000-0000-0000-0000-000000000001"
+	items := []entity.Item{
+		{UserID: userID, Name: "home/bank", Type: "card", Metadata: []entity.Metadata{{Key: "bank", Value: "city"}}},
+		{UserID: userID, Name: "home/note", Type: "text"},
+		{UserID: userID, Name: "work/acme", Type: "card", Metadata: []entity.Metadata{{Key: "bank", Value: "acme"}}},
+		{UserID: userID, Name: "work/mail", Type: "password"},
+		{UserID: userID, Name: "work/pay", Type: "card"},
+	}
+	for _, item := range items {
+		if err := r.Create(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		filter    entity.ItemFilter
+		want      []string
+		wantReads int
+	}{
+		{name: "type", filter: entity.ItemFilter{Type: "card"}, want: []string{"home/bank", "work/acme", "work/pay"}, wantReads: 5},
+		{name: "metadata", filter: entity.ItemFilter{Metadata: []entity.Metadata{{Key: "bank"}}}, want: []string{"home/bank", "work/acme"}, wantReads: 5},
+		// Only objects under glob literal prefix are listed, only ones with matching names are read.
+		{name: "glob", filter: entity.ItemFilter{Name: "work/*a*", Type: "card"}, want: []string{"work/acme", "work/pay"}, wantReads: 3},
+		{name: "substring", filter: entity.ItemFilter{Name: "note"}, want: []string{"home/note"}, wantReads: 1},
+	}
+	for _, tt := range tests {
+		s3.reads = 0
+		list, err := r.FindByFilter(ctx, userID, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := make([]string, 0, len(list))
+		for _, summary := range list {
+			got = append(got, summary.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: FindByFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+		if s3.reads != tt.wantReads {
+			t.Errorf("%s: %d objects read, want %d", tt.name, s3.reads, tt.wantReads)
+		}
+	}
+}
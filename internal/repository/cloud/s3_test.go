@@ -0,0 +1,120 @@
+package cloud
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"keeper/pkg/cloud"
+)
+
+const testBucket = "keeper"
+
+// fakeS3 is minimal path style S3 server keeping objects in memory.
+// Listing returns at most pageSize keys per page, like S3 does with 1000 keys.
+type fakeS3 struct {
+	pageSize int
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	lists   int
+	reads   int
+}
+
+type fakeS3Object struct {
+	Key string `xml:"Key"`
+}
+
+type fakeS3ListResult struct {
+	XMLName               xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	KeyCount              int            `xml:"KeyCount"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	Contents              []fakeS3Object `xml:"Contents"`
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+testBucket), "/")
+	data, exists := s.objects[key]
+	etag := fmt.Sprintf(`"%x"`, len(data))
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		s.list(w, r)
+	case r.Method == http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		s.reads++
+		w.Header().Set("ETag", etag)
+		w.Write(data)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("If-None-Match") == "*" && exists ||
+			r.Header.Get("If-Match") != "" && (!exists || r.Header.Get("If-Match") != etag) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code></Error>`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.objects[key] = body
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (s *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	s.lists++
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	after := query.Get("start-after")
+	if token := query.Get("continuation-token"); token != "" {
+		after = token
+	}
+
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	result := fakeS3ListResult{Name: testBucket, Prefix: prefix}
+	if len(keys) > s.pageSize {
+		keys = keys[:s.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, fakeS3Object{Key: key})
+	}
+	result.KeyCount = len(keys)
+	w.Header().Set("Content-Type", "application/xml")
+	//goland:noinspection GoUnhandledErrorResult
+	xml.NewEncoder(w).Encode(result)
+}
+
+// newTestS3 start fake S3 server stopped after test and return client connected to it.
+func newTestS3(t *testing.T, pageSize int) (*s3.Client, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{pageSize: pageSize, objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return cloud.NewS3Client("key", "secret", "us-east-1", server.URL), fake
+}
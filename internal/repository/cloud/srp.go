@@ -0,0 +1,151 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+const (
+	// srpExchangesSweepInterval is how often abandoned exchange objects are removed.
+	srpExchangesSweepInterval = time.Minute
+	// srpExchangeMaxAge is age of exchange objects considered abandoned, exchanges expire much earlier.
+	srpExchangeMaxAge = time.Hour
+)
+
+// SRPExchangeRepository S3 started SRP-6a login exchanges storage.
+type SRPExchangeRepository struct {
+	bucket string
+	client *s3.Client
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewSRPExchangeRepository construct SRPExchangeRepository.
+func NewSRPExchangeRepository(client *s3.Client, bucket string) *SRPExchangeRepository {
+	return &SRPExchangeRepository{
+		bucket: bucket,
+		client: client,
+	}
+}
+
+// CreateExchange store started exchange, abandoned exchanges are removed from time to time.
+func (r *SRPExchangeRepository) CreateExchange(ctx context.Context, exchange entity.SRPExchange) error {
+	if err := r.sweep(ctx); err != nil {
+		return err
+	}
+
+	exchangeFileName := getSRPExchangeFileName(exchange.ID)
+	exchangeFileData, err := json.Marshal(exchange)
+	if err != nil {
+		return fmt.Errorf("marshal srp exchange entity: %w", err)
+	}
+	params := s3.PutObjectInput{
+		Bucket: &r.bucket,
+		Key:    &exchangeFileName,
+		Body:   bytes.NewReader(exchangeFileData),
+	}
+	if _, err := r.client.PutObject(ctx, &params); err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+
+	return nil
+}
+
+// TakeExchange remove exchange and return it. Object is removed only if it is still the same (If-Match),
+// so concurrent calls don't take one exchange twice.
+func (r *SRPExchangeRepository) TakeExchange(ctx context.Context, id string) (entity.SRPExchange, error) {
+	exchangeFileName := getSRPExchangeFileName(id)
+	params := s3.GetObjectInput{
+		Bucket: &r.bucket,
+		Key:    &exchangeFileName,
+	}
+	out, err := r.client.GetObject(ctx, &params)
+	if err != nil {
+		return entity.SRPExchange{}, repository.ErrSRPExchangeNotFound
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer out.Body.Close()
+	exchangeFileData, err := io.ReadAll(out.Body)
+	if err != nil {
+		return entity.SRPExchange{}, fmt.Errorf("read srp exchange file body: %w", err)
+	}
+	var exchange entity.SRPExchange
+	if err := json.Unmarshal(exchangeFileData, &exchange); err != nil {
+		return entity.SRPExchange{}, fmt.Errorf("unmarshal srp exchange data: %w", err)
+	}
+
+	var etag string
+	if out.ETag != nil {
+		etag = *out.ETag
+	}
+	deleteParams := s3.DeleteObjectInput{
+		Bucket: &r.bucket,
+		Key:    &exchangeFileName,
+	}
+	if _, err := r.client.DeleteObject(ctx, &deleteParams, withIfMatch(etag)); err != nil {
+		if isPreconditionFailed(err) {
+			return entity.SRPExchange{}, repository.ErrSRPExchangeNotFound
+		}
+		return entity.SRPExchange{}, fmt.Errorf("delete object: %w", err)
+	}
+
+	return exchange, nil
+}
+
+// sweep remove abandoned exchange objects at most once per sweep interval.
+func (r *SRPExchangeRepository) sweep(ctx context.Context) error {
+	r.mu.Lock()
+	now := time.Now()
+	if now.Sub(r.lastSweep) < srpExchangesSweepInterval {
+		r.mu.Unlock()
+		return nil
+	}
+	r.lastSweep = now
+	r.mu.Unlock()
+
+	prefix := getSRPExchangesFolderName()
+	params := s3.ListObjectsV2Input{
+		Bucket: &r.bucket,
+		Prefix: &prefix,
+	}
+	paginator := s3.NewListObjectsV2Paginator(r.client, &params)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("get srp exchanges list: %w", err)
+		}
+		for _, obj := range out.Contents {
+			if obj.LastModified == nil || now.Sub(*obj.LastModified) < srpExchangeMaxAge {
+				continue
+			}
+			deleteParams := s3.DeleteObjectInput{
+				Bucket: &r.bucket,
+				Key:    obj.Key,
+			}
+			if _, err := r.client.DeleteObject(ctx, &deleteParams); err != nil {
+				return fmt.Errorf("delete srp exchange object: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func getSRPExchangesFolderName() string {
+	return "_srp_exchanges/"
+}
+
+func getSRPExchangeFileName(id string) string {
+	return fmt.Sprintf("%s%s.json", getSRPExchangesFolderName(), id)
+}
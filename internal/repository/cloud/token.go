@@ -3,12 +3,10 @@ package cloud
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 	"time"
 
@@ -34,42 +32,124 @@ func NewTokenRepository(client *s3.Client, bucket string, lifetime time.Duration
 	}
 }
 
-// CreateToken creates new random user token and store it.
-func (r *TokenRepository) CreateToken(ctx context.Context, user entity.User) (string, error) {
-	var sb strings.Builder
-	sb.WriteString(user.ID)
-	sb.WriteString(user.Login)
-	sb.WriteString(strconv.Itoa(int(time.Now().UnixNano())))
-	hash := md5.Sum([]byte(sb.String()))
-	token := hex.EncodeToString(hash[:])
+// CreateToken store user token.
+func (r *TokenRepository) CreateToken(ctx context.Context, token entity.Token) error {
+	if err := r.putToken(ctx, token, ""); err != nil {
+		return err
+	}
 
-	tokenEntity := entity.Token{
-		ID:        token,
-		UserLogin: user.Login,
-		CreatedAt: time.Now(),
+	// Empty object in user folder allows finding user tokens without reading all tokens.
+	userTokenFileName := getUserTokenFileName(token.UserLogin, token.ID)
+	params := s3.PutObjectInput{
+		Bucket: &r.bucket,
+		Key:    &userTokenFileName,
+		Body:   bytes.NewReader(nil),
+	}
+	if _, err := r.client.PutObject(ctx, &params); err != nil {
+		return fmt.Errorf("put user token object: %w", err)
 	}
 
-	tokenFileName := getTokenFileName(token)
-	tokenData, err := json.Marshal(tokenEntity)
+	return nil
+}
+
+// UpdateTokenUsage store time and client IP of user token last usage.
+// Object is replaced only if it was not changed since it was read (If-Match), usage of concurrently
+// changed token is not stored, it is stored again by the next call.
+func (r *TokenRepository) UpdateTokenUsage(ctx context.Context, id string, ip string, usedAt time.Time) error {
+	token, etag, err := r.getToken(ctx, id)
 	if err != nil {
-		return "", fmt.Errorf("marshal token entity: %w", err)
+		return err
 	}
-	tokenReader := bytes.NewReader(tokenData)
-	params := s3.PutObjectInput{
-		Bucket: &r.bucket,
-		Key:    &tokenFileName,
-		Body:   tokenReader,
+	token.Device.IP = ip
+	token.LastUsedAt = usedAt
+	if err := r.putToken(ctx, token, etag); err != nil && !isPreconditionFailed(err) {
+		return err
 	}
-	_, err = r.client.PutObject(ctx, &params)
+	return nil
+}
+
+// RotateToken replace access and refresh token hashes and access expiration time of user token
+// if refresh token hash is still refreshHash, replaced hash is kept for reuse detection.
+// Object is replaced only if it was not changed since it was read (If-Match).
+func (r *TokenRepository) RotateToken(ctx context.Context, id string, refreshHash string, accessHash string, newRefreshHash string, expiresAt time.Time) error {
+	token, etag, err := r.getToken(ctx, id)
 	if err != nil {
-		return "", fmt.Errorf("put object: %w", err)
+		return err
 	}
+	if token.RefreshHash != refreshHash {
+		return repository.ErrTokenRotated
+	}
+	token.AccessHash = accessHash
+	token.RefreshHash = newRefreshHash
+	token.PreviousRefreshHash = refreshHash
+	token.AccessExpiresAt = expiresAt
+	if err := r.putToken(ctx, token, etag); err != nil {
+		if isPreconditionFailed(err) {
+			return repository.ErrTokenRotated
+		}
+		return err
+	}
+	return nil
+}
+
+// FindTokensByUser return not expired user tokens.
+func (r *TokenRepository) FindTokensByUser(ctx context.Context, login string) ([]entity.Token, error) {
+	ids, err := r.getUserTokenIDs(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]entity.Token, 0, len(ids))
+	for _, id := range ids {
+		token, err := r.GetToken(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrTokenNotFound) || errors.Is(err, repository.ErrTokenExpired) {
+				continue
+			}
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
 
-	return token, nil
+// DeleteToken remove user token, removing not existed token is not an error.
+func (r *TokenRepository) DeleteToken(ctx context.Context, id string) error {
+	token, err := r.GetToken(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) || errors.Is(err, repository.ErrTokenExpired) {
+			return nil
+		}
+		return err
+	}
+	return r.deleteToken(ctx, token)
+}
+
+// DeleteTokensByUser remove all tokens of user.
+func (r *TokenRepository) DeleteTokensByUser(ctx context.Context, login string) error {
+	ids, err := r.getUserTokenIDs(ctx, login)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		token := entity.Token{
+			ID:        id,
+			UserLogin: login,
+		}
+		if err := r.deleteToken(ctx, token); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // GetToken return user token by token ID.
 func (r *TokenRepository) GetToken(ctx context.Context, id string) (entity.Token, error) {
+	token, _, err := r.getToken(ctx, id)
+	return token, err
+}
+
+// getToken return user token object with its ETag, expired token is removed.
+func (r *TokenRepository) getToken(ctx context.Context, id string) (entity.Token, string, error) {
 	tokenFileName := getTokenFileName(id)
 	params := s3.GetObjectInput{
 		Bucket: &r.bucket,
@@ -77,40 +157,84 @@ func (r *TokenRepository) GetToken(ctx context.Context, id string) (entity.Token
 	}
 	out, err := r.client.GetObject(ctx, &params)
 	if err != nil {
-		return entity.Token{}, repository.ErrTokenNotFound
+		return entity.Token{}, "", repository.ErrTokenNotFound
 	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer out.Body.Close()
 	tokenFileData, err := io.ReadAll(out.Body)
 	if err != nil {
-		return entity.Token{}, fmt.Errorf("read token file body: %w", err)
+		return entity.Token{}, "", fmt.Errorf("read token file body: %w", err)
 	}
-	//goland:noinspection GoUnhandledErrorResult
-	defer out.Body.Close()
 
 	var token entity.Token
 	err = json.Unmarshal(tokenFileData, &token)
 	if err != nil {
-		return entity.Token{}, fmt.Errorf("unmarshal token data: %w", err)
+		return entity.Token{}, "", fmt.Errorf("unmarshal token data: %w", err)
 	}
 
 	if time.Since(token.CreatedAt) > r.lifetime {
-		if err := r.deleteToken(ctx, token.ID); err != nil {
-			return entity.Token{}, err
+		if err := r.deleteToken(ctx, token); err != nil {
+			return entity.Token{}, "", err
 		}
-		return entity.Token{}, repository.ErrTokenExpired
+		return entity.Token{}, "", repository.ErrTokenExpired
 	}
 
-	return token, nil
+	var etag string
+	if out.ETag != nil {
+		etag = *out.ETag
+	}
+
+	return token, etag, nil
 }
 
-func (r *TokenRepository) deleteToken(ctx context.Context, id string) error {
-	tokenFileName := getTokenFileName(id)
-	params := s3.DeleteObjectInput{
+// putToken store token object, non-empty etag makes write conditional.
+func (r *TokenRepository) putToken(ctx context.Context, token entity.Token, etag string) error {
+	tokenFileName := getTokenFileName(token.ID)
+	tokenData, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("marshal token entity: %w", err)
+	}
+	params := s3.PutObjectInput{
 		Bucket: &r.bucket,
 		Key:    &tokenFileName,
+		Body:   bytes.NewReader(tokenData),
 	}
-	_, err := r.client.DeleteObject(ctx, &params)
-	if err != nil {
-		return fmt.Errorf("delete object: %w", err)
+	if _, err := r.client.PutObject(ctx, &params, withIfMatch(etag)); err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+	return nil
+}
+
+// getUserTokenIDs return IDs of user tokens from user tokens folder, expired tokens are included.
+func (r *TokenRepository) getUserTokenIDs(ctx context.Context, login string) ([]string, error) {
+	userTokensFolderName := getUserTokensFolderName(login) + "/"
+	params := s3.ListObjectsV2Input{
+		Bucket: &r.bucket,
+		Prefix: &userTokensFolderName,
+	}
+	var ids []string
+	paginator := s3.NewListObjectsV2Paginator(r.client, &params)
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get user tokens list: %w", err)
+		}
+		for _, obj := range out.Contents {
+			ids = append(ids, strings.TrimPrefix(*obj.Key, userTokensFolderName))
+		}
+	}
+	return ids, nil
+}
+
+func (r *TokenRepository) deleteToken(ctx context.Context, token entity.Token) error {
+	for _, fileName := range []string{getTokenFileName(token.ID), getUserTokenFileName(token.UserLogin, token.ID)} {
+		params := s3.DeleteObjectInput{
+			Bucket: &r.bucket,
+			Key:    &fileName,
+		}
+		if _, err := r.client.DeleteObject(ctx, &params); err != nil {
+			return fmt.Errorf("delete object: %w", err)
+		}
 	}
 
 	return nil
@@ -119,3 +243,11 @@ func (r *TokenRepository) deleteToken(ctx context.Context, id string) error {
 func getTokenFileName(id string) string {
 	return fmt.Sprintf("_tokens/%s.json", id)
 }
+
+func getUserTokensFolderName(login string) string {
+	return fmt.Sprintf("_user_tokens/%s", login)
+}
+
+func getUserTokenFileName(login string, id string) string {
+	return fmt.Sprintf("%s/%s", getUserTokensFolderName(login), id)
+}
@@ -0,0 +1,73 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+func TestTokenRepositoryUserIndex(t *testing.T) {
+	ctx := context.Background()
+	client, s3 := newTestS3(t, 2)
+	r := NewTokenRepository(client, testBucket, time.Hour)
+	now := time.Now()
+	// Login "user" is prefix of "user2", user index shouldn't mix their tokens.
+	tokens := []entity.Token{
+		{ID: "laptop", UserLogin: "user", CreatedAt: now},
+		{ID: "phone", UserLogin: "user", CreatedAt: now},
+		{ID: "tablet", UserLogin: "user", CreatedAt: now},
+		{ID: "other", UserLogin: "user2", CreatedAt: now},
+	}
+	for _, token := range tokens {
+		if err := r.CreateToken(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	findIDs := func(login string) string {
+		t.Helper()
+		found, err := r.FindTokensByUser(ctx, login)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, 0, len(found))
+		for _, token := range found {
+			ids = append(ids, token.ID)
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+
+	if got := findIDs("user"); got != "laptop,phone,tablet" {
+		t.Errorf("FindTokensByUser() = %s, want laptop,phone,tablet", got)
+	}
+	if err := r.DeleteToken(ctx, "phone"); err != nil {
+		t.Fatal(err)
+	}
+	if got := findIDs("user"); got != "laptop,tablet" {
+		t.Errorf("FindTokensByUser() after delete = %s, want laptop,tablet", got)
+	}
+
+	if err := r.DeleteTokensByUser(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if got := findIDs("user"); got != "" {
+		t.Errorf("FindTokensByUser() after user tokens delete = %s, want none", got)
+	}
+	if _, err := r.GetToken(ctx, "laptop"); !errors.Is(err, repository.ErrTokenNotFound) {
+		t.Errorf("GetToken() of deleted token error = %v, want %v", err, repository.ErrTokenNotFound)
+	}
+	if got := findIDs("user2"); got != "other" {
+		t.Errorf("FindTokensByUser() of other user = %s, want other", got)
+	}
+	for key := range s3.objects {
+		if strings.Contains(key, "laptop") || strings.Contains(key, "tablet") {
+			t.Errorf("object %s of deleted token is kept", key)
+		}
+	}
+}
@@ -13,6 +13,9 @@ import (
 	"keeper/internal/repository"
 )
 
+// userUpdateRetries is number of attempts to update user object changed concurrently.
+const userUpdateRetries = 5
+
 // UserRepository S3 user storage.
 type UserRepository struct {
 	bucket string
@@ -33,27 +36,76 @@ func (r *UserRepository) Create(ctx context.Context, user entity.User) error {
 		return repository.ErrUserAlreadyExist
 	}
 
-	userFileName := getUserFileName(user.Login)
-	userFileData, err := json.Marshal(user)
-	if err != nil {
-		return fmt.Errorf("marshal user entity: %w", err)
-	}
-	userReader := bytes.NewReader(userFileData)
-	params := s3.PutObjectInput{
-		Bucket: &r.bucket,
-		Key:    &userFileName,
-		Body:   userReader,
-	}
-	_, err = r.client.PutObject(ctx, &params)
-	if err != nil {
-		return fmt.Errorf("put object: %w", err)
-	}
-
-	return nil
+	return r.putUser(ctx, user, "")
 }
 
 // GetByLogin return user by login from storage.
 func (r *UserRepository) GetByLogin(ctx context.Context, login string) (entity.User, error) {
+	user, _, err := r.getUser(ctx, login)
+	return user, err
+}
+
+// UpdatePasswordHash replace stored user password hash.
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, login string, passwordHash string) error {
+	return r.update(ctx, login, func(user *entity.User) error {
+		user.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+// UpdateKDF replace stored user vault key derivation parameters.
+func (r *UserRepository) UpdateKDF(ctx context.Context, login string, kdf entity.KDF, previousKDF *entity.KDF) error {
+	return r.update(ctx, login, func(user *entity.User) error {
+		user.KDF = kdf
+		user.PreviousKDF = previousKDF
+		return nil
+	})
+}
+
+// SwapOTP replace stored user two-factor authentication state only if it equals old one,
+// otherwise repository.ErrOTPChanged is returned.
+func (r *UserRepository) SwapOTP(ctx context.Context, login string, old *entity.OTP, otp *entity.OTP) error {
+	return r.update(ctx, login, func(user *entity.User) error {
+		if !repository.EqualOTP(user.OTP, old) {
+			return repository.ErrOTPChanged
+		}
+		user.OTP = otp
+		return nil
+	})
+}
+
+// UpdateSRP replace stored user SRP verifier and clear password hash.
+func (r *UserRepository) UpdateSRP(ctx context.Context, login string, srp *entity.SRP) error {
+	return r.update(ctx, login, func(user *entity.User) error {
+		user.PasswordHash = ""
+		user.SRP = srp
+		return nil
+	})
+}
+
+// update change stored user entity fields by update function. Object is replaced only if it was not changed
+// since it was read (If-Match), otherwise update is repeated with fresh user entity.
+// Update function error leaves object unchanged.
+func (r *UserRepository) update(ctx context.Context, login string, update func(user *entity.User) error) error {
+	var err error
+	for i := 0; i < userUpdateRetries; i++ {
+		user, etag, getErr := r.getUser(ctx, login)
+		if getErr != nil {
+			return getErr
+		}
+		if err = update(&user); err != nil {
+			return err
+		}
+		err = r.putUser(ctx, user, etag)
+		if !isPreconditionFailed(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// getUser return user object with its ETag.
+func (r *UserRepository) getUser(ctx context.Context, login string) (entity.User, string, error) {
 	userFileName := getUserFileName(login)
 	params := s3.GetObjectInput{
 		Bucket: &r.bucket,
@@ -61,22 +113,66 @@ func (r *UserRepository) GetByLogin(ctx context.Context, login string) (entity.U
 	}
 	out, err := r.client.GetObject(ctx, &params)
 	if err != nil {
-		return entity.User{}, repository.ErrUserNotFound
+		return entity.User{}, "", repository.ErrUserNotFound
 	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer out.Body.Close()
 	userFileData, err := io.ReadAll(out.Body)
 	if err != nil {
-		return entity.User{}, fmt.Errorf("read user file body: %w", err)
+		return entity.User{}, "", fmt.Errorf("read user file body: %w", err)
 	}
-	//goland:noinspection GoUnhandledErrorResult
-	defer out.Body.Close()
 
 	var user entity.User
 	err = json.Unmarshal(userFileData, &user)
 	if err != nil {
-		return entity.User{}, fmt.Errorf("unmarshal user data: %w", err)
+		return entity.User{}, "", fmt.Errorf("unmarshal user data: %w", err)
+	}
+
+	var etag string
+	if out.ETag != nil {
+		etag = *out.ETag
+	}
+
+	return user, etag, nil
+}
+
+// Delete remove user entity from storage.
+func (r *UserRepository) Delete(ctx context.Context, login string) error {
+	if _, err := r.GetByLogin(ctx, login); err != nil {
+		return err
+	}
+
+	userFileName := getUserFileName(login)
+	params := s3.DeleteObjectInput{
+		Bucket: &r.bucket,
+		Key:    &userFileName,
+	}
+	if _, err := r.client.DeleteObject(ctx, &params); err != nil {
+		return fmt.Errorf("delete object: %w", err)
+	}
+
+	return nil
+}
+
+// putUser store user object, non-empty etag makes write conditional.
+func (r *UserRepository) putUser(ctx context.Context, user entity.User, etag string) error {
+	userFileName := getUserFileName(user.Login)
+	userFileData, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("marshal user entity: %w", err)
+	}
+	userReader := bytes.NewReader(userFileData)
+	params := s3.PutObjectInput{
+		Bucket: &r.bucket,
+		Key:    &userFileName,
+		Body:   userReader,
+	}
+	_, err = r.client.PutObject(ctx, &params, withIfMatch(etag))
+	if err != nil {
+		return fmt.Errorf("put object: %w", err)
 	}
 
-	return user, nil
+	return nil
 }
 
 func getUserFileName(login string) string {
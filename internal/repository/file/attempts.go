@@ -0,0 +1,123 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"keeper/internal/entity"
+)
+
+// AttemptRepository embedded file failed login attempts storage.
+type AttemptRepository struct {
+	db *bbolt.DB
+}
+
+// NewAttemptRepository construct AttemptRepository.
+func NewAttemptRepository(db *bbolt.DB) *AttemptRepository {
+	return &AttemptRepository{
+		db: db,
+	}
+}
+
+// GetAttempts return failed attempts counter by key, counter is empty when there were no failures.
+func (r *AttemptRepository) GetAttempts(_ context.Context, key string) (entity.Attempts, error) {
+	var attempts entity.Attempts
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		attempts, err = getAttempts(getBucket(tx, attemptsBucket), key)
+		return err
+	})
+	if err != nil {
+		return entity.Attempts{}, err
+	}
+
+	return attempts, nil
+}
+
+// ReserveAttempt increment failed attempts counter when allowed accepts it and return counter,
+// counter older than window starts from scratch.
+func (r *AttemptRepository) ReserveAttempt(_ context.Context, key string, at time.Time, window time.Duration, allowed func(entity.Attempts) bool) (entity.Attempts, bool, error) {
+	var attempts entity.Attempts
+	var reserved bool
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createBucket(tx, attemptsBucket)
+		if err != nil {
+			return err
+		}
+		if attempts, err = getAttempts(b, key); err != nil {
+			return err
+		}
+		if at.Sub(attempts.LastFailureAt) > window {
+			attempts = entity.Attempts{Key: key}
+		}
+		if !allowed(attempts) {
+			return nil
+		}
+		attempts.Failures++
+		attempts.LastFailureAt = at
+		reserved = true
+		return putAttempts(b, attempts)
+	})
+	if err != nil {
+		return entity.Attempts{}, false, err
+	}
+
+	return attempts, reserved, nil
+}
+
+// ReleaseAttempt decrement failed attempts counter, counter is removed when it drops to zero.
+func (r *AttemptRepository) ReleaseAttempt(_ context.Context, key string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, attemptsBucket)
+		if b == nil {
+			return nil
+		}
+		attempts, err := getAttempts(b, key)
+		if err != nil {
+			return err
+		}
+		attempts.Failures--
+		if attempts.Failures <= 0 {
+			return b.Delete([]byte(key))
+		}
+		return putAttempts(b, attempts)
+	})
+}
+
+// ResetAttempts remove failed attempts counter.
+func (r *AttemptRepository) ResetAttempts(_ context.Context, key string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, attemptsBucket)
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func getAttempts(b *bbolt.Bucket, key string) (entity.Attempts, error) {
+	if b == nil {
+		return entity.Attempts{Key: key}, nil
+	}
+	data := b.Get([]byte(key))
+	if data == nil {
+		return entity.Attempts{Key: key}, nil
+	}
+	var attempts entity.Attempts
+	if err := json.Unmarshal(data, &attempts); err != nil {
+		return entity.Attempts{}, fmt.Errorf("unmarshal attempts data: %w", err)
+	}
+	return attempts, nil
+}
+
+func putAttempts(b *bbolt.Bucket, attempts entity.Attempts) error {
+	data, err := json.Marshal(attempts)
+	if err != nil {
+		return fmt.Errorf("marshal attempts entity: %w", err)
+	}
+	return b.Put([]byte(attempts.Key), data)
+}
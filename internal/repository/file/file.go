@@ -0,0 +1,44 @@
+// Package file contains embedded single file (bbolt) storage implementation of Keeper repositories.
+package file
+
+import (
+	"go.etcd.io/bbolt"
+)
+
+var (
+	usersBucket    = []byte("users")
+	tokensBucket   = []byte("tokens")
+	itemsBucket    = []byte("items")
+	versionsBucket = []byte("versions")
+	deletedBucket  = []byte("deleted")
+
+	srpExchangesBucket = []byte("srp_exchanges")
+	attemptsBucket     = []byte("attempts")
+)
+
+// getBucket return nested bucket by path or nil if any bucket in path not exist.
+func getBucket(tx *bbolt.Tx, path ...[]byte) *bbolt.Bucket {
+	b := tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		if b == nil {
+			return nil
+		}
+		b = b.Bucket(name)
+	}
+	return b
+}
+
+// createBucket return nested bucket by path creating missed buckets.
+func createBucket(tx *bbolt.Tx, path ...[]byte) (*bbolt.Bucket, error) {
+	b, err := tx.CreateBucketIfNotExists(path[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range path[1:] {
+		b, err = b.CreateBucketIfNotExists(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,125 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+	"keeper/pkg/bolt"
+)
+
+// openTestDB open database file with fsync of every write, as server does by default.
+func openTestDB(t *testing.T, path string) *bbolt.DB {
+	t.Helper()
+	db, err := bolt.NewDB(path, bolt.SyncAlways, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestStoragePersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keeper.db")
+	now := time.Now()
+	user := entity.User{ID: "user-id", Login: "user", PasswordHash: "hash", CreatedAt: now}
+	token := entity.Token{ID: "token-id", UserLogin: user.Login, AccessHash: "access", CreatedAt: now}
+	item := entity.Item{ID: "item-id", UserID: user.ID, Name: "note", Type: "text", Data: []byte("v1"), Revision: 1, CreatedAt: now, UpdatedAt: now}
+
+	db := openTestDB(t, path)
+	if err := NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewTokenRepository(db, time.Hour).CreateToken(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	items := NewItemRepository(db)
+	if err := items.Create(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	item.Data = []byte("v2")
+	if err := items.Update(ctx, item, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Everything stored before restart should be read from the same file.
+	db = openTestDB(t, path)
+	t.Cleanup(func() {
+		//goland:noinspection GoUnhandledErrorResult
+		db.Close()
+	})
+	storedUser, err := NewUserRepository(db).GetByLogin(ctx, user.Login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedUser.ID != user.ID || storedUser.PasswordHash != user.PasswordHash {
+		t.Errorf("stored user = %+v, want %+v", storedUser, user)
+	}
+	storedToken, err := NewTokenRepository(db, time.Hour).GetToken(ctx, token.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedToken.UserLogin != user.Login || storedToken.AccessHash != token.AccessHash {
+		t.Errorf("stored token = %+v, want %+v", storedToken, token)
+	}
+	items = NewItemRepository(db)
+	storedItem, err := items.GetByUserIDAndName(ctx, user.ID, item.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storedItem.Revision != 2 || string(storedItem.Data) != "v2" {
+		t.Errorf("stored item = %+v, want revision 2 with v2 data", storedItem)
+	}
+	versions, err := items.FindVersions(ctx, user.ID, item.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || string(versions[0].Data) != "v1" {
+		t.Errorf("item versions = %+v, want v1 version", versions)
+	}
+}
+
+func TestItemRepositoryDeleteByUser(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t, filepath.Join(t.TempDir(), "keeper.db"))
+	t.Cleanup(func() {
+		//goland:noinspection GoUnhandledErrorResult
+		db.Close()
+	})
+	items := NewItemRepository(db)
+	for _, userID := range []string{"user", "other"} {
+		item := entity.Item{UserID: userID, Name: "note", Data: []byte("v1"), Revision: 1}
+		if err := items.Create(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+		if err := items.Update(ctx, item, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := items.DeleteByUser(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := items.GetByUserIDAndName(ctx, "user", "note"); !errors.Is(err, repository.ErrItemNotFound) {
+		t.Errorf("GetByUserIDAndName() of removed user item error = %v, want %v", err, repository.ErrItemNotFound)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	db.View(func(tx *bbolt.Tx) error {
+		if getBucket(tx, versionsBucket, []byte("user")) != nil {
+			t.Error("removed user item history is kept")
+		}
+		return nil
+	})
+	if _, err := items.GetByUserIDAndName(ctx, "other", "note"); err != nil {
+		t.Errorf("GetByUserIDAndName() of other user item error = %v", err)
+	}
+}
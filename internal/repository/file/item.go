@@ -0,0 +1,423 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// ItemRepository embedded file item storage.
+type ItemRepository struct {
+	db *bbolt.DB
+}
+
+// NewItemRepository construct ItemRepository.
+func NewItemRepository(db *bbolt.DB) *ItemRepository {
+	return &ItemRepository{
+		db: db,
+	}
+}
+
+// Create store item in storage.
+func (r *ItemRepository) Create(_ context.Context, item entity.Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("marshal item entity: %w", err)
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createBucket(tx, itemsBucket, []byte(item.UserID))
+		if err != nil {
+			return err
+		}
+		if b.Get([]byte(item.Name)) != nil {
+			return repository.ErrItemAlreadyExist
+		}
+		if err := b.Put([]byte(item.Name), data); err != nil {
+			return err
+		}
+		if d := getBucket(tx, deletedBucket, []byte(item.UserID)); d != nil {
+			return d.Delete([]byte(item.Name))
+		}
+		return nil
+	})
+}
+
+// Update store new version item in storage, previous version is kept in item history.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Update(_ context.Context, item entity.Item, revision int64) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(item.UserID))
+		if b == nil {
+			return repository.ErrItemNotFound
+		}
+		existedData := b.Get([]byte(item.Name))
+		if existedData == nil {
+			return repository.ErrItemNotFound
+		}
+		var existed entity.Item
+		if err := json.Unmarshal(existedData, &existed); err != nil {
+			return fmt.Errorf("unmarshal item data: %w", err)
+		}
+		if revision != 0 && existed.Revision != revision {
+			return repository.ErrItemRevisionMismatch
+		}
+
+		v, err := createBucket(tx, versionsBucket, []byte(item.UserID), []byte(item.Name))
+		if err != nil {
+			return err
+		}
+		seq, err := v.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err := v.Put(key, existedData); err != nil {
+			return err
+		}
+
+		item.Revision = existed.Revision + 1
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("marshal item entity: %w", err)
+		}
+		return b.Put([]byte(item.Name), data)
+	})
+}
+
+// Replace store new version item in storage, current version and item history are removed.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Replace(_ context.Context, item entity.Item, revision int64) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(item.UserID))
+		if b == nil {
+			return repository.ErrItemNotFound
+		}
+		existedData := b.Get([]byte(item.Name))
+		if existedData == nil {
+			return repository.ErrItemNotFound
+		}
+		var existed entity.Item
+		if err := json.Unmarshal(existedData, &existed); err != nil {
+			return fmt.Errorf("unmarshal item data: %w", err)
+		}
+		if revision != 0 && existed.Revision != revision {
+			return repository.ErrItemRevisionMismatch
+		}
+
+		item.Revision = existed.Revision + 1
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("marshal item entity: %w", err)
+		}
+		if err := b.Put([]byte(item.Name), data); err != nil {
+			return err
+		}
+		if v := getBucket(tx, versionsBucket, []byte(item.UserID)); v != nil && v.Bucket([]byte(item.Name)) != nil {
+			return v.DeleteBucket([]byte(item.Name))
+		}
+		return nil
+	})
+}
+
+// UpdateDataKey store new item data key and update time, previous item version is not kept as item data is the same.
+// Data keys of previous versions are replaced by not empty version keys, oldest first,
+// versions without data key are removed as they are encrypted directly with replaced vault key.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) UpdateDataKey(_ context.Context, item entity.Item, versionKeys [][]byte, revision int64) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(item.UserID))
+		if b == nil {
+			return repository.ErrItemNotFound
+		}
+		existedData := b.Get([]byte(item.Name))
+		if existedData == nil {
+			return repository.ErrItemNotFound
+		}
+		var existed entity.Item
+		if err := json.Unmarshal(existedData, &existed); err != nil {
+			return fmt.Errorf("unmarshal item data: %w", err)
+		}
+		if revision != 0 && existed.Revision != revision {
+			return repository.ErrItemRevisionMismatch
+		}
+
+		existed.DataKey = item.DataKey
+		existed.UpdatedAt = item.UpdatedAt
+		existed.Revision++
+		data, err := json.Marshal(existed)
+		if err != nil {
+			return fmt.Errorf("marshal item entity: %w", err)
+		}
+		if err := b.Put([]byte(item.Name), data); err != nil {
+			return err
+		}
+		return updateVersionKeys(tx, item, versionKeys)
+	})
+}
+
+// updateVersionKeys replace data keys of item previous versions by not empty version keys, oldest first,
+// and remove versions without data key.
+func updateVersionKeys(tx *bbolt.Tx, item entity.Item, versionKeys [][]byte) error {
+	v := getBucket(tx, versionsBucket, []byte(item.UserID), []byte(item.Name))
+	if v == nil {
+		return nil
+	}
+	var keys [][]byte
+	//goland:noinspection GoUnhandledErrorResult
+	v.ForEach(func(k, _ []byte) error {
+		keys = append(keys, k)
+		return nil
+	})
+	for i, key := range keys {
+		var version entity.Item
+		if err := json.Unmarshal(v.Get(key), &version); err != nil {
+			return fmt.Errorf("unmarshal item version data: %w", err)
+		}
+		if i < len(versionKeys) && len(versionKeys[i]) > 0 {
+			version.DataKey = versionKeys[i]
+		} else if len(version.DataKey) > 0 {
+			continue
+		}
+		if len(version.DataKey) == 0 {
+			if err := v.Delete(key); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := json.Marshal(version)
+		if err != nil {
+			return fmt.Errorf("marshal item version entity: %w", err)
+		}
+		if err := v.Put(key, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetByUserIDAndName return item from storage by user ID and item name.
+func (r *ItemRepository) GetByUserIDAndName(_ context.Context, userID string, name string) (entity.Item, error) {
+	var item entity.Item
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(userID))
+		if b == nil {
+			return repository.ErrItemNotFound
+		}
+		data := b.Get([]byte(name))
+		if data == nil {
+			return repository.ErrItemNotFound
+		}
+		if err := json.Unmarshal(data, &item); err != nil {
+			return fmt.Errorf("unmarshal item data: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.Item{}, err
+	}
+
+	return item, nil
+}
+
+// Delete remove item and its history from storage.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Delete(_ context.Context, item entity.Item, revision int64) error {
+	deletedAt, err := json.Marshal(time.Now())
+	if err != nil {
+		return fmt.Errorf("marshal item deletion time: %w", err)
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(item.UserID))
+		if b == nil {
+			return repository.ErrItemNotFound
+		}
+		existedData := b.Get([]byte(item.Name))
+		if existedData == nil {
+			return repository.ErrItemNotFound
+		}
+		var existed entity.Item
+		if err := json.Unmarshal(existedData, &existed); err != nil {
+			return fmt.Errorf("unmarshal item data: %w", err)
+		}
+		if revision != 0 && existed.Revision != revision {
+			return repository.ErrItemRevisionMismatch
+		}
+
+		if err := b.Delete([]byte(item.Name)); err != nil {
+			return err
+		}
+		if v := getBucket(tx, versionsBucket, []byte(item.UserID)); v != nil && v.Bucket([]byte(item.Name)) != nil {
+			if err := v.DeleteBucket([]byte(item.Name)); err != nil {
+				return err
+			}
+		}
+		d, err := createBucket(tx, deletedBucket, []byte(item.UserID))
+		if err != nil {
+			return err
+		}
+		return d.Put([]byte(item.Name), deletedAt)
+	})
+}
+
+// DeleteByUser remove all user items with their history from storage.
+func (r *ItemRepository) DeleteByUser(_ context.Context, userID string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{itemsBucket, versionsBucket, deletedBucket} {
+			b := getBucket(tx, name)
+			if b == nil || b.Bucket([]byte(userID)) == nil {
+				continue
+			}
+			if err := b.DeleteBucket([]byte(userID)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindByUser return item summaries list from storage for user ID.
+// Items are returned in name order starting after provided name, not positive limit returns all items.
+func (r *ItemRepository) FindByUser(_ context.Context, userID string, limit int, after string) ([]entity.ItemSummary, error) {
+	list := []entity.ItemSummary{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(userID))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		k, data := c.Seek([]byte(after))
+		if k != nil && string(k) == after {
+			k, data = c.Next()
+		}
+		for ; k != nil && (limit <= 0 || len(list) < limit); k, data = c.Next() {
+			var summary entity.ItemSummary
+			if err := json.Unmarshal(data, &summary); err != nil {
+				return fmt.Errorf("unmarshal item data: %w", err)
+			}
+			list = append(list, summary)
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.ItemSummary{}, err
+	}
+
+	return list, nil
+}
+
+// FindByFilter return summaries of user items matching filter, ordered by name.
+// Only names with glob pattern literal prefix are scanned and only items with matching names are decoded.
+func (r *ItemRepository) FindByFilter(_ context.Context, userID string, filter entity.ItemFilter) ([]entity.ItemSummary, error) {
+	matcher, err := repository.NewItemMatcher(filter)
+	if err != nil {
+		return []entity.ItemSummary{}, err
+	}
+
+	list := []entity.ItemSummary{}
+	prefix := []byte(matcher.NamePrefix())
+	err = r.db.View(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(userID))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, data := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, data = c.Next() {
+			if !matcher.MatchName(string(k)) {
+				continue
+			}
+			var summary entity.ItemSummary
+			if err := json.Unmarshal(data, &summary); err != nil {
+				return fmt.Errorf("unmarshal item data: %w", err)
+			}
+			if matcher.Match(summary) {
+				list = append(list, summary)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return []entity.ItemSummary{}, err
+	}
+
+	return list, nil
+}
+
+// FindVersions return previous versions of item from storage, oldest first.
+func (r *ItemRepository) FindVersions(_ context.Context, userID string, name string) ([]entity.Item, error) {
+	var versions []entity.Item
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, itemsBucket, []byte(userID))
+		if b == nil || b.Get([]byte(name)) == nil {
+			return repository.ErrItemNotFound
+		}
+		v := getBucket(tx, versionsBucket, []byte(userID), []byte(name))
+		if v == nil {
+			return nil
+		}
+		return v.ForEach(func(_, data []byte) error {
+			var item entity.Item
+			if err := json.Unmarshal(data, &item); err != nil {
+				return fmt.Errorf("unmarshal item version data: %w", err)
+			}
+			versions = append(versions, item)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// FindChanges return items updated and names of items deleted after since time.
+func (r *ItemRepository) FindChanges(_ context.Context, userID string, since time.Time) ([]entity.Item, []string, error) {
+	var items []entity.Item
+	var deleted []string
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		if b := getBucket(tx, itemsBucket, []byte(userID)); b != nil {
+			err := b.ForEach(func(_, data []byte) error {
+				var item entity.Item
+				if err := json.Unmarshal(data, &item); err != nil {
+					return fmt.Errorf("unmarshal item data: %w", err)
+				}
+				if item.UpdatedAt.After(since) {
+					items = append(items, item)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if d := getBucket(tx, deletedBucket, []byte(userID)); d != nil {
+			return d.ForEach(func(name, data []byte) error {
+				var deletedAt time.Time
+				if err := json.Unmarshal(data, &deletedAt); err != nil {
+					return fmt.Errorf("unmarshal item deletion time: %w", err)
+				}
+				if deletedAt.After(since) {
+					deleted = append(deleted, string(name))
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items, deleted, nil
+}
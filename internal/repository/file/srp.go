@@ -0,0 +1,82 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// SRPExchangeRepository embedded file started SRP-6a login exchanges storage.
+type SRPExchangeRepository struct {
+	db *bbolt.DB
+}
+
+// NewSRPExchangeRepository construct SRPExchangeRepository.
+func NewSRPExchangeRepository(db *bbolt.DB) *SRPExchangeRepository {
+	return &SRPExchangeRepository{
+		db: db,
+	}
+}
+
+// CreateExchange store started exchange, expired exchanges are removed.
+func (r *SRPExchangeRepository) CreateExchange(_ context.Context, exchange entity.SRPExchange) error {
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		return fmt.Errorf("marshal srp exchange entity: %w", err)
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createBucket(tx, srpExchangesBucket)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		var expired [][]byte
+		err = b.ForEach(func(k, v []byte) error {
+			var e entity.SRPExchange
+			if err := json.Unmarshal(v, &e); err != nil || now.After(e.ExpiresAt) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return b.Put([]byte(exchange.ID), data)
+	})
+}
+
+// TakeExchange remove exchange and return it.
+func (r *SRPExchangeRepository) TakeExchange(_ context.Context, id string) (entity.SRPExchange, error) {
+	var exchange entity.SRPExchange
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, srpExchangesBucket)
+		if b == nil {
+			return repository.ErrSRPExchangeNotFound
+		}
+		data := b.Get([]byte(id))
+		if data == nil {
+			return repository.ErrSRPExchangeNotFound
+		}
+		if err := json.Unmarshal(data, &exchange); err != nil {
+			return fmt.Errorf("unmarshal srp exchange data: %w", err)
+		}
+		return b.Delete([]byte(id))
+	})
+	if err != nil {
+		return entity.SRPExchange{}, err
+	}
+
+	return exchange, nil
+}
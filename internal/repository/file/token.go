@@ -0,0 +1,217 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// TokenRepository embedded file token storage.
+type TokenRepository struct {
+	db       *bbolt.DB
+	lifetime time.Duration
+}
+
+// NewTokenRepository construct TokenRepository.
+func NewTokenRepository(db *bbolt.DB, lifetime time.Duration) *TokenRepository {
+	return &TokenRepository{
+		db:       db,
+		lifetime: lifetime,
+	}
+}
+
+// CreateToken store user token.
+func (r *TokenRepository) CreateToken(_ context.Context, token entity.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("marshal token entity: %w", err)
+	}
+
+	err = r.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createBucket(tx, tokensBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(token.ID), data)
+	})
+	if err != nil {
+		return fmt.Errorf("store token: %w", err)
+	}
+
+	return nil
+}
+
+// GetToken return user token by token ID.
+func (r *TokenRepository) GetToken(_ context.Context, id string) (entity.Token, error) {
+	var token entity.Token
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, tokensBucket)
+		if b == nil {
+			return repository.ErrTokenNotFound
+		}
+		data := b.Get([]byte(id))
+		if data == nil {
+			return repository.ErrTokenNotFound
+		}
+		if err := json.Unmarshal(data, &token); err != nil {
+			return fmt.Errorf("unmarshal token data: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.Token{}, err
+	}
+
+	if time.Since(token.CreatedAt) > r.lifetime {
+		err := r.db.Update(func(tx *bbolt.Tx) error {
+			if b := getBucket(tx, tokensBucket); b != nil {
+				return b.Delete([]byte(id))
+			}
+			return nil
+		})
+		if err != nil {
+			return entity.Token{}, fmt.Errorf("delete token: %w", err)
+		}
+		return entity.Token{}, repository.ErrTokenExpired
+	}
+
+	return token, nil
+}
+
+// UpdateTokenUsage store time and client IP of user token last usage.
+func (r *TokenRepository) UpdateTokenUsage(_ context.Context, id string, ip string, usedAt time.Time) error {
+	err := r.updateToken(id, func(token *entity.Token) error {
+		token.Device.IP = ip
+		token.LastUsedAt = usedAt
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("update token usage: %w", err)
+	}
+	return nil
+}
+
+// RotateToken replace access and refresh token hashes and access expiration time of user token
+// if refresh token hash is still refreshHash, replaced hash is kept for reuse detection.
+func (r *TokenRepository) RotateToken(_ context.Context, id string, refreshHash string, accessHash string, newRefreshHash string, expiresAt time.Time) error {
+	err := r.updateToken(id, func(token *entity.Token) error {
+		if token.RefreshHash != refreshHash {
+			return repository.ErrTokenRotated
+		}
+		token.AccessHash = accessHash
+		token.RefreshHash = newRefreshHash
+		token.PreviousRefreshHash = refreshHash
+		token.AccessExpiresAt = expiresAt
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("rotate token: %w", err)
+	}
+	return nil
+}
+
+// FindTokensByUser return not expired user tokens.
+func (r *TokenRepository) FindTokensByUser(_ context.Context, login string) ([]entity.Token, error) {
+	var tokens []entity.Token
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, tokensBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var token entity.Token
+			if err := json.Unmarshal(v, &token); err != nil {
+				return fmt.Errorf("unmarshal token data: %w", err)
+			}
+			if token.UserLogin == login && time.Since(token.CreatedAt) <= r.lifetime {
+				tokens = append(tokens, token)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find user tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteToken remove user token, removing not existed token is not an error.
+func (r *TokenRepository) DeleteToken(_ context.Context, id string) error {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		if b := getBucket(tx, tokensBucket); b != nil {
+			return b.Delete([]byte(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("delete token: %w", err)
+	}
+	return nil
+}
+
+// DeleteTokensByUser remove all tokens of user.
+func (r *TokenRepository) DeleteTokensByUser(_ context.Context, login string) error {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, tokensBucket)
+		if b == nil {
+			return nil
+		}
+		var ids [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			var token entity.Token
+			if err := json.Unmarshal(v, &token); err != nil {
+				return fmt.Errorf("unmarshal token data: %w", err)
+			}
+			if token.UserLogin == login {
+				ids = append(ids, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Keys can't be deleted while iterating bucket.
+		for _, id := range ids {
+			if err := b.Delete(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("delete user tokens: %w", err)
+	}
+	return nil
+}
+
+// updateToken apply update to stored token in single transaction, update error cancels it.
+func (r *TokenRepository) updateToken(id string, update func(token *entity.Token) error) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, tokensBucket)
+		if b == nil {
+			return repository.ErrTokenNotFound
+		}
+		data := b.Get([]byte(id))
+		if data == nil {
+			return repository.ErrTokenNotFound
+		}
+		var token entity.Token
+		if err := json.Unmarshal(data, &token); err != nil {
+			return fmt.Errorf("unmarshal token data: %w", err)
+		}
+		if err := update(&token); err != nil {
+			return err
+		}
+		data, err := json.Marshal(token)
+		if err != nil {
+			return fmt.Errorf("marshal token entity: %w", err)
+		}
+		return b.Put([]byte(id), data)
+	})
+}
@@ -0,0 +1,143 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// UserRepository embedded file user storage.
+type UserRepository struct {
+	db *bbolt.DB
+}
+
+// NewUserRepository construct UserRepository.
+func NewUserRepository(db *bbolt.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+// Create store user entity in storage.
+func (r *UserRepository) Create(_ context.Context, user entity.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("marshal user entity: %w", err)
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b, err := createBucket(tx, usersBucket)
+		if err != nil {
+			return err
+		}
+		if b.Get([]byte(user.Login)) != nil {
+			return repository.ErrUserAlreadyExist
+		}
+		return b.Put([]byte(user.Login), data)
+	})
+}
+
+// GetByLogin return user by login from storage.
+func (r *UserRepository) GetByLogin(_ context.Context, login string) (entity.User, error) {
+	var user entity.User
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, usersBucket)
+		if b == nil {
+			return repository.ErrUserNotFound
+		}
+		data := b.Get([]byte(login))
+		if data == nil {
+			return repository.ErrUserNotFound
+		}
+		if err := json.Unmarshal(data, &user); err != nil {
+			return fmt.Errorf("unmarshal user data: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+// UpdatePasswordHash replace stored user password hash.
+func (r *UserRepository) UpdatePasswordHash(_ context.Context, login string, passwordHash string) error {
+	return r.update(login, func(user *entity.User) error {
+		user.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+// UpdateKDF replace stored user vault key derivation parameters.
+func (r *UserRepository) UpdateKDF(_ context.Context, login string, kdf entity.KDF, previousKDF *entity.KDF) error {
+	return r.update(login, func(user *entity.User) error {
+		user.KDF = kdf
+		user.PreviousKDF = previousKDF
+		return nil
+	})
+}
+
+// SwapOTP replace stored user two-factor authentication state only if it equals old one,
+// otherwise repository.ErrOTPChanged is returned.
+func (r *UserRepository) SwapOTP(_ context.Context, login string, old *entity.OTP, otp *entity.OTP) error {
+	return r.update(login, func(user *entity.User) error {
+		if !repository.EqualOTP(user.OTP, old) {
+			return repository.ErrOTPChanged
+		}
+		user.OTP = otp
+		return nil
+	})
+}
+
+// UpdateSRP replace stored user SRP verifier and clear password hash.
+func (r *UserRepository) UpdateSRP(_ context.Context, login string, srp *entity.SRP) error {
+	return r.update(login, func(user *entity.User) error {
+		user.PasswordHash = ""
+		user.SRP = srp
+		return nil
+	})
+}
+
+// Delete remove user entity from storage.
+func (r *UserRepository) Delete(_ context.Context, login string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, usersBucket)
+		if b == nil || b.Get([]byte(login)) == nil {
+			return repository.ErrUserNotFound
+		}
+		return b.Delete([]byte(login))
+	})
+}
+
+// update change stored user entity fields by update function in single transaction,
+// update function error leaves entity unchanged.
+func (r *UserRepository) update(login string, update func(user *entity.User) error) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := getBucket(tx, usersBucket)
+		if b == nil {
+			return repository.ErrUserNotFound
+		}
+		data := b.Get([]byte(login))
+		if data == nil {
+			return repository.ErrUserNotFound
+		}
+		var user entity.User
+		if err := json.Unmarshal(data, &user); err != nil {
+			return fmt.Errorf("unmarshal user data: %w", err)
+		}
+		if err := update(&user); err != nil {
+			return err
+		}
+		data, err := json.Marshal(user)
+		if err != nil {
+			return fmt.Errorf("marshal user entity: %w", err)
+		}
+		return b.Put([]byte(login), data)
+	})
+}
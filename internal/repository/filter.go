@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"keeper/internal/entity"
+)
+
+const globMeta = `*?[\`
+
+var (
+	ErrBadPattern = errors.New("invalid glob pattern")
+)
+
+// ItemMatcher check items by items filter conditions.
+// Filter name with "*", "?", "[...]" or "\" is glob pattern where "*" matches any characters including "/",
+// otherwise it is name substring.
+type ItemMatcher struct {
+	filter entity.ItemFilter
+	glob   *regexp.Regexp
+}
+
+// NewItemMatcher construct ItemMatcher.
+func NewItemMatcher(filter entity.ItemFilter) (*ItemMatcher, error) {
+	m := ItemMatcher{
+		filter: filter,
+	}
+	if strings.ContainsAny(filter.Name, globMeta) {
+		glob, err := compileGlob(filter.Name)
+		if err != nil {
+			return nil, err
+		}
+		m.glob = glob
+	}
+	return &m, nil
+}
+
+// NamePrefix return literal prefix every matching item name has, names list can be started from it.
+func (m *ItemMatcher) NamePrefix() string {
+	if m.glob == nil {
+		return ""
+	}
+	return m.filter.Name[:strings.IndexAny(m.filter.Name, globMeta)]
+}
+
+// NameSubstring return substring every matching item name contains.
+func (m *ItemMatcher) NameSubstring() string {
+	if m.glob != nil {
+		return ""
+	}
+	return m.filter.Name
+}
+
+// MatchName check item name.
+func (m *ItemMatcher) MatchName(name string) bool {
+	if m.glob != nil {
+		return m.glob.MatchString(name)
+	}
+	return strings.Contains(name, m.filter.Name)
+}
+
+// Match check item by all filter conditions, metadata record with empty value matches any value of its key.
+func (m *ItemMatcher) Match(item entity.ItemSummary) bool {
+	if !m.MatchName(item.Name) {
+		return false
+	}
+	if m.filter.Type != "" && m.filter.Type != item.Type {
+		return false
+	}
+	for _, fm := range m.filter.Metadata {
+		found := false
+		for _, im := range item.Metadata {
+			if im.Key == fm.Key && (fm.Value == "" || im.Value == fm.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// compileGlob convert glob pattern to anchored regular expression.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, ErrBadPattern
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 1 {
+				return nil, ErrBadPattern
+			}
+			class := pattern[i+1 : i+1+end]
+			if class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, ErrBadPattern
+	}
+	return re, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"keeper/internal/entity"
+)
+
+func TestItemMatcherName(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		name      string
+		want      bool
+		prefix    string
+		substring string
+	}{
+		{pattern: "", name: "anything", want: true},
+		{pattern: "bank", name: "work/bank-card", want: true, substring: "bank"},
+		{pattern: "bank", name: "work/card", want: false, substring: "bank"},
+		{pattern: "work/*", name: "work/mail/inbox", want: true, prefix: "work/"},
+		{pattern: "work/*", name: "home/work/mail", want: false, prefix: "work/"},
+		{pattern: "card-?", name: "card-1", want: true, prefix: "card-"},
+		{pattern: "card-?", name: "card-12", want: false, prefix: "card-"},
+		{pattern: "card-[0-9]", name: "card-7", want: true, prefix: "card-"},
+		{pattern: "card-[!0-9]", name: "card-7", want: false, prefix: "card-"},
+		{pattern: `what\?`, name: "what?", want: true, prefix: "what"},
+		{pattern: `what\?`, name: "whats", want: false, prefix: "what"},
+		{pattern: "a.b*", name: "axb", want: false, prefix: "a.b"},
+	}
+	for _, tt := range tests {
+		m, err := NewItemMatcher(entity.ItemFilter{Name: tt.pattern})
+		if err != nil {
+			t.Fatalf("NewItemMatcher(%q) error = %v", tt.pattern, err)
+		}
+		if got := m.MatchName(tt.name); got != tt.want {
+			t.Errorf("%q: MatchName(%q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+		if got := m.NamePrefix(); got != tt.prefix {
+			t.Errorf("%q: NamePrefix() = %q, want %q", tt.pattern, got, tt.prefix)
+		}
+		if got := m.NameSubstring(); got != tt.substring {
+			t.Errorf("%q: NameSubstring() = %q, want %q", tt.pattern, got, tt.substring)
+		}
+	}
+}
+
+func TestItemMatcherBadPattern(t *testing.T) {
+	for _, pattern := range []string{`trailing\`, "open[", "empty[]", "bad[z-a]"} {
+		if _, err := NewItemMatcher(entity.ItemFilter{Name: pattern}); !errors.Is(err, ErrBadPattern) {
+			t.Errorf("NewItemMatcher(%q) error = %v, want %v", pattern, err, ErrBadPattern)
+		}
+	}
+}
+
+func TestItemMatcherMatch(t *testing.T) {
+	item := entity.ItemSummary{
+		Name:     "acme-card",
+		Type:     "card",
+		Metadata: []entity.Metadata{{Key: "bank", Value: "acme"}, {Key: "owner", Value: "me"}},
+	}
+	tests := []struct {
+		name   string
+		filter entity.ItemFilter
+		want   bool
+	}{
+		{name: "empty filter", filter: entity.ItemFilter{}, want: true},
+		{name: "type", filter: entity.ItemFilter{Type: "card"}, want: true},
+		{name: "other type", filter: entity.ItemFilter{Type: "text"}, want: false},
+		{name: "metadata", filter: entity.ItemFilter{Metadata: []entity.Metadata{{Key: "bank", Value: "acme"}}}, want: true},
+		{name: "metadata key", filter: entity.ItemFilter{Metadata: []entity.Metadata{{Key: "owner"}}}, want: true},
+		{name: "other metadata value", filter: entity.ItemFilter{Metadata: []entity.Metadata{{Key: "bank", Value: "other"}}}, want: false},
+		{
+			name:   "all metadata records",
+			filter: entity.ItemFilter{Metadata: []entity.Metadata{{Key: "bank", Value: "acme"}, {Key: "absent"}}},
+			want:   false,
+		},
+		{name: "all conditions", filter: entity.ItemFilter{Name: "acme*", Type: "card", Metadata: []entity.Metadata{{Key: "owner"}}}, want: true},
+		{name: "name mismatch", filter: entity.ItemFilter{Name: "bank", Type: "card"}, want: false},
+	}
+	for _, tt := range tests {
+		m, err := NewItemMatcher(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := m.Match(item); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"keeper/internal/entity"
+)
+
+// attemptsSweepInterval is how often counters older than window are removed.
+const attemptsSweepInterval = time.Minute
+
+// AttemptRepository in memory failed login attempts storage.
+type AttemptRepository struct {
+	mu        *sync.Mutex
+	attempts  map[string]entity.Attempts
+	lastSweep time.Time
+}
+
+// NewAttemptRepository construct AttemptRepository.
+func NewAttemptRepository() *AttemptRepository {
+	return &AttemptRepository{
+		mu:       new(sync.Mutex),
+		attempts: map[string]entity.Attempts{},
+	}
+}
+
+// GetAttempts return failed attempts counter by key, counter is empty when there were no failures.
+func (r *AttemptRepository) GetAttempts(_ context.Context, key string) (entity.Attempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok {
+		return entity.Attempts{Key: key}, nil
+	}
+
+	return attempts, nil
+}
+
+// ReserveAttempt increment failed attempts counter when allowed accepts it and return counter,
+// counter older than window starts from scratch.
+func (r *AttemptRepository) ReserveAttempt(_ context.Context, key string, at time.Time, window time.Duration, allowed func(entity.Attempts) bool) (entity.Attempts, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if at.Sub(r.lastSweep) >= attemptsSweepInterval {
+		for k, a := range r.attempts {
+			if at.Sub(a.LastFailureAt) > window {
+				delete(r.attempts, k)
+			}
+		}
+		r.lastSweep = at
+	}
+
+	attempts, ok := r.attempts[key]
+	if !ok || at.Sub(attempts.LastFailureAt) > window {
+		attempts = entity.Attempts{Key: key}
+	}
+	if !allowed(attempts) {
+		return attempts, false, nil
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = at
+	r.attempts[key] = attempts
+
+	return attempts, true, nil
+}
+
+// ReleaseAttempt decrement failed attempts counter, counter is removed when it drops to zero.
+func (r *AttemptRepository) ReleaseAttempt(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok {
+		return nil
+	}
+	attempts.Failures--
+	if attempts.Failures <= 0 {
+		delete(r.attempts, key)
+		return nil
+	}
+	r.attempts[key] = attempts
+
+	return nil
+}
+
+// ResetAttempts remove failed attempts counter.
+func (r *AttemptRepository) ResetAttempts(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+
+	return nil
+}
@@ -2,7 +2,9 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"keeper/internal/entity"
 	"keeper/internal/repository"
@@ -10,15 +12,19 @@ import (
 
 // ItemRepository in memory item storage.
 type ItemRepository struct {
-	mu    *sync.RWMutex
-	items map[string]map[string]entity.Item
+	mu       *sync.RWMutex
+	items    map[string]map[string]entity.Item
+	versions map[string]map[string][]entity.Item
+	deleted  map[string]map[string]time.Time
 }
 
 // NewItemRepository construct ItemRepository.
 func NewItemRepository() *ItemRepository {
 	return &ItemRepository{
-		mu:    new(sync.RWMutex),
-		items: map[string]map[string]entity.Item{},
+		mu:       new(sync.RWMutex),
+		items:    map[string]map[string]entity.Item{},
+		versions: map[string]map[string][]entity.Item{},
+		deleted:  map[string]map[string]time.Time{},
 	}
 }
 
@@ -34,19 +40,29 @@ func (r *ItemRepository) Create(_ context.Context, item entity.Item) error {
 		return repository.ErrItemAlreadyExist
 	}
 	r.items[item.UserID][item.Name] = item
+	delete(r.deleted[item.UserID], item.Name)
 
 	return nil
 }
 
-// Update store new version item in storage.
-func (r *ItemRepository) Update(_ context.Context, item entity.Item) error {
+// Update store new version item in storage, previous version is kept in item history.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Update(_ context.Context, item entity.Item, revision int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if _, ok := r.items[item.UserID]; !ok {
 		return repository.ErrItemNotFound
 	}
-	if _, ok := r.items[item.UserID][item.Name]; ok {
+	if existed, ok := r.items[item.UserID][item.Name]; ok {
+		if revision != 0 && existed.Revision != revision {
+			return repository.ErrItemRevisionMismatch
+		}
+		item.Revision = existed.Revision + 1
+		if _, ok := r.versions[item.UserID]; !ok {
+			r.versions[item.UserID] = map[string][]entity.Item{}
+		}
+		r.versions[item.UserID][item.Name] = append(r.versions[item.UserID][item.Name], existed)
 		r.items[item.UserID][item.Name] = item
 		return nil
 	}
@@ -54,6 +70,62 @@ func (r *ItemRepository) Update(_ context.Context, item entity.Item) error {
 	return repository.ErrItemNotFound
 }
 
+// Replace store new version item in storage, current version and item history are removed.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Replace(_ context.Context, item entity.Item, revision int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existed, ok := r.items[item.UserID][item.Name]
+	if !ok {
+		return repository.ErrItemNotFound
+	}
+	if revision != 0 && existed.Revision != revision {
+		return repository.ErrItemRevisionMismatch
+	}
+	item.Revision = existed.Revision + 1
+	r.items[item.UserID][item.Name] = item
+	delete(r.versions[item.UserID], item.Name)
+
+	return nil
+}
+
+// UpdateDataKey store new item data key and update time, previous item version is not kept as item data is the same.
+// Data keys of previous versions are replaced by not empty version keys, oldest first,
+// versions without data key are removed as they are encrypted directly with replaced vault key.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) UpdateDataKey(_ context.Context, item entity.Item, versionKeys [][]byte, revision int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existed, ok := r.items[item.UserID][item.Name]
+	if !ok {
+		return repository.ErrItemNotFound
+	}
+	if revision != 0 && existed.Revision != revision {
+		return repository.ErrItemRevisionMismatch
+	}
+	existed.DataKey = item.DataKey
+	existed.UpdatedAt = item.UpdatedAt
+	existed.Revision++
+	r.items[item.UserID][item.Name] = existed
+	versions := r.versions[item.UserID][item.Name]
+	kept := versions[:0]
+	for i, version := range versions {
+		if i < len(versionKeys) && len(versionKeys[i]) > 0 {
+			version.DataKey = versionKeys[i]
+		}
+		if len(version.DataKey) > 0 {
+			kept = append(kept, version)
+		}
+	}
+	if len(versions) > 0 {
+		r.versions[item.UserID][item.Name] = kept
+	}
+
+	return nil
+}
+
 // GetByUserIDAndName return item from storage by user ID and item name.
 func (r *ItemRepository) GetByUserIDAndName(_ context.Context, userID string, name string) (entity.Item, error) {
 	r.mu.RLock()
@@ -67,30 +139,130 @@ func (r *ItemRepository) GetByUserIDAndName(_ context.Context, userID string, na
 }
 
 // Delete remove item from storage.
-func (r *ItemRepository) Delete(_ context.Context, item entity.Item) error {
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Delete(_ context.Context, item entity.Item, revision int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.items[item.UserID][item.Name]; !ok {
+	existed, ok := r.items[item.UserID][item.Name]
+	if !ok {
 		return repository.ErrItemNotFound
 	}
+	if revision != 0 && existed.Revision != revision {
+		return repository.ErrItemRevisionMismatch
+	}
 	delete(r.items[item.UserID], item.Name)
+	delete(r.versions[item.UserID], item.Name)
+	if _, ok := r.deleted[item.UserID]; !ok {
+		r.deleted[item.UserID] = map[string]time.Time{}
+	}
+	r.deleted[item.UserID][item.Name] = time.Now()
+
+	return nil
+}
+
+// DeleteByUser remove all user items with their history from storage.
+func (r *ItemRepository) DeleteByUser(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.items, userID)
+	delete(r.versions, userID)
+	delete(r.deleted, userID)
 
 	return nil
 }
 
-// FindByUser return item names list from storage for user ID.
-func (r *ItemRepository) FindByUser(_ context.Context, userID string) ([]string, error) {
+// FindByUser return item summaries list from storage for user ID.
+// Items are returned in name order starting after provided name, not positive limit returns all items.
+func (r *ItemRepository) FindByUser(_ context.Context, userID string, limit int, after string) ([]entity.ItemSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]entity.ItemSummary, 0, len(r.items[userID]))
+	for name, item := range r.items[userID] {
+		if name > after {
+			list = append(list, itemSummary(item))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+
+	return list, nil
+}
+
+// FindByFilter return summaries of user items matching filter, ordered by name.
+func (r *ItemRepository) FindByFilter(_ context.Context, userID string, filter entity.ItemFilter) ([]entity.ItemSummary, error) {
+	matcher, err := repository.NewItemMatcher(filter)
+	if err != nil {
+		return []entity.ItemSummary{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := []entity.ItemSummary{}
+	for _, item := range r.items[userID] {
+		summary := itemSummary(item)
+		if matcher.Match(summary) {
+			list = append(list, summary)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// FindVersions return previous versions of item from storage, oldest first.
+func (r *ItemRepository) FindVersions(_ context.Context, userID string, name string) ([]entity.Item, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if items, ok := r.items[userID]; ok {
-		list := make([]string, 0, len(items))
-		for _, item := range items {
-			list = append(list, item.Name)
+	if _, ok := r.items[userID][name]; !ok {
+		return nil, repository.ErrItemNotFound
+	}
+	versions := make([]entity.Item, len(r.versions[userID][name]))
+	copy(versions, r.versions[userID][name])
+
+	return versions, nil
+}
+
+// FindChanges return items updated and names of items deleted after since time.
+func (r *ItemRepository) FindChanges(_ context.Context, userID string, since time.Time) ([]entity.Item, []string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var items []entity.Item
+	for _, item := range r.items[userID] {
+		if item.UpdatedAt.After(since) {
+			items = append(items, item)
+		}
+	}
+	var deleted []string
+	for name, deletedAt := range r.deleted[userID] {
+		if deletedAt.After(since) {
+			deleted = append(deleted, name)
 		}
-		return list, nil
 	}
 
-	return []string{}, nil
+	return items, deleted, nil
+}
+
+func itemSummary(item entity.Item) entity.ItemSummary {
+	return entity.ItemSummary{
+		Name:       item.Name,
+		Type:       item.Type,
+		DataKey:    item.DataKey,
+		Attributes: item.Attributes,
+		Metadata:   item.Metadata,
+		Revision:   item.Revision,
+		CreatedAt:  item.CreatedAt,
+		UpdatedAt:  item.UpdatedAt,
+	}
 }
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// SRPExchangeRepository in memory started SRP-6a login exchanges storage.
+type SRPExchangeRepository struct {
+	mu        *sync.Mutex
+	exchanges map[string]entity.SRPExchange
+}
+
+// NewSRPExchangeRepository construct SRPExchangeRepository.
+func NewSRPExchangeRepository() *SRPExchangeRepository {
+	return &SRPExchangeRepository{
+		mu:        new(sync.Mutex),
+		exchanges: map[string]entity.SRPExchange{},
+	}
+}
+
+// CreateExchange store started exchange, expired exchanges are removed.
+func (r *SRPExchangeRepository) CreateExchange(_ context.Context, exchange entity.SRPExchange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, e := range r.exchanges {
+		if now.After(e.ExpiresAt) {
+			delete(r.exchanges, id)
+		}
+	}
+	r.exchanges[exchange.ID] = exchange
+
+	return nil
+}
+
+// TakeExchange remove exchange and return it.
+func (r *SRPExchangeRepository) TakeExchange(_ context.Context, id string) (entity.SRPExchange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exchange, ok := r.exchanges[id]
+	if !ok {
+		return entity.SRPExchange{}, repository.ErrSRPExchangeNotFound
+	}
+	delete(r.exchanges, id)
+
+	return exchange, nil
+}
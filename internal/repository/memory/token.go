@@ -2,10 +2,6 @@ package memory
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -29,25 +25,91 @@ func NewTokenRepository(lifetime time.Duration) *TokenRepository {
 	}
 }
 
-// CreateToken creates new random user token and store it.
-func (r *TokenRepository) CreateToken(_ context.Context, user entity.User) (string, error) {
+// CreateToken store user token.
+func (r *TokenRepository) CreateToken(_ context.Context, token entity.Token) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	var sb strings.Builder
-	sb.WriteString(user.ID)
-	sb.WriteString(user.Login)
-	sb.WriteString(strconv.Itoa(int(time.Now().UnixNano())))
-	hash := md5.Sum([]byte(sb.String()))
-	token := hex.EncodeToString(hash[:])
-
-	r.tokens[token] = entity.Token{
-		ID:        token,
-		UserLogin: user.Login,
-		CreatedAt: time.Now(),
+	r.tokens[token.ID] = token
+
+	return nil
+}
+
+// UpdateTokenUsage store time and client IP of user token last usage.
+func (r *TokenRepository) UpdateTokenUsage(_ context.Context, id string, ip string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return repository.ErrTokenNotFound
 	}
+	token.Device.IP = ip
+	token.LastUsedAt = usedAt
+	r.tokens[id] = token
 
-	return token, nil
+	return nil
+}
+
+// RotateToken replace access and refresh token hashes and access expiration time of user token
+// if refresh token hash is still refreshHash, replaced hash is kept for reuse detection.
+func (r *TokenRepository) RotateToken(_ context.Context, id string, refreshHash string, accessHash string, newRefreshHash string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return repository.ErrTokenNotFound
+	}
+	if token.RefreshHash != refreshHash {
+		return repository.ErrTokenRotated
+	}
+	token.AccessHash = accessHash
+	token.RefreshHash = newRefreshHash
+	token.PreviousRefreshHash = refreshHash
+	token.AccessExpiresAt = expiresAt
+	r.tokens[id] = token
+
+	return nil
+}
+
+// FindTokensByUser return not expired user tokens.
+func (r *TokenRepository) FindTokensByUser(_ context.Context, login string) ([]entity.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tokens []entity.Token
+	for _, token := range r.tokens {
+		if token.UserLogin == login && time.Since(token.CreatedAt) <= r.lifetime {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
+
+// DeleteToken remove user token, removing not existed token is not an error.
+func (r *TokenRepository) DeleteToken(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tokens, id)
+
+	return nil
+}
+
+// DeleteTokensByUser remove all tokens of user.
+func (r *TokenRepository) DeleteTokensByUser(_ context.Context, login string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserLogin == login {
+			delete(r.tokens, id)
+		}
+	}
+
+	return nil
 }
 
 // GetToken return user token by token ID.
@@ -46,3 +46,72 @@ func (r *UserRepository) GetByLogin(_ context.Context, login string) (entity.Use
 	}
 	return entity.User{}, repository.ErrUserNotFound
 }
+
+// UpdatePasswordHash replace stored user password hash.
+func (r *UserRepository) UpdatePasswordHash(_ context.Context, login string, passwordHash string) error {
+	return r.update(login, func(user *entity.User) error {
+		user.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+// UpdateKDF replace stored user vault key derivation parameters.
+func (r *UserRepository) UpdateKDF(_ context.Context, login string, kdf entity.KDF, previousKDF *entity.KDF) error {
+	return r.update(login, func(user *entity.User) error {
+		user.KDF = kdf
+		user.PreviousKDF = previousKDF
+		return nil
+	})
+}
+
+// SwapOTP replace stored user two-factor authentication state only if it equals old one,
+// otherwise repository.ErrOTPChanged is returned.
+func (r *UserRepository) SwapOTP(_ context.Context, login string, old *entity.OTP, otp *entity.OTP) error {
+	return r.update(login, func(user *entity.User) error {
+		if !repository.EqualOTP(user.OTP, old) {
+			return repository.ErrOTPChanged
+		}
+		user.OTP = otp
+		return nil
+	})
+}
+
+// UpdateSRP replace stored user SRP verifier and clear password hash.
+func (r *UserRepository) UpdateSRP(_ context.Context, login string, srp *entity.SRP) error {
+	return r.update(login, func(user *entity.User) error {
+		user.PasswordHash = ""
+		user.SRP = srp
+		return nil
+	})
+}
+
+// Delete remove user entity from storage.
+func (r *UserRepository) Delete(_ context.Context, login string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[login]; !ok {
+		return repository.ErrUserNotFound
+	}
+
+	delete(r.users, login)
+
+	return nil
+}
+
+// update change stored user entity fields by update function, update function error leaves entity unchanged.
+func (r *UserRepository) update(login string, update func(user *entity.User) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[login]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if err := update(&user); err != nil {
+		return err
+	}
+	r.users[login] = user
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"bytes"
+
+	"keeper/internal/entity"
+)
+
+// EqualOTP report that two-factor authentication states are the same, absent state equals only absent one.
+// Challenge expiration is compared as time instant, empty and nil lists are the same.
+func EqualOTP(a, b *entity.OTP) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !bytes.Equal(a.Secret, b.Secret) || !bytes.Equal(a.PendingSecret, b.PendingSecret) {
+		return false
+	}
+	if len(a.RecoveryCodeHashes) != len(b.RecoveryCodeHashes) {
+		return false
+	}
+	for i := range a.RecoveryCodeHashes {
+		if a.RecoveryCodeHashes[i] != b.RecoveryCodeHashes[i] {
+			return false
+		}
+	}
+	return a.LastStep == b.LastStep &&
+		a.ChallengeHash == b.ChallengeHash &&
+		a.ChallengeExpiresAt.Equal(b.ChallengeExpiresAt) &&
+		a.ChallengeAttempts == b.ChallengeAttempts
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"keeper/internal/entity"
+)
+
+// AttemptRepository PostgreSQL failed login attempts storage, counters are shared by all server replicas.
+type AttemptRepository struct {
+	db *sql.DB
+}
+
+// NewAttemptRepository construct AttemptRepository.
+func NewAttemptRepository(db *sql.DB) *AttemptRepository {
+	return &AttemptRepository{
+		db: db,
+	}
+}
+
+// GetAttempts return failed attempts counter by key, counter is empty when there were no failures.
+func (r *AttemptRepository) GetAttempts(ctx context.Context, key string) (entity.Attempts, error) {
+	const query = `SELECT failures, last_failure_at FROM login_attempts WHERE key = $1`
+	attempts := entity.Attempts{Key: key}
+	err := r.db.QueryRowContext(ctx, query, key).Scan(&attempts.Failures, &attempts.LastFailureAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return attempts, nil
+		}
+		return entity.Attempts{}, fmt.Errorf("select login attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// ReserveAttempt increment failed attempts counter when allowed accepts it and return counter,
+// counter older than window starts from scratch. Counter row is locked till decision is made.
+func (r *AttemptRepository) ReserveAttempt(ctx context.Context, key string, at time.Time, window time.Duration, allowed func(entity.Attempts) bool) (entity.Attempts, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entity.Attempts{}, false, fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	const insertQuery = `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 0, $2)
+		ON CONFLICT (key) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, insertQuery, key, at); err != nil {
+		return entity.Attempts{}, false, fmt.Errorf("insert login attempts: %w", err)
+	}
+	const selectQuery = `SELECT failures, last_failure_at FROM login_attempts WHERE key = $1 FOR UPDATE`
+	attempts := entity.Attempts{Key: key}
+	if err := tx.QueryRowContext(ctx, selectQuery, key).Scan(&attempts.Failures, &attempts.LastFailureAt); err != nil {
+		return entity.Attempts{}, false, fmt.Errorf("select login attempts: %w", err)
+	}
+	if attempts.Failures <= 0 || at.Sub(attempts.LastFailureAt) > window {
+		attempts = entity.Attempts{Key: key}
+	}
+	if !allowed(attempts) {
+		return attempts, false, nil
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = at
+	const updateQuery = `UPDATE login_attempts SET failures = $2, last_failure_at = $3 WHERE key = $1`
+	if _, err := tx.ExecContext(ctx, updateQuery, key, attempts.Failures, attempts.LastFailureAt); err != nil {
+		return entity.Attempts{}, false, fmt.Errorf("update login attempts: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return entity.Attempts{}, false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return attempts, true, nil
+}
+
+// ReleaseAttempt decrement failed attempts counter, counter with no failures is considered empty.
+func (r *AttemptRepository) ReleaseAttempt(ctx context.Context, key string) error {
+	const query = `UPDATE login_attempts SET failures = GREATEST(failures - 1, 0) WHERE key = $1`
+	if _, err := r.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("update login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// ResetAttempts remove failed attempts counter.
+func (r *AttemptRepository) ResetAttempts(ctx context.Context, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_attempts WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("delete login attempts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,473 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+const itemColumns = `id, user_id, name, type, data, data_key, attributes, metadata, revision, created_at, updated_at`
+
+// ItemRepository PostgreSQL item storage.
+type ItemRepository struct {
+	db *sql.DB
+}
+
+// NewItemRepository construct ItemRepository.
+func NewItemRepository(db *sql.DB) *ItemRepository {
+	return &ItemRepository{
+		db: db,
+	}
+}
+
+// Create store item in storage.
+func (r *ItemRepository) Create(ctx context.Context, item entity.Item) error {
+	metadata, err := marshalMetadata(item.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	const query = `INSERT INTO items (` + itemColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err = tx.ExecContext(ctx, query,
+		item.ID, item.UserID, item.Name, item.Type, item.Data, item.DataKey, item.Attributes, metadata, item.Revision,
+		item.CreatedAt, item.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrItemAlreadyExist
+		}
+		return fmt.Errorf("insert item: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM deleted_items WHERE user_id = $1 AND name = $2`, item.UserID, item.Name)
+	if err != nil {
+		return fmt.Errorf("delete item tombstone: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Update store new version item in storage, previous version is kept in item history.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Update(ctx context.Context, item entity.Item, revision int64) error {
+	metadata, err := marshalMetadata(item.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	id, err := lockItem(ctx, tx, item.UserID, item.Name, revision)
+	if err != nil {
+		return err
+	}
+	const archiveQuery = `INSERT INTO item_versions
+		(item_id, user_id, name, type, data, data_key, attributes, metadata, revision, created_at, updated_at)
+		SELECT ` + itemColumns + ` FROM items WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, archiveQuery, id); err != nil {
+		return fmt.Errorf("insert item version: %w", err)
+	}
+	const updateQuery = `UPDATE items SET type = $2, data = $3, data_key = $4, attributes = $5, metadata = $6,
+		revision = revision + 1, updated_at = $7 WHERE id = $1`
+	_, err = tx.ExecContext(ctx, updateQuery,
+		id, item.Type, item.Data, item.DataKey, item.Attributes, metadata, item.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update item: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Replace store new version item in storage, current version and item history are removed.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Replace(ctx context.Context, item entity.Item, revision int64) error {
+	metadata, err := marshalMetadata(item.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	id, err := lockItem(ctx, tx, item.UserID, item.Name, revision)
+	if err != nil {
+		return err
+	}
+	const updateQuery = `UPDATE items SET type = $2, data = $3, data_key = $4, attributes = $5, metadata = $6,
+		revision = revision + 1, updated_at = $7 WHERE id = $1`
+	_, err = tx.ExecContext(ctx, updateQuery,
+		id, item.Type, item.Data, item.DataKey, item.Attributes, metadata, item.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update item: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM item_versions WHERE item_id = $1`, id); err != nil {
+		return fmt.Errorf("delete item versions: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateDataKey store new item data key and update time, previous item version is not kept as item data is the same.
+// Data keys of previous versions are replaced by not empty version keys, oldest first,
+// versions without data key are removed as they are encrypted directly with replaced vault key.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) UpdateDataKey(ctx context.Context, item entity.Item, versionKeys [][]byte, revision int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	id, err := lockItem(ctx, tx, item.UserID, item.Name, revision)
+	if err != nil {
+		return err
+	}
+	const query = `UPDATE items SET data_key = $2, revision = revision + 1, updated_at = $3 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, id, item.DataKey, item.UpdatedAt); err != nil {
+		return fmt.Errorf("update item data key: %w", err)
+	}
+	if err := updateVersionKeys(ctx, tx, id, versionKeys); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateVersionKeys replace data keys of item previous versions by not empty version keys, oldest first,
+// and remove versions without data key.
+func updateVersionKeys(ctx context.Context, tx *sql.Tx, itemID string, versionKeys [][]byte) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM item_versions WHERE item_id = $1 ORDER BY id`, itemID)
+	if err != nil {
+		return fmt.Errorf("select item versions: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("scan item version: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("select item versions: %w", err)
+	}
+
+	for i := 0; i < len(ids) && i < len(versionKeys); i++ {
+		if len(versionKeys[i]) == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE item_versions SET data_key = $2 WHERE id = $1`, ids[i], versionKeys[i]); err != nil {
+			return fmt.Errorf("update item version data key: %w", err)
+		}
+	}
+	const deleteQuery = `DELETE FROM item_versions WHERE item_id = $1 AND (data_key IS NULL OR length(data_key) = 0)`
+	if _, err := tx.ExecContext(ctx, deleteQuery, itemID); err != nil {
+		return fmt.Errorf("delete item versions: %w", err)
+	}
+	return nil
+}
+
+// GetByUserIDAndName return item from storage by user ID and item name.
+func (r *ItemRepository) GetByUserIDAndName(ctx context.Context, userID string, name string) (entity.Item, error) {
+	const query = `SELECT ` + itemColumns + ` FROM items WHERE user_id = $1 AND name = $2`
+	item, err := scanItem(r.db.QueryRowContext(ctx, query, userID, name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Item{}, repository.ErrItemNotFound
+		}
+		return entity.Item{}, fmt.Errorf("select item: %w", err)
+	}
+
+	return item, nil
+}
+
+// Delete remove item and its history from storage.
+// Stored item revision should match provided revision, zero revision skips the check.
+func (r *ItemRepository) Delete(ctx context.Context, item entity.Item, revision int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	id, err := lockItem(ctx, tx, item.UserID, item.Name, revision)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete item: %w", err)
+	}
+	const tombstoneQuery = `INSERT INTO deleted_items (user_id, name, deleted_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, name) DO UPDATE SET deleted_at = EXCLUDED.deleted_at`
+	if _, err := tx.ExecContext(ctx, tombstoneQuery, item.UserID, item.Name, time.Now()); err != nil {
+		return fmt.Errorf("insert item tombstone: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteByUser remove all user items with their history from storage.
+func (r *ItemRepository) DeleteByUser(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete user items: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM deleted_items WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("delete user item tombstones: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FindByUser return item summaries list from storage for user ID.
+// Items are returned in name order starting after provided name, not positive limit returns all items.
+func (r *ItemRepository) FindByUser(ctx context.Context, userID string, limit int, after string) ([]entity.ItemSummary, error) {
+	const query = `SELECT name, type, data_key, attributes, metadata, revision, created_at, updated_at FROM items
+		WHERE user_id = $1 AND name > $2 ORDER BY name LIMIT $3`
+	var limitArg any
+	if limit > 0 {
+		limitArg = limit
+	}
+	rows, err := r.db.QueryContext(ctx, query, userID, after, limitArg)
+	if err != nil {
+		return []entity.ItemSummary{}, fmt.Errorf("select items list: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer rows.Close()
+
+	list := []entity.ItemSummary{}
+	for rows.Next() {
+		summary, err := scanItemSummary(rows)
+		if err != nil {
+			return []entity.ItemSummary{}, err
+		}
+		list = append(list, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return []entity.ItemSummary{}, fmt.Errorf("select items list: %w", err)
+	}
+
+	return list, nil
+}
+
+// FindByFilter return summaries of user items matching filter, ordered by name.
+// Type, metadata and name prefix or substring conditions are checked by database, glob pattern is checked after.
+func (r *ItemRepository) FindByFilter(ctx context.Context, userID string, filter entity.ItemFilter) ([]entity.ItemSummary, error) {
+	matcher, err := repository.NewItemMatcher(filter)
+	if err != nil {
+		return []entity.ItemSummary{}, err
+	}
+
+	metadata := make([]map[string]string, 0, len(filter.Metadata))
+	for _, m := range filter.Metadata {
+		record := map[string]string{"Key": m.Key}
+		if m.Value != "" {
+			record["Value"] = m.Value
+		}
+		metadata = append(metadata, record)
+	}
+	metadataArg, err := json.Marshal(metadata)
+	if err != nil {
+		return []entity.ItemSummary{}, fmt.Errorf("marshal metadata filter: %w", err)
+	}
+
+	const query = `SELECT name, type, data_key, attributes, metadata, revision, created_at, updated_at FROM items
+		WHERE user_id = $1 AND ($2 = '' OR type = $2) AND metadata @> $3
+			AND starts_with(name, $4) AND strpos(name, $5) > 0
+		ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query, userID, filter.Type, string(metadataArg), matcher.NamePrefix(), matcher.NameSubstring())
+	if err != nil {
+		return []entity.ItemSummary{}, fmt.Errorf("select items by filter: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer rows.Close()
+
+	list := []entity.ItemSummary{}
+	for rows.Next() {
+		summary, err := scanItemSummary(rows)
+		if err != nil {
+			return []entity.ItemSummary{}, err
+		}
+		if matcher.MatchName(summary.Name) {
+			list = append(list, summary)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return []entity.ItemSummary{}, fmt.Errorf("select items by filter: %w", err)
+	}
+
+	return list, nil
+}
+
+// FindVersions return previous versions of item from storage, oldest first.
+func (r *ItemRepository) FindVersions(ctx context.Context, userID string, name string) ([]entity.Item, error) {
+	item, err := r.GetByUserIDAndName(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `SELECT item_id, user_id, name, type, data, data_key, attributes, metadata, revision, created_at, updated_at
+		FROM item_versions WHERE item_id = $1 ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("select item versions: %w", err)
+	}
+
+	return scanItems(rows)
+}
+
+// FindChanges return items updated and names of items deleted after since time.
+func (r *ItemRepository) FindChanges(ctx context.Context, userID string, since time.Time) ([]entity.Item, []string, error) {
+	const query = `SELECT ` + itemColumns + ` FROM items WHERE user_id = $1 AND updated_at > $2`
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("select changed items: %w", err)
+	}
+	items, err := scanItems(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	const deletedQuery = `SELECT name FROM deleted_items WHERE user_id = $1 AND deleted_at > $2`
+	rows, err = r.db.QueryContext(ctx, deletedQuery, userID, since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("select deleted items: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer rows.Close()
+
+	var deleted []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, nil, fmt.Errorf("scan deleted item name: %w", err)
+		}
+		deleted = append(deleted, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("select deleted items: %w", err)
+	}
+
+	return items, deleted, nil
+}
+
+// lockItem lock item row till the end of transaction and check its revision, zero revision skips the check.
+func lockItem(ctx context.Context, tx *sql.Tx, userID string, name string, revision int64) (string, error) {
+	const query = `SELECT id, revision FROM items WHERE user_id = $1 AND name = $2 FOR UPDATE`
+	var id string
+	var current int64
+	if err := tx.QueryRowContext(ctx, query, userID, name).Scan(&id, &current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repository.ErrItemNotFound
+		}
+		return "", fmt.Errorf("select item for update: %w", err)
+	}
+	if revision != 0 && current != revision {
+		return "", repository.ErrItemRevisionMismatch
+	}
+
+	return id, nil
+}
+
+// marshalMetadata encode item metadata for metadata column, no metadata is stored as empty array,
+// so metadata containment condition of item search matches it.
+func marshalMetadata(metadata []entity.Metadata) (string, error) {
+	if metadata == nil {
+		metadata = []entity.Metadata{}
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("marshal item metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItem(row scanner) (entity.Item, error) {
+	var item entity.Item
+	var metadata []byte
+	err := row.Scan(
+		&item.ID, &item.UserID, &item.Name, &item.Type, &item.Data, &item.DataKey, &item.Attributes, &metadata,
+		&item.Revision, &item.CreatedAt, &item.UpdatedAt,
+	)
+	if err != nil {
+		return entity.Item{}, err
+	}
+	if err := json.Unmarshal(metadata, &item.Metadata); err != nil {
+		return entity.Item{}, fmt.Errorf("unmarshal item metadata: %w", err)
+	}
+
+	return item, nil
+}
+
+func scanItemSummary(row scanner) (entity.ItemSummary, error) {
+	var summary entity.ItemSummary
+	var metadata []byte
+	err := row.Scan(
+		&summary.Name, &summary.Type, &summary.DataKey, &summary.Attributes, &metadata, &summary.Revision,
+		&summary.CreatedAt, &summary.UpdatedAt,
+	)
+	if err != nil {
+		return entity.ItemSummary{}, fmt.Errorf("scan item summary: %w", err)
+	}
+	if err := json.Unmarshal(metadata, &summary.Metadata); err != nil {
+		return entity.ItemSummary{}, fmt.Errorf("unmarshal item metadata: %w", err)
+	}
+
+	return summary, nil
+}
+
+func scanItems(rows *sql.Rows) ([]entity.Item, error) {
+	//goland:noinspection GoUnhandledErrorResult
+	defer rows.Close()
+
+	var items []entity.Item
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("select items: %w", err)
+	}
+
+	return items, nil
+}
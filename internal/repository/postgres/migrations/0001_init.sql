@@ -0,0 +1,51 @@
+CREATE TABLE users (
+    id            UUID PRIMARY KEY,
+    login         TEXT        NOT NULL UNIQUE,
+    password_hash TEXT        NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE tokens (
+    id         TEXT PRIMARY KEY,
+    user_login TEXT        NOT NULL REFERENCES users (login) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE items (
+    id         UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    type       TEXT        NOT NULL,
+    data       BYTEA,
+    metadata   JSONB       NOT NULL DEFAULT '[]',
+    revision   BIGINT      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE INDEX items_user_id_updated_at_idx ON items (user_id, updated_at);
+
+CREATE TABLE item_versions (
+    id         BIGSERIAL PRIMARY KEY,
+    item_id    UUID        NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL,
+    name       TEXT        NOT NULL,
+    type       TEXT        NOT NULL,
+    data       BYTEA,
+    metadata   JSONB       NOT NULL DEFAULT '[]',
+    revision   BIGINT      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX item_versions_item_id_idx ON item_versions (item_id, id);
+
+CREATE TABLE deleted_items (
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, name)
+);
+
+CREATE INDEX deleted_items_user_id_deleted_at_idx ON deleted_items (user_id, deleted_at);
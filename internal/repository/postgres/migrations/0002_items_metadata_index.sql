@@ -0,0 +1 @@
+CREATE INDEX items_metadata_idx ON items USING GIN (metadata jsonb_path_ops);
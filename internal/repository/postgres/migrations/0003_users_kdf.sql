@@ -0,0 +1,4 @@
+-- Existing users get empty key derivation parameters, that is legacy version migrated by client on login.
+ALTER TABLE users
+    ADD COLUMN kdf          JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN previous_kdf JSONB;
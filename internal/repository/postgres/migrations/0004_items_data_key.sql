@@ -0,0 +1,5 @@
+ALTER TABLE items
+    ADD COLUMN data_key BYTEA;
+
+ALTER TABLE item_versions
+    ADD COLUMN data_key BYTEA;
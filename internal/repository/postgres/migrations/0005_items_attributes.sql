@@ -0,0 +1,5 @@
+ALTER TABLE items
+    ADD COLUMN attributes BYTEA;
+
+ALTER TABLE item_versions
+    ADD COLUMN attributes BYTEA;
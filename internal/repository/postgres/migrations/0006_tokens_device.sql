@@ -0,0 +1,7 @@
+ALTER TABLE tokens
+    ADD COLUMN device_name    TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN client_version TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN ip             TEXT        NOT NULL DEFAULT '',
+    ADD COLUMN last_used_at   TIMESTAMPTZ;
+
+CREATE INDEX tokens_user_login_idx ON tokens (user_login);
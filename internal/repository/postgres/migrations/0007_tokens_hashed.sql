@@ -0,0 +1,2 @@
+-- Tokens are stored by SHA-256 hex of token now, rows keyed by raw tokens can't be used anymore.
+DELETE FROM tokens WHERE length(id) <> 64;
@@ -0,0 +1,7 @@
+-- Tokens are pairs of access and refresh tokens now, previously issued tokens can't be used anymore.
+DELETE FROM tokens;
+
+ALTER TABLE tokens
+    ADD COLUMN access_hash       TEXT        NOT NULL,
+    ADD COLUMN refresh_hash      TEXT        NOT NULL,
+    ADD COLUMN access_expires_at TIMESTAMPTZ NOT NULL;
@@ -0,0 +1,5 @@
+CREATE TABLE login_attempts (
+    key             TEXT PRIMARY KEY,
+    failures        INTEGER     NOT NULL,
+    last_failure_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE srp_exchanges (
+    id            TEXT PRIMARY KEY,
+    user_login    TEXT        NOT NULL,
+    client_public BYTEA       NOT NULL,
+    server_secret BYTEA       NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX srp_exchanges_expires_at_idx ON srp_exchanges (expires_at);
@@ -0,0 +1,3 @@
+-- Refresh tokens are rotated, hash of replaced refresh token detects its reuse.
+ALTER TABLE tokens
+    ADD COLUMN previous_refresh_hash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Items without metadata were stored with JSON null, it isn't matched by metadata containment of item search.
+UPDATE items SET metadata = '[]' WHERE metadata = 'null';
+UPDATE item_versions SET metadata = '[]' WHERE metadata = 'null';
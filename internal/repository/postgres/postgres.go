@@ -0,0 +1,17 @@
+// Package postgres contains PostgreSQL storage implementation of Keeper repositories.
+package postgres
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const (
+	uniqueViolation = "23505"
+)
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
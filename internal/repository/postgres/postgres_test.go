@@ -0,0 +1,260 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+	pg "keeper/pkg/postgres"
+)
+
+// testDSNEnv is environment variable with DSN of database for storage tests, tests are skipped without it.
+// Database from docker-compose.yml is used by "make test-postgres".
+const testDSNEnv = "KEEPER_TEST_POSTGRES_DSN"
+
+func TestMigrationsOrder(t *testing.T) {
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	// Migrations are applied in file name order, so it should be version order without gaps.
+	for i, file := range files {
+		name := strings.TrimPrefix(file, "migrations/")
+		version, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+		if err != nil {
+			t.Fatalf("migration %s version: %v", name, err)
+		}
+		if version != i+1 {
+			t.Errorf("migration %s has version %d, want %d", name, version, i+1)
+		}
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "unique violation", err: &pq.Error{Code: uniqueViolation}, want: true},
+		{name: "wrapped unique violation", err: fmt.Errorf("insert: %w", &pq.Error{Code: uniqueViolation}), want: true},
+		{name: "foreign key violation", err: &pq.Error{Code: "23503"}, want: false},
+		{name: "other error", err: errors.New("connection refused"), want: false},
+	}
+	for _, tt := range tests {
+		if got := isUniqueViolation(tt.err); got != tt.want {
+			t.Errorf("%s: isUniqueViolation() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// newTestUser connect to migrated test database and create user removed after test.
+func newTestUser(t *testing.T) (*UserRepository, *ItemRepository, entity.User) {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	ctx := context.Background()
+	db, err := pg.NewDB(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		//goland:noinspection GoUnhandledErrorResult
+		db.Close()
+	})
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	users := NewUserRepository(db)
+	user := entity.User{
+		ID:        uuid.NewString(),
+		Login:     "test-" + uuid.NewString(),
+		CreatedAt: time.Now(),
+	}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		//goland:noinspection GoUnhandledErrorResult
+		users.Delete(context.Background(), user.Login)
+	})
+	return users, NewItemRepository(db), user
+}
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	users, _, user := newTestUser(t)
+
+	if err := users.Create(ctx, user); !errors.Is(err, repository.ErrUserAlreadyExist) {
+		t.Errorf("Create() of existing user error = %v, want %v", err, repository.ErrUserAlreadyExist)
+	}
+	kdf := entity.KDF{Version: 1, Salt: []byte("salt"), Time: 3, Memory: 65536, Threads: 4}
+	previousKDF := entity.KDF{}
+	if err := users.UpdateKDF(ctx, user.Login, kdf, &previousKDF); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := users.GetByLogin(ctx, user.Login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ID != user.ID || stored.KDF.Memory != kdf.Memory || string(stored.KDF.Salt) != "salt" || stored.PreviousKDF == nil {
+		t.Errorf("stored user = %+v, want user with updated KDF", stored)
+	}
+	if err := users.UpdatePasswordHash(ctx, "absent-"+user.Login, "hash"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("UpdatePasswordHash() of absent user error = %v, want %v", err, repository.ErrUserNotFound)
+	}
+}
+
+func TestUserRepositorySwapOTP(t *testing.T) {
+	ctx := context.Background()
+	users, _, user := newTestUser(t)
+
+	otp := entity.OTP{
+		Secret:             []byte("secret"),
+		RecoveryCodeHashes: []string{"hash"},
+		LastStep:           1,
+		ChallengeExpiresAt: time.Now(),
+	}
+	if err := users.SwapOTP(ctx, user.Login, nil, &otp); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := users.GetByLogin(ctx, user.Login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repository.EqualOTP(stored.OTP, &otp) {
+		t.Fatalf("stored otp = %+v, want %+v", stored.OTP, otp)
+	}
+
+	used := otp
+	used.LastStep = 2
+	used.RecoveryCodeHashes = []string{}
+	if err := users.SwapOTP(ctx, user.Login, stored.OTP, &used); err != nil {
+		t.Fatal(err)
+	}
+	// The same state can't be changed again by concurrent request.
+	if err := users.SwapOTP(ctx, user.Login, stored.OTP, &used); !errors.Is(err, repository.ErrOTPChanged) {
+		t.Errorf("SwapOTP() of changed state error = %v, want %v", err, repository.ErrOTPChanged)
+	}
+	if err := users.SwapOTP(ctx, "absent-"+user.Login, nil, &otp); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("SwapOTP() of absent user error = %v, want %v", err, repository.ErrUserNotFound)
+	}
+}
+
+func TestItemRepositoryRevisions(t *testing.T) {
+	ctx := context.Background()
+	_, items, user := newTestUser(t)
+
+	now := time.Now()
+	item := entity.Item{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Name:      "note",
+		Type:      "text",
+		Data:      []byte("v1"),
+		Metadata:  []entity.Metadata{{Key: "tag", Value: "work"}},
+		Revision:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := items.Create(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	if err := items.Create(ctx, item); !errors.Is(err, repository.ErrItemAlreadyExist) {
+		t.Errorf("Create() of existing item error = %v, want %v", err, repository.ErrItemAlreadyExist)
+	}
+
+	item.Data = []byte("v2")
+	if err := items.Update(ctx, item, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := items.Update(ctx, item, 1); !errors.Is(err, repository.ErrItemRevisionMismatch) {
+		t.Errorf("Update() with stale revision error = %v, want %v", err, repository.ErrItemRevisionMismatch)
+	}
+	stored, err := items.GetByUserIDAndName(ctx, user.ID, "note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Revision != 2 || string(stored.Data) != "v2" || len(stored.Metadata) != 1 {
+		t.Errorf("stored item = %+v, want revision 2 with v2 data and metadata", stored)
+	}
+	versions, err := items.FindVersions(ctx, user.ID, "note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || string(versions[0].Data) != "v1" {
+		t.Errorf("item versions = %+v, want v1 version", versions)
+	}
+
+	if err := items.Delete(ctx, stored, 1); !errors.Is(err, repository.ErrItemRevisionMismatch) {
+		t.Errorf("Delete() with stale revision error = %v, want %v", err, repository.ErrItemRevisionMismatch)
+	}
+	if err := items.Delete(ctx, stored, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := items.GetByUserIDAndName(ctx, user.ID, "note"); !errors.Is(err, repository.ErrItemNotFound) {
+		t.Errorf("GetByUserIDAndName() of deleted item error = %v, want %v", err, repository.ErrItemNotFound)
+	}
+}
+
+func TestItemRepositoryFindByFilter(t *testing.T) {
+	ctx := context.Background()
+	_, items, user := newTestUser(t)
+	for _, item := range []entity.Item{
+		{Name: "home/bank", Type: "card", Metadata: []entity.Metadata{{Key: "bank", Value: "city"}}},
+		{Name: "home/note", Type: "text"},
+		{Name: "work/acme", Type: "card", Metadata: []entity.Metadata{{Key: "bank", Value: "acme"}}},
+		{Name: "work/pay", Type: "card"},
+	} {
+		item.ID = uuid.NewString()
+		item.UserID = user.ID
+		item.Revision = 1
+		if err := items.Create(ctx, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter entity.ItemFilter
+		want   []string
+	}{
+		{name: "substring", filter: entity.ItemFilter{Name: "note"}, want: []string{"home/note"}},
+		{name: "glob", filter: entity.ItemFilter{Name: "work/*a*"}, want: []string{"work/acme", "work/pay"}},
+		{name: "type", filter: entity.ItemFilter{Type: "card"}, want: []string{"home/bank", "work/acme", "work/pay"}},
+		{name: "metadata value", filter: entity.ItemFilter{Metadata: []entity.Metadata{{Key: "bank", Value: "acme"}}}, want: []string{"work/acme"}},
+		{name: "metadata key", filter: entity.ItemFilter{Metadata: []entity.Metadata{{Key: "bank"}}}, want: []string{"home/bank", "work/acme"}},
+	}
+	for _, tt := range tests {
+		list, err := items.FindByFilter(ctx, user.ID, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := make([]string, 0, len(list))
+		for _, summary := range list {
+			got = append(got, summary.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: FindByFilter() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
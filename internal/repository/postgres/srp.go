@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// SRPExchangeRepository PostgreSQL started SRP-6a login exchanges storage, exchanges are shared by all server replicas.
+type SRPExchangeRepository struct {
+	db *sql.DB
+}
+
+// NewSRPExchangeRepository construct SRPExchangeRepository.
+func NewSRPExchangeRepository(db *sql.DB) *SRPExchangeRepository {
+	return &SRPExchangeRepository{
+		db: db,
+	}
+}
+
+// CreateExchange store started exchange, expired exchanges are removed.
+func (r *SRPExchangeRepository) CreateExchange(ctx context.Context, exchange entity.SRPExchange) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM srp_exchanges WHERE expires_at < now()`); err != nil {
+		return fmt.Errorf("delete expired srp exchanges: %w", err)
+	}
+
+	const query = `INSERT INTO srp_exchanges (id, user_login, client_public, server_secret, expires_at) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.db.ExecContext(ctx, query, exchange.ID, exchange.UserLogin, exchange.ClientPublic, exchange.ServerSecret, exchange.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("insert srp exchange: %w", err)
+	}
+
+	return nil
+}
+
+// TakeExchange remove exchange and return it.
+func (r *SRPExchangeRepository) TakeExchange(ctx context.Context, id string) (entity.SRPExchange, error) {
+	const query = `DELETE FROM srp_exchanges WHERE id = $1 RETURNING id, user_login, client_public, server_secret, expires_at`
+	var exchange entity.SRPExchange
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&exchange.ID,
+		&exchange.UserLogin,
+		&exchange.ClientPublic,
+		&exchange.ServerSecret,
+		&exchange.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.SRPExchange{}, repository.ErrSRPExchangeNotFound
+		}
+		return entity.SRPExchange{}, fmt.Errorf("delete srp exchange: %w", err)
+	}
+
+	return exchange, nil
+}
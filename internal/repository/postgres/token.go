@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// TokenRepository PostgreSQL token storage.
+type TokenRepository struct {
+	db       *sql.DB
+	lifetime time.Duration
+}
+
+// NewTokenRepository construct TokenRepository.
+func NewTokenRepository(db *sql.DB, lifetime time.Duration) *TokenRepository {
+	return &TokenRepository{
+		db:       db,
+		lifetime: lifetime,
+	}
+}
+
+// CreateToken store user token.
+func (r *TokenRepository) CreateToken(ctx context.Context, token entity.Token) error {
+	const query = `INSERT INTO tokens (` + tokenColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserLogin,
+		token.AccessHash,
+		token.RefreshHash,
+		token.PreviousRefreshHash,
+		token.Device.Name,
+		token.Device.ClientVersion,
+		token.Device.IP,
+		token.CreatedAt,
+		token.LastUsedAt,
+		token.AccessExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert token: %w", err)
+	}
+
+	return nil
+}
+
+// GetToken return user token by token ID.
+func (r *TokenRepository) GetToken(ctx context.Context, id string) (entity.Token, error) {
+	const query = `SELECT ` + tokenColumns + ` FROM tokens WHERE id = $1`
+	token, err := scanToken(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Token{}, repository.ErrTokenNotFound
+		}
+		return entity.Token{}, fmt.Errorf("select token: %w", err)
+	}
+
+	if time.Since(token.CreatedAt) > r.lifetime {
+		if _, err := r.db.ExecContext(ctx, `DELETE FROM tokens WHERE id = $1`, id); err != nil {
+			return entity.Token{}, fmt.Errorf("delete token: %w", err)
+		}
+		return entity.Token{}, repository.ErrTokenExpired
+	}
+
+	return token, nil
+}
+
+// UpdateTokenUsage store time and client IP of user token last usage.
+func (r *TokenRepository) UpdateTokenUsage(ctx context.Context, id string, ip string, usedAt time.Time) error {
+	const query = `UPDATE tokens SET ip = $2, last_used_at = $3 WHERE id = $1`
+	res, err := r.db.ExecContext(ctx, query, id, ip, usedAt)
+	if err != nil {
+		return fmt.Errorf("update token usage: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update token usage: %w", err)
+	}
+	if n == 0 {
+		return repository.ErrTokenNotFound
+	}
+	return nil
+}
+
+// RotateToken replace access and refresh token hashes and access expiration time of user token
+// if refresh token hash is still refreshHash, replaced hash is kept for reuse detection.
+// Token which is missed or already rotated is reported as rotated.
+func (r *TokenRepository) RotateToken(ctx context.Context, id string, refreshHash string, accessHash string, newRefreshHash string, expiresAt time.Time) error {
+	const query = `UPDATE tokens SET access_hash = $3, refresh_hash = $4, previous_refresh_hash = $2, access_expires_at = $5
+		WHERE id = $1 AND refresh_hash = $2`
+	res, err := r.db.ExecContext(ctx, query, id, refreshHash, accessHash, newRefreshHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("rotate token: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rotate token: %w", err)
+	}
+	if n == 0 {
+		return repository.ErrTokenRotated
+	}
+	return nil
+}
+
+// FindTokensByUser return not expired user tokens.
+func (r *TokenRepository) FindTokensByUser(ctx context.Context, login string) ([]entity.Token, error) {
+	const query = `SELECT ` + tokenColumns + ` FROM tokens WHERE user_login = $1 AND created_at > $2 ORDER BY created_at`
+	rows, err := r.db.QueryContext(ctx, query, login, time.Now().Add(-r.lifetime))
+	if err != nil {
+		return nil, fmt.Errorf("select user tokens: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer rows.Close()
+
+	var tokens []entity.Token
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("select user tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// DeleteToken remove user token, removing not existed token is not an error.
+func (r *TokenRepository) DeleteToken(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM tokens WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete token: %w", err)
+	}
+	return nil
+}
+
+// DeleteTokensByUser remove all tokens of user.
+func (r *TokenRepository) DeleteTokensByUser(ctx context.Context, login string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM tokens WHERE user_login = $1`, login); err != nil {
+		return fmt.Errorf("delete user tokens: %w", err)
+	}
+	return nil
+}
+
+const tokenColumns = `id, user_login, access_hash, refresh_hash, previous_refresh_hash, device_name, client_version, ip,
+	created_at, last_used_at, access_expires_at`
+
+func scanToken(row scanner) (entity.Token, error) {
+	var token entity.Token
+	var lastUsedAt sql.NullTime
+	err := row.Scan(
+		&token.ID,
+		&token.UserLogin,
+		&token.AccessHash,
+		&token.RefreshHash,
+		&token.PreviousRefreshHash,
+		&token.Device.Name,
+		&token.Device.ClientVersion,
+		&token.Device.IP,
+		&token.CreatedAt,
+		&lastUsedAt,
+		&token.AccessExpiresAt,
+	)
+	if err != nil {
+		return entity.Token{}, err
+	}
+	// Tokens issued before usage tracking have no last usage time.
+	token.LastUsedAt = token.CreatedAt
+	if lastUsedAt.Valid {
+		token.LastUsedAt = lastUsedAt.Time
+	}
+	return token, nil
+}
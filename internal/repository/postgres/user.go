@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+// UserRepository PostgreSQL user storage.
+type UserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository construct UserRepository.
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+// Create store user entity in storage.
+func (r *UserRepository) Create(ctx context.Context, user entity.User) error {
+	kdf, previousKDF, err := marshalUserKDF(user)
+	if err != nil {
+		return err
+	}
+	otp, err := marshalUserOTP(user)
+	if err != nil {
+		return err
+	}
+	srp, err := marshalUserSRP(user)
+	if err != nil {
+		return err
+	}
+
+	const query = `INSERT INTO users (id, login, password_hash, kdf, previous_kdf, otp, srp, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err = r.db.ExecContext(ctx, query, user.ID, user.Login, user.PasswordHash, kdf, previousKDF, otp, srp, user.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrUserAlreadyExist
+		}
+		return fmt.Errorf("insert user: %w", err)
+	}
+
+	return nil
+}
+
+// GetByLogin return user by login from storage.
+func (r *UserRepository) GetByLogin(ctx context.Context, login string) (entity.User, error) {
+	const query = `SELECT id, login, password_hash, kdf, previous_kdf, otp, srp, created_at FROM users WHERE login = $1`
+	var user entity.User
+	var kdf []byte
+	var previousKDF []byte
+	var otp []byte
+	var srp []byte
+	err := r.db.QueryRowContext(ctx, query, login).Scan(&user.ID, &user.Login, &user.PasswordHash, &kdf, &previousKDF, &otp, &srp, &user.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.User{}, repository.ErrUserNotFound
+		}
+		return entity.User{}, fmt.Errorf("select user: %w", err)
+	}
+	if err := json.Unmarshal(kdf, &user.KDF); err != nil {
+		return entity.User{}, fmt.Errorf("unmarshal user kdf: %w", err)
+	}
+	if previousKDF != nil {
+		if err := json.Unmarshal(previousKDF, &user.PreviousKDF); err != nil {
+			return entity.User{}, fmt.Errorf("unmarshal user previous kdf: %w", err)
+		}
+	}
+	if otp != nil {
+		if err := json.Unmarshal(otp, &user.OTP); err != nil {
+			return entity.User{}, fmt.Errorf("unmarshal user otp: %w", err)
+		}
+	}
+	if srp != nil {
+		if err := json.Unmarshal(srp, &user.SRP); err != nil {
+			return entity.User{}, fmt.Errorf("unmarshal user srp: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// UpdatePasswordHash replace stored user password hash.
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, login string, passwordHash string) error {
+	return r.update(ctx, `UPDATE users SET password_hash = $2 WHERE login = $1`, login, passwordHash)
+}
+
+// UpdateKDF replace stored user vault key derivation parameters.
+func (r *UserRepository) UpdateKDF(ctx context.Context, login string, kdf entity.KDF, previousKDF *entity.KDF) error {
+	kdfData, previousKDFData, err := marshalUserKDF(entity.User{KDF: kdf, PreviousKDF: previousKDF})
+	if err != nil {
+		return err
+	}
+	return r.update(ctx, `UPDATE users SET kdf = $2, previous_kdf = $3 WHERE login = $1`, login, kdfData, previousKDFData)
+}
+
+// SwapOTP replace stored user two-factor authentication state only if it equals old one,
+// otherwise repository.ErrOTPChanged is returned. User row is locked till decision is made.
+func (r *UserRepository) SwapOTP(ctx context.Context, login string, old *entity.OTP, otp *entity.OTP) error {
+	otpData, err := marshalUserOTP(entity.User{OTP: otp})
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	var storedData []byte
+	err = tx.QueryRowContext(ctx, `SELECT otp FROM users WHERE login = $1 FOR UPDATE`, login).Scan(&storedData)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrUserNotFound
+		}
+		return fmt.Errorf("select user otp: %w", err)
+	}
+	var stored *entity.OTP
+	if storedData != nil {
+		if err := json.Unmarshal(storedData, &stored); err != nil {
+			return fmt.Errorf("unmarshal user otp: %w", err)
+		}
+	}
+	if !repository.EqualOTP(stored, old) {
+		return repository.ErrOTPChanged
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET otp = $2 WHERE login = $1`, login, otpData); err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSRP replace stored user SRP verifier and clear password hash.
+func (r *UserRepository) UpdateSRP(ctx context.Context, login string, srp *entity.SRP) error {
+	srpData, err := marshalUserSRP(entity.User{SRP: srp})
+	if err != nil {
+		return err
+	}
+	return r.update(ctx, `UPDATE users SET password_hash = '', srp = $2 WHERE login = $1`, login, srpData)
+}
+
+// update execute update query of user columns, first query argument is user login.
+func (r *UserRepository) update(ctx context.Context, query string, args ...any) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	if affected == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// marshalUserKDF return user key derivation parameters as JSON query arguments, absent previous parameters are NULL.
+func marshalUserKDF(user entity.User) (string, sql.NullString, error) {
+	kdf, err := json.Marshal(user.KDF)
+	if err != nil {
+		return "", sql.NullString{}, fmt.Errorf("marshal user kdf: %w", err)
+	}
+	var previousKDF sql.NullString
+	if user.PreviousKDF != nil {
+		data, err := json.Marshal(user.PreviousKDF)
+		if err != nil {
+			return "", sql.NullString{}, fmt.Errorf("marshal user previous kdf: %w", err)
+		}
+		previousKDF = sql.NullString{String: string(data), Valid: true}
+	}
+	return string(kdf), previousKDF, nil
+}
+
+// marshalUserOTP return user two-factor authentication state as JSON query argument, absent state is NULL.
+func marshalUserOTP(user entity.User) (sql.NullString, error) {
+	if user.OTP == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(user.OTP)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshal user otp: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// Delete remove user entity from storage, user tokens and items are removed by foreign keys cascade.
+func (r *UserRepository) Delete(ctx context.Context, login string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE login = $1`, login)
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	if affected == 0 {
+		return repository.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// marshalUserSRP return user SRP verifier as JSON query argument, absent verifier is NULL.
+func marshalUserSRP(user entity.User) (sql.NullString, error) {
+	if user.SRP == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(user.SRP)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("marshal user srp: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
@@ -7,10 +7,16 @@ import (
 var (
 	ErrUserAlreadyExist = errors.New("user already exist")
 	ErrUserNotFound     = errors.New("user not found")
+	ErrOTPChanged       = errors.New("user otp changed")
 
 	ErrTokenNotFound = errors.New("token not found")
 	ErrTokenExpired  = errors.New("token expired")
+	ErrTokenRotated  = errors.New("token rotated")
+
+	ErrSRPExchangeNotFound = errors.New("srp exchange not found")
 
 	ErrItemAlreadyExist = errors.New("item already exist")
 	ErrItemNotFound     = errors.New("item not found")
+
+	ErrItemRevisionMismatch = errors.New("item revision mismatch")
 )
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+var (
+	ErrCurrentPasswordInvalid = errors.New("current password is invalid")
+)
+
+// ChangePassword replace user password after checking current one, user sessions except session
+// of access token are revoked. Wrong current passwords are counted as failed login attempts.
+// Users with zero-knowledge login change password by SetSRPVerifier.
+func (s *AuthService) ChangePassword(ctx context.Context, login string, token string, oldPassword string, newPassword string, ip string) error {
+	if len(newPassword) < passwordMinLength {
+		return FieldErrors{{
+			Field: "new_password",
+			Error: fmt.Sprintf("length should be greater or equal %d", passwordMinLength),
+		}}
+	}
+	if _, err := s.verifyCredentials(ctx, login, oldPassword, SRPProof{}, ip); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordHasher.Generate(newPassword)
+	if err != nil {
+		return fmt.Errorf("password hash generate: %w", err)
+	}
+	if err := s.userRepository.UpdatePasswordHash(ctx, login, passwordHash); err != nil {
+		return err
+	}
+	return s.revokeOtherSessions(ctx, login, token)
+}
+
+// DeleteAccount remove user with all tokens and items after checking password or proof of started SRP exchange
+// for users with zero-knowledge login. Tokens are removed first, so other sessions can't change items while they are deleted.
+func (s *AuthService) DeleteAccount(ctx context.Context, login string, password string, proof SRPProof, ip string) error {
+	user, err := s.verifyCredentials(ctx, login, password, proof, ip)
+	if err != nil {
+		return err
+	}
+
+	if err := s.tokenRepository.DeleteTokensByUser(ctx, login); err != nil {
+		return err
+	}
+	if err := s.itemRepository.DeleteByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.userRepository.Delete(ctx, login)
+}
+
+// verifyCredentials return user when password matches, users with zero-knowledge login are checked by proof
+// of started SRP exchange instead. It is limited like login.
+func (s *AuthService) verifyCredentials(ctx context.Context, login string, password string, proof SRPProof, ip string) (entity.User, error) {
+	if err := s.reserveAttempt(ctx, login, ip); err != nil {
+		return entity.User{}, err
+	}
+	user, err := s.checkCredentials(ctx, login, password, proof)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if err := s.resetAttempts(ctx, login, ip); err != nil {
+		return entity.User{}, err
+	}
+	return user, nil
+}
+
+// checkCredentials return user when password or proof of started SRP exchange matches,
+// attempt should be reserved by caller.
+func (s *AuthService) checkCredentials(ctx context.Context, login string, password string, proof SRPProof) (entity.User, error) {
+	user, err := s.userRepository.GetByLogin(ctx, login)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if user.SRP != nil {
+		if len(proof.Proof) == 0 {
+			return entity.User{}, ErrSRPProofRequired
+		}
+		serverProof, err := s.checkSRPProof(ctx, login, user.SRP, proof)
+		if err != nil {
+			return entity.User{}, err
+		}
+		if serverProof == nil {
+			return entity.User{}, ErrCurrentPasswordInvalid
+		}
+	} else if !s.passwordHasher.Check(password, user.PasswordHash) {
+		return entity.User{}, ErrCurrentPasswordInvalid
+	}
+	return user, nil
+}
+
+// revokeOtherSessions revoke user tokens except token of current session.
+func (s *AuthService) revokeOtherSessions(ctx context.Context, login string, token string) error {
+	tokens, err := s.tokenRepository.FindTokensByUser(ctx, login)
+	if err != nil {
+		return err
+	}
+	current := tokenStoredID(token)
+	for _, t := range tokens {
+		if t.ID == current {
+			continue
+		}
+		if err := s.tokenRepository.DeleteToken(ctx, t.ID); err != nil && !errors.Is(err, repository.ErrTokenNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"keeper/internal/repository"
+	"keeper/internal/repository/memory"
+)
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	s, tokens := newTestAuthService(memory.NewTokenRepository(time.Hour))
+	current, err := s.Register(ctx, "user", "password", Device{Name: "laptop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.Auth(ctx, "user", "password", Device{Name: "phone"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fieldErrors FieldErrors
+	if err := s.ChangePassword(ctx, "user", current.Token, "password", "short", ""); !errors.As(err, &fieldErrors) {
+		t.Errorf("ChangePassword() to short password error = %v, want field error", err)
+	}
+	if err := s.ChangePassword(ctx, "user", current.Token, "wrong password", "new password", ""); !errors.Is(err, ErrCurrentPasswordInvalid) {
+		t.Errorf("ChangePassword() with wrong password error = %v, want %v", err, ErrCurrentPasswordInvalid)
+	}
+	if _, err := tokens.GetUser(ctx, other.Token, ""); err != nil {
+		t.Fatalf("session is revoked by failed password change: %v", err)
+	}
+
+	if err := s.ChangePassword(ctx, "user", current.Token, "password", "new password", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.GetUser(ctx, current.Token, ""); err != nil {
+		t.Errorf("current session is revoked by password change: %v", err)
+	}
+	if _, err := tokens.GetUser(ctx, other.Token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+		t.Errorf("other session token error = %v, want %v", err, repository.ErrTokenNotFound)
+	}
+	if _, err := s.Auth(ctx, "user", "password", Device{}); !errors.Is(err, ErrUserInvalidPassword) {
+		t.Errorf("Auth() with old password error = %v, want %v", err, ErrUserInvalidPassword)
+	}
+	if _, err := s.Auth(ctx, "user", "new password", Device{}); err != nil {
+		t.Errorf("Auth() with new password error = %v", err)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	for _, tt := range itemRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepository := memory.NewUserRepository()
+			tokenRepository := memory.NewTokenRepository(time.Hour)
+			itemRepository := tt.repository(t)
+			s := NewAuthService(
+				&UuidGenerator{},
+				&BCryptPasswordHasher{Cost: 4},
+				userRepository,
+				tokenRepository,
+				itemRepository,
+				memory.NewAttemptRepository(),
+				memory.NewSRPExchangeRepository(),
+				[]byte("secret"),
+				time.Hour,
+			)
+			tokens := NewTokenService(userRepository, tokenRepository)
+			items := NewItemService(&UuidGenerator{}, itemRepository)
+
+			ids := map[string]string{}
+			results := map[string]AuthResult{}
+			for _, login := range []string{"user", "other"} {
+				res, err := s.Register(ctx, login, "password", Device{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				user, err := tokens.GetUser(ctx, res.Token, "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				item := Item{Name: "note", Type: "text", Data: []byte(login)}
+				if err := items.Create(ctx, user.ID, item); err != nil {
+					t.Fatal(err)
+				}
+				if err := items.Update(ctx, user.ID, item, 0); err != nil {
+					t.Fatal(err)
+				}
+				ids[login], results[login] = user.ID, res
+			}
+
+			if err := s.DeleteAccount(ctx, "user", "wrong password", SRPProof{}, ""); !errors.Is(err, ErrCurrentPasswordInvalid) {
+				t.Errorf("DeleteAccount() with wrong password error = %v, want %v", err, ErrCurrentPasswordInvalid)
+			}
+			if err := s.DeleteAccount(ctx, "user", "password", SRPProof{}, ""); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := userRepository.GetByLogin(ctx, "user"); !errors.Is(err, repository.ErrUserNotFound) {
+				t.Errorf("deleted user error = %v, want %v", err, repository.ErrUserNotFound)
+			}
+			if _, err := tokens.GetUser(ctx, results["user"].Token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Errorf("deleted user token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if _, err := items.Get(ctx, ids["user"], "note"); !errors.Is(err, repository.ErrItemNotFound) {
+				t.Errorf("deleted user item error = %v, want %v", err, repository.ErrItemNotFound)
+			}
+			if list, _, err := items.List(ctx, ids["user"], 0, ""); err != nil || len(list) != 0 {
+				t.Errorf("deleted user items = %+v, %v, want none", list, err)
+			}
+
+			if _, err := tokens.GetUser(ctx, results["other"].Token, ""); err != nil {
+				t.Errorf("other user token error = %v", err)
+			}
+			if versions, err := items.History(ctx, ids["other"], "note"); err != nil || len(versions) != 1 {
+				t.Errorf("other user item history = %+v, %v, want one version", versions, err)
+			}
+			// Login of deleted account is free again.
+			if _, err := s.Register(ctx, "user", "password", Device{}); err != nil {
+				t.Errorf("Register() with deleted login error = %v", err)
+			}
+		})
+	}
+}
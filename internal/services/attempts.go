@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"keeper/internal/entity"
+)
+
+var (
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+	// loginAttemptPolicy limits guessing password or one-time password of one user login.
+	loginAttemptPolicy = attemptPolicy{
+		prefix:    "login:",
+		free:      5,
+		baseDelay: time.Second,
+		maxDelay:  15 * time.Minute,
+		window:    24 * time.Hour,
+	}
+	// ipAttemptPolicy limits login attempts from one client IP to any logins,
+	// it is looser since many users can share IP behind NAT.
+	ipAttemptPolicy = attemptPolicy{
+		prefix:    "ip:",
+		free:      20,
+		baseDelay: time.Second,
+		maxDelay:  15 * time.Minute,
+		window:    24 * time.Hour,
+	}
+)
+
+// AttemptsError is returned when login is refused till failed attempts delay passes.
+type AttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AttemptsError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap return ErrTooManyAttempts, so errors.Is matches AttemptsError.
+func (e *AttemptsError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// attemptPolicy describe exponential backoff of failed attempts counter. First free failures have no delay,
+// then delay doubles with each failure starting from baseDelay up to maxDelay, which is temporary lockout.
+// Counter is forgotten when there were no failures during window.
+type attemptPolicy struct {
+	prefix    string
+	free      int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	window    time.Duration
+}
+
+// delay return time next attempt is refused after last failure.
+func (p attemptPolicy) delay(failures int) time.Duration {
+	if failures < p.free {
+		return 0
+	}
+	n := failures - p.free
+	if n >= 32 {
+		return p.maxDelay
+	}
+	d := p.baseDelay << n
+	if d <= 0 || d > p.maxDelay {
+		return p.maxDelay
+	}
+	return d
+}
+
+// wait return time left till next attempt is allowed, counter older than window is not considered.
+func (p attemptPolicy) wait(attempts entity.Attempts, now time.Time) time.Duration {
+	if attempts.Failures == 0 || now.Sub(attempts.LastFailureAt) > p.window {
+		return 0
+	}
+	return attempts.LastFailureAt.Add(p.delay(attempts.Failures)).Sub(now)
+}
+
+// checkAttempts refuse login when failed attempts delay of login or client IP isn't passed yet.
+// It doesn't count attempt, so it is used only before steps which don't check credentials.
+func (s *AuthService) checkAttempts(ctx context.Context, login string, ip string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, k := range attemptKeys(login, ip) {
+		attempts, err := s.attemptRepository.GetAttempts(ctx, k.key)
+		if err != nil {
+			return err
+		}
+		if wait := k.policy.wait(attempts, now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return &AttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// reserveAttempt count attempt of login from client IP as failed before credentials are checked,
+// so parallel attempts can't pass the limit together, and refuse it when failed attempts delay isn't passed yet.
+// It is called before checking credentials, so refused attempts don't spend password hashing time.
+// Right credentials release reserved attempt by releaseAttempt or resetAttempts.
+func (s *AuthService) reserveAttempt(ctx context.Context, login string, ip string) error {
+	now := time.Now()
+	var reserved []attemptKey
+	var retryAfter time.Duration
+	for _, k := range attemptKeys(login, ip) {
+		policy := k.policy
+		attempts, ok, err := s.attemptRepository.ReserveAttempt(ctx, k.key, now, policy.window, func(attempts entity.Attempts) bool {
+			return policy.wait(attempts, now) <= 0
+		})
+		if err != nil {
+			return fmt.Errorf("login attempt reserve: %w", err)
+		}
+		if ok {
+			reserved = append(reserved, k)
+			continue
+		}
+		if wait := policy.wait(attempts, now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		for _, k := range reserved {
+			if err := s.attemptRepository.ReleaseAttempt(ctx, k.key); err != nil {
+				return fmt.Errorf("login attempt release: %w", err)
+			}
+		}
+		return &AttemptsError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// releaseAttempt revert attempt reserved by reserveAttempt when credentials are right,
+// but login is not finished yet.
+func (s *AuthService) releaseAttempt(ctx context.Context, login string, ip string) error {
+	for _, k := range attemptKeys(login, ip) {
+		if err := s.attemptRepository.ReleaseAttempt(ctx, k.key); err != nil {
+			return fmt.Errorf("login attempt release: %w", err)
+		}
+	}
+	return nil
+}
+
+// resetAttempts forget failed attempts of login and release attempt reserved for client IP after successful login.
+// Client IP counter is kept, otherwise attacker could reset it by logging in to own account.
+func (s *AuthService) resetAttempts(ctx context.Context, login string, ip string) error {
+	if err := s.attemptRepository.ResetAttempts(ctx, loginAttemptPolicy.prefix+login); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	if err := s.attemptRepository.ReleaseAttempt(ctx, ipAttemptPolicy.prefix+ip); err != nil {
+		return fmt.Errorf("login attempt release: %w", err)
+	}
+	return nil
+}
+
+type attemptKey struct {
+	key    string
+	policy attemptPolicy
+}
+
+func attemptKeys(login string, ip string) []attemptKey {
+	keys := []attemptKey{{key: loginAttemptPolicy.prefix + login, policy: loginAttemptPolicy}}
+	if ip != "" {
+		keys = append(keys, attemptKey{key: ipAttemptPolicy.prefix + ip, policy: ipAttemptPolicy})
+	}
+	return keys
+}
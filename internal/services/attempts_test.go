@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository/file"
+	"keeper/internal/repository/memory"
+)
+
+func TestAttemptPolicyDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 4, want: 0},
+		{failures: 5, want: time.Second},
+		{failures: 6, want: 2 * time.Second},
+		{failures: 10, want: 32 * time.Second},
+		{failures: 15, want: 15 * time.Minute},
+		{failures: 100, want: 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := loginAttemptPolicy.delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestAttemptPolicyWait(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		attempts entity.Attempts
+		want     time.Duration
+	}{
+		{
+			name: "no failures",
+			want: 0,
+		},
+		{
+			name:     "free failures",
+			attempts: entity.Attempts{Failures: 4, LastFailureAt: now},
+			want:     0,
+		},
+		{
+			name:     "delay not passed",
+			attempts: entity.Attempts{Failures: 6, LastFailureAt: now.Add(-time.Second)},
+			want:     time.Second,
+		},
+		{
+			name:     "delay passed",
+			attempts: entity.Attempts{Failures: 6, LastFailureAt: now.Add(-3 * time.Second)},
+			want:     -time.Second,
+		},
+		{
+			name:     "counter out of window",
+			attempts: entity.Attempts{Failures: 100, LastFailureAt: now.Add(-25 * time.Hour)},
+			want:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loginAttemptPolicy.wait(tt.attempts, now); got != tt.want {
+				t.Errorf("wait() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthParallelAttempts(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository func(t *testing.T) AttemptRepository
+	}{
+		{
+			name: "memory",
+			repository: func(t *testing.T) AttemptRepository {
+				return memory.NewAttemptRepository()
+			},
+		},
+		{
+			name: "file",
+			repository: func(t *testing.T) AttemptRepository {
+				return file.NewAttemptRepository(newTestDB(t))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewAuthService(
+				&UuidGenerator{},
+				&BCryptPasswordHasher{Cost: 4},
+				memory.NewUserRepository(),
+				memory.NewTokenRepository(time.Hour),
+				memory.NewItemRepository(),
+				tt.repository(t),
+				memory.NewSRPExchangeRepository(),
+				[]byte("secret"),
+				time.Hour,
+			)
+			if _, err := s.Register(ctx, "user", "password", Device{}); err != nil {
+				t.Fatal(err)
+			}
+
+			const parallel = 50
+			errs := make(chan error, parallel)
+			var wg sync.WaitGroup
+			for i := 0; i < parallel; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := s.Auth(ctx, "user", "wrong password", Device{IP: "192.0.2.1"})
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			var invalid, refused int
+			for err := range errs {
+				switch {
+				case errors.Is(err, ErrUserInvalidPassword):
+					invalid++
+				case errors.Is(err, ErrTooManyAttempts):
+					refused++
+				default:
+					t.Fatalf("unexpected error %v", err)
+				}
+			}
+			if invalid != loginAttemptPolicy.free || refused != parallel-loginAttemptPolicy.free {
+				t.Errorf("got %d invalid password and %d refused attempts, want %d and %d",
+					invalid, refused, loginAttemptPolicy.free, parallel-loginAttemptPolicy.free)
+			}
+		})
+	}
+}
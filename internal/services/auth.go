@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"keeper/internal/entity"
+	"keeper/internal/repository"
 )
 
 const (
@@ -16,51 +18,111 @@ const (
 
 var (
 	ErrUserInvalidPassword = errors.New("invalid user password")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrRefreshTokenReused  = errors.New("refresh token reused, session revoked")
 )
 
 // AuthService implement logic for working with users.
 type AuthService struct {
-	idGenerator     IdGenerator
-	passwordHasher  PasswordHasher
-	userRepository  UserRepository
-	tokenRepository TokenRepository
+	idGenerator         IdGenerator
+	passwordHasher      PasswordHasher
+	userRepository      UserRepository
+	tokenRepository     TokenRepository
+	itemRepository      ItemRepository
+	attemptRepository   AttemptRepository
+	srpRepository       SRPExchangeRepository
+	srpSecret           []byte
+	accessTokenLifetime time.Duration
 }
 
 // NewAuthService construct new AuthService.
+// Access tokens expire after accessTokenLifetime, refresh tokens live as long as token repository keeps tokens.
+// Item repository is used for removing items of deleted accounts, failed login attempts are counted in attemptRepository.
+// Started zero-knowledge logins are kept in srpRepository, srpSecret derives fake challenges for unknown logins.
 func NewAuthService(
 	idGenerator IdGenerator,
 	passwordHasher PasswordHasher,
 	userRepository UserRepository,
 	tokenRepository TokenRepository,
+	itemRepository ItemRepository,
+	attemptRepository AttemptRepository,
+	srpRepository SRPExchangeRepository,
+	srpSecret []byte,
+	accessTokenLifetime time.Duration,
 ) *AuthService {
 	return &AuthService{
-		idGenerator:     idGenerator,
-		passwordHasher:  passwordHasher,
-		userRepository:  userRepository,
-		tokenRepository: tokenRepository,
+		idGenerator:         idGenerator,
+		passwordHasher:      passwordHasher,
+		userRepository:      userRepository,
+		tokenRepository:     tokenRepository,
+		itemRepository:      itemRepository,
+		attemptRepository:   attemptRepository,
+		srpRepository:       srpRepository,
+		srpSecret:           srpSecret,
+		accessTokenLifetime: accessTokenLifetime,
 	}
 }
 
-// Auth check login and password and return new user token.
-func (s *AuthService) Auth(ctx context.Context, login string, password string) (string, error) {
+// Auth check login and password and return new user access and refresh tokens issued to device
+// with user vault key derivation parameters. Users with two-factor authentication get only login challenge
+// for VerifyOTP call instead. Outdated password hash is upgraded to current hasher settings.
+// Login is refused with AttemptsError after too many failures from login or device IP,
+// every attempt is counted as failed till password is checked.
+func (s *AuthService) Auth(ctx context.Context, login string, password string, device Device) (AuthResult, error) {
+	if err := s.reserveAttempt(ctx, login, device.IP); err != nil {
+		return AuthResult{}, err
+	}
 	user, err := s.userRepository.GetByLogin(ctx, login)
 	if err != nil {
-		return "", err
+		return AuthResult{}, err
 	}
-
+	// Accounts with zero-knowledge login have no password hash and fail like wrong password.
 	if !s.passwordHasher.Check(password, user.PasswordHash) {
-		return "", ErrUserInvalidPassword
+		return AuthResult{}, ErrUserInvalidPassword
+	}
+	if user, err = s.rehashPassword(ctx, user, password); err != nil {
+		return AuthResult{}, err
+	}
+	if user.OTP != nil && user.OTP.Secret != nil {
+		if err := s.releaseAttempt(ctx, login, device.IP); err != nil {
+			return AuthResult{}, err
+		}
+		challenge, err := s.startOTPChallenge(ctx, user)
+		if err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{OTPChallenge: challenge}, nil
 	}
+	if err := s.resetAttempts(ctx, login, device.IP); err != nil {
+		return AuthResult{}, err
+	}
+
+	token, refreshToken, err := s.createToken(ctx, user, device)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("user token generate: %w", err)
+	}
+	return userEntityToAuthResult(user, token, refreshToken), nil
+}
 
-	token, err := s.tokenRepository.CreateToken(ctx, user)
+// rehashPassword replace user password hash made by other algorithm or weaker parameters, password is already checked.
+func (s *AuthService) rehashPassword(ctx context.Context, user entity.User, password string) (entity.User, error) {
+	if !s.passwordHasher.NeedsRehash(user.PasswordHash) {
+		return user, nil
+	}
+	passwordHash, err := s.passwordHasher.Generate(password)
 	if err != nil {
-		return "", fmt.Errorf("user token generate: %w", err)
+		return entity.User{}, fmt.Errorf("password hash generate: %w", err)
+	}
+	if err := s.userRepository.UpdatePasswordHash(ctx, user.Login, passwordHash); err != nil {
+		return entity.User{}, err
 	}
-	return token, nil
+	user.PasswordHash = passwordHash
+	return user, nil
 }
 
-// Register creates new user and return new user token.
-func (s *AuthService) Register(ctx context.Context, login string, password string) (string, error) {
+// Register creates new user and return new user access and refresh tokens issued to device
+// with user vault key derivation parameters.
+func (s *AuthService) Register(ctx context.Context, login string, password string, device Device) (AuthResult, error) {
 	var fields FieldErrors
 	if len(login) < loginMinLength {
 		field := FieldError{
@@ -77,25 +139,244 @@ func (s *AuthService) Register(ctx context.Context, login string, password strin
 		fields = append(fields, field)
 	}
 	if len(fields) > 0 {
-		return "", fields
+		return AuthResult{}, fields
 	}
 
 	passwordHash, err := s.passwordHasher.Generate(password)
 	if err != nil {
-		return "", fmt.Errorf("password hash generate: %w", err)
+		return AuthResult{}, fmt.Errorf("password hash generate: %w", err)
+	}
+	kdf, err := NewKDF()
+	if err != nil {
+		return AuthResult{}, err
 	}
 	user := entity.User{
 		ID:           s.idGenerator.Generate(),
 		Login:        login,
 		PasswordHash: passwordHash,
+		KDF:          kdfServiceToKDFEntity(kdf),
 		CreatedAt:    time.Now(),
 	}
 	if err := s.userRepository.Create(ctx, user); err != nil {
-		return "", err
+		return AuthResult{}, err
+	}
+	token, refreshToken, err := s.createToken(ctx, user, device)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("new user token generate: %w", err)
 	}
-	token, err := s.tokenRepository.CreateToken(ctx, user)
+	return userEntityToAuthResult(user, token, refreshToken), nil
+}
+
+// GetKDF return user vault key derivation parameters, previous parameters are set while migration is not finished.
+func (s *AuthService) GetKDF(ctx context.Context, login string) (KDF, *KDF, error) {
+	user, err := s.userRepository.GetByLogin(ctx, login)
 	if err != nil {
-		return "", fmt.Errorf("new user token generate: %w", err)
+		return KDF{}, nil, err
+	}
+	result := userEntityToAuthResult(user, "", "")
+	return result.KDF, result.PreviousKDF, nil
+}
+
+// StartKDFMigration replace user vault key derivation parameters keeping current ones as previous
+// till client re-encrypts user items and finishes migration.
+func (s *AuthService) StartKDFMigration(ctx context.Context, login string, kdf KDF) error {
+	if err := validateKDF(kdf); err != nil {
+		return err
+	}
+	user, err := s.userRepository.GetByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	if user.PreviousKDF != nil {
+		return ErrKDFMigrationInProgress
+	}
+
+	previousKDF := user.KDF
+	return s.userRepository.UpdateKDF(ctx, login, kdfServiceToKDFEntity(kdf), &previousKDF)
+}
+
+// FinishKDFMigration forget user previous vault key derivation parameters, finished migration is not an error.
+func (s *AuthService) FinishKDFMigration(ctx context.Context, login string) error {
+	user, err := s.userRepository.GetByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	if user.PreviousKDF == nil {
+		return nil
+	}
+
+	return s.userRepository.UpdateKDF(ctx, login, user.KDF, nil)
+}
+
+// RefreshToken return new access and refresh tokens of session refresh token belongs to,
+// previous access and refresh tokens are revoked. Reuse of replaced refresh token means it was stolen,
+// so the session is revoked and ErrRefreshTokenReused is returned.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	te, secret, err := getToken(ctx, s.tokenRepository, refreshToken)
+	if err != nil {
+		return "", "", err
+	}
+	if !checkTokenSecret(secret, te.RefreshHash) {
+		if te.PreviousRefreshHash != "" && checkTokenSecret(secret, te.PreviousRefreshHash) {
+			return "", "", s.revokeReusedToken(ctx, te.ID)
+		}
+		return "", "", repository.ErrTokenNotFound
+	}
+	token, accessHash, err := newTokenSecret(te.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("access token generate: %w", err)
+	}
+	newRefreshToken, refreshHash, err := newTokenSecret(te.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("refresh token generate: %w", err)
+	}
+	expiresAt := time.Now().Add(s.accessTokenLifetime)
+	if err := s.tokenRepository.RotateToken(ctx, te.ID, te.RefreshHash, accessHash, refreshHash, expiresAt); err != nil {
+		// Refresh token was used concurrently, one of the callers has it stolen.
+		if errors.Is(err, repository.ErrTokenRotated) {
+			return "", "", s.revokeReusedToken(ctx, te.ID)
+		}
+		return "", "", err
+	}
+	return token, newRefreshToken, nil
+}
+
+// revokeReusedToken revoke session whose replaced refresh token was used again.
+func (s *AuthService) revokeReusedToken(ctx context.Context, id string) error {
+	if err := s.tokenRepository.DeleteToken(ctx, id); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout revoke user session of access token.
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	return s.tokenRepository.DeleteToken(ctx, tokenStoredID(token))
+}
+
+// RevokeAllSessions revoke all user tokens.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, login string) error {
+	return s.tokenRepository.DeleteTokensByUser(ctx, login)
+}
+
+// ListSessions return not expired user sessions ordered by creation time, access token marks current session.
+func (s *AuthService) ListSessions(ctx context.Context, login string, token string) ([]Session, error) {
+	tokens, err := s.tokenRepository.FindTokensByUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	sessions := make([]Session, 0, len(tokens))
+	currentID := tokenStoredID(token)
+	for _, t := range tokens {
+		// Tokens issued before refresh tokens can't be used anymore.
+		if t.RefreshHash == "" {
+			continue
+		}
+		sessions = append(sessions, tokenEntityToSession(t, currentID))
+	}
+	return sessions, nil
+}
+
+// RevokeSession revoke user session by ID.
+func (s *AuthService) RevokeSession(ctx context.Context, login string, id string) error {
+	te, err := s.tokenRepository.GetToken(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) || errors.Is(err, repository.ErrTokenExpired) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if te.UserLogin != login {
+		return ErrSessionNotFound
+	}
+	return s.tokenRepository.DeleteToken(ctx, id)
+}
+
+// createToken generate new user session issued to device and return its access and refresh tokens.
+func (s *AuthService) createToken(ctx context.Context, user entity.User, device Device) (string, string, error) {
+	id, err := randomString(tokenIDLength)
+	if err != nil {
+		return "", "", err
+	}
+	token, accessHash, err := newTokenSecret(id)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, refreshHash, err := newTokenSecret(id)
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	te := entity.Token{
+		ID:              id,
+		UserLogin:       user.Login,
+		AccessHash:      accessHash,
+		RefreshHash:     refreshHash,
+		Device:          deviceServiceToDeviceEntity(device),
+		CreatedAt:       now,
+		LastUsedAt:      now,
+		AccessExpiresAt: now.Add(s.accessTokenLifetime),
+	}
+	if err := s.tokenRepository.CreateToken(ctx, te); err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
+
+func tokenEntityToSession(t entity.Token, currentID string) Session {
+	return Session{
+		ID: t.ID,
+		Device: Device{
+			Name:          t.Device.Name,
+			ClientVersion: t.Device.ClientVersion,
+			IP:            t.Device.IP,
+		},
+		CreatedAt:  t.CreatedAt,
+		LastUsedAt: t.LastUsedAt,
+		Current:    t.ID == currentID,
+	}
+}
+
+func deviceServiceToDeviceEntity(in Device) entity.Device {
+	return entity.Device{
+		Name:          in.Name,
+		ClientVersion: in.ClientVersion,
+		IP:            in.IP,
+	}
+}
+
+func userEntityToAuthResult(user entity.User, token string, refreshToken string) AuthResult {
+	result := AuthResult{
+		Token:        token,
+		RefreshToken: refreshToken,
+		KDF:          kdfEntityToKDFService(user.KDF),
+	}
+	if user.PreviousKDF != nil {
+		previousKDF := kdfEntityToKDFService(*user.PreviousKDF)
+		result.PreviousKDF = &previousKDF
+	}
+	return result
+}
+
+func kdfServiceToKDFEntity(in KDF) entity.KDF {
+	return entity.KDF{
+		Version: in.Version,
+		Salt:    in.Salt,
+		Time:    in.Time,
+		Memory:  in.Memory,
+		Threads: in.Threads,
+	}
+}
+
+func kdfEntityToKDFService(in entity.KDF) KDF {
+	return KDF{
+		Version: in.Version,
+		Salt:    in.Salt,
+		Time:    in.Time,
+		Memory:  in.Memory,
+		Threads: in.Threads,
 	}
-	return token, nil
 }
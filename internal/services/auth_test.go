@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"keeper/internal/repository"
+	"keeper/internal/repository/file"
+	"keeper/internal/repository/memory"
+)
+
+// newTestDB open embedded file storage removed after test.
+func newTestDB(t *testing.T) *bbolt.DB {
+	t.Helper()
+	db, err := bbolt.Open(filepath.Join(t.TempDir(), "keeper.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		//goland:noinspection GoUnhandledErrorResult
+		db.Close()
+	})
+	return db
+}
+
+// tokenRepositories are token storages checked by session tests.
+var tokenRepositories = []struct {
+	name       string
+	repository func(t *testing.T) TokenRepository
+}{
+	{
+		name: "memory",
+		repository: func(t *testing.T) TokenRepository {
+			return memory.NewTokenRepository(time.Hour)
+		},
+	},
+	{
+		name: "file",
+		repository: func(t *testing.T) TokenRepository {
+			return file.NewTokenRepository(newTestDB(t), time.Hour)
+		},
+	},
+}
+
+func newTestAuthService(tokenRepository TokenRepository) (*AuthService, *TokenService) {
+	userRepository := memory.NewUserRepository()
+	s := NewAuthService(
+		&UuidGenerator{},
+		&BCryptPasswordHasher{Cost: 4},
+		userRepository,
+		tokenRepository,
+		memory.NewItemRepository(),
+		memory.NewAttemptRepository(),
+		memory.NewSRPExchangeRepository(),
+		[]byte("secret"),
+		time.Hour,
+	)
+	return s, NewTokenService(userRepository, tokenRepository)
+}
+
+func TestRefreshToken(t *testing.T) {
+	for _, tt := range tokenRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, tokens := newTestAuthService(tt.repository(t))
+			res, err := s.Register(ctx, "user", "password", Device{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			token, refreshToken, err := s.RefreshToken(ctx, res.RefreshToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refreshToken == res.RefreshToken || token == res.Token {
+				t.Fatal("tokens are not rotated")
+			}
+			if _, err := tokens.GetUser(ctx, res.Token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Fatalf("replaced access token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if _, err := tokens.GetUser(ctx, token, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			// Access token is not accepted as refresh token.
+			if _, _, err := s.RefreshToken(ctx, token); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Fatalf("access token refresh error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			// Replaced refresh token is reused, so session is revoked with its new tokens.
+			if _, _, err := s.RefreshToken(ctx, res.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+				t.Fatalf("reused refresh token error = %v, want %v", err, ErrRefreshTokenReused)
+			}
+			if _, err := tokens.GetUser(ctx, token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Fatalf("revoked access token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if _, _, err := s.RefreshToken(ctx, refreshToken); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Fatalf("revoked refresh token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenParallel(t *testing.T) {
+	for _, tt := range tokenRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, _ := newTestAuthService(tt.repository(t))
+			res, err := s.Register(ctx, "user", "password", Device{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			const parallel = 10
+			errs := make(chan error, parallel)
+			var wg sync.WaitGroup
+			for i := 0; i < parallel; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _, err := s.RefreshToken(ctx, res.RefreshToken)
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			var refreshed, reused int
+			for err := range errs {
+				switch {
+				case err == nil:
+					refreshed++
+				case errors.Is(err, ErrRefreshTokenReused):
+					reused++
+				case errors.Is(err, repository.ErrTokenNotFound):
+				default:
+					t.Fatalf("unexpected error %v", err)
+				}
+			}
+			if refreshed > 1 || reused == 0 {
+				t.Errorf("got %d refreshed and %d reused refresh tokens", refreshed, reused)
+			}
+			sessions, err := s.ListSessions(ctx, "user", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sessions) != 0 {
+				t.Errorf("session is not revoked after parallel refresh")
+			}
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	for _, tt := range tokenRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, tokens := newTestAuthService(tt.repository(t))
+			if _, err := s.Register(ctx, "other", "password", Device{}); err != nil {
+				t.Fatal(err)
+			}
+			other, err := s.Auth(ctx, "other", "password", Device{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			laptop, err := s.Register(ctx, "user", "password", Device{Name: "laptop"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			phone, err := s.Auth(ctx, "user", "password", Device{Name: "phone"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.Logout(ctx, laptop.Token); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tokens.GetUser(ctx, laptop.Token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Errorf("logged out access token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if _, _, err := s.RefreshToken(ctx, laptop.RefreshToken); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Errorf("logged out refresh token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if _, err := tokens.GetUser(ctx, phone.Token, ""); err != nil {
+				t.Errorf("other session is revoked by logout: %v", err)
+			}
+
+			if err := s.RevokeAllSessions(ctx, "user"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tokens.GetUser(ctx, phone.Token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Errorf("revoked access token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if _, _, err := s.RefreshToken(ctx, phone.RefreshToken); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Errorf("revoked refresh token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if _, err := tokens.GetUser(ctx, other.Token, ""); err != nil {
+				t.Errorf("other user session is revoked: %v", err)
+			}
+		})
+	}
+}
+
+func TestSessions(t *testing.T) {
+	for _, tt := range tokenRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, tokens := newTestAuthService(tt.repository(t))
+			laptop, err := s.Register(ctx, "user", "password", Device{Name: "laptop", ClientVersion: "1.0.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			phone, err := s.Auth(ctx, "user", "password", Device{Name: "phone", ClientVersion: "1.1.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			other, err := s.Register(ctx, "other", "password", Device{Name: "other"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tokens.GetUser(ctx, phone.Token, "10.0.0.2"); err != nil {
+				t.Fatal(err)
+			}
+
+			sessions, err := s.ListSessions(ctx, "user", laptop.Token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sessions) != 2 {
+				t.Fatalf("ListSessions() returned %d sessions, want 2", len(sessions))
+			}
+			if d := sessions[0].Device; d.Name != "laptop" || d.ClientVersion != "1.0.0" || !sessions[0].Current {
+				t.Errorf("first session = %+v, want current laptop session", sessions[0])
+			}
+			if d := sessions[1].Device; d.Name != "phone" || d.IP != "10.0.0.2" || sessions[1].Current {
+				t.Errorf("second session = %+v, want phone session used from 10.0.0.2", sessions[1])
+			}
+			if sessions[1].LastUsedAt.Before(sessions[1].CreatedAt) {
+				t.Errorf("session last usage %s is before creation %s", sessions[1].LastUsedAt, sessions[1].CreatedAt)
+			}
+
+			otherSessions, err := s.ListSessions(ctx, "other", other.Token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.RevokeSession(ctx, "user", otherSessions[0].ID); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("RevokeSession() of other user session error = %v, want %v", err, ErrSessionNotFound)
+			}
+			if _, err := tokens.GetUser(ctx, other.Token, ""); err != nil {
+				t.Errorf("other user session is revoked: %v", err)
+			}
+			if err := s.RevokeSession(ctx, "user", sessions[1].ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tokens.GetUser(ctx, phone.Token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+				t.Errorf("revoked session token error = %v, want %v", err, repository.ErrTokenNotFound)
+			}
+			if err := s.RevokeSession(ctx, "user", sessions[1].ID); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("RevokeSession() of revoked session error = %v, want %v", err, ErrSessionNotFound)
+			}
+			if sessions, err = s.ListSessions(ctx, "user", laptop.Token); err != nil || len(sessions) != 1 {
+				t.Errorf("ListSessions() after revoke returned %d sessions, %v, want 1", len(sessions), err)
+			}
+		})
+	}
+}
+
+func TestTokenStorage(t *testing.T) {
+	for _, tt := range tokenRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tokenRepository := tt.repository(t)
+			s, tokens := newTestAuthService(tokenRepository)
+			first, err := s.Register(ctx, "user", "password", Device{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := s.Auth(ctx, "user", "password", Device{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.Token == second.Token || tokenStoredID(first.Token) == tokenStoredID(second.Token) {
+				t.Fatal("sessions of the same user got the same token")
+			}
+
+			// Only token ID and secret hashes are stored, token itself can't be taken from storage.
+			id, secret, _ := strings.Cut(first.Token, tokenSeparator)
+			_, refreshSecret, _ := strings.Cut(first.RefreshToken, tokenSeparator)
+			if len(secret) < tokenSecretLength {
+				t.Fatalf("token secret %q is too short", secret)
+			}
+			stored, err := tokenRepository.GetToken(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.AccessHash != tokenHash(secret) || stored.RefreshHash != tokenHash(refreshSecret) {
+				t.Errorf("stored token = %+v, want secret hashes", stored)
+			}
+			if strings.Contains(fmt.Sprintf("%+v", stored), secret) || strings.Contains(fmt.Sprintf("%+v", stored), refreshSecret) {
+				t.Error("token secret is stored")
+			}
+
+			forged := []string{
+				id + tokenSeparator + refreshSecret,
+				id + tokenSeparator + stored.AccessHash,
+				id + tokenSeparator,
+				id,
+				tokenSeparator + secret,
+			}
+			for _, token := range forged {
+				if _, err := tokens.GetUser(ctx, token, ""); !errors.Is(err, repository.ErrTokenNotFound) {
+					t.Errorf("GetUser(%q) error = %v, want %v", token, err, repository.ErrTokenNotFound)
+				}
+			}
+		})
+	}
+}
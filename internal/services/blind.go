@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "keeper/gen/service"
+)
+
+const (
+	// indexKeyItemName is name of item keeping random key of names blind index in zero-knowledge vaults.
+	// It is never a valid blind index value, so it can't collide with user items.
+	indexKeyItemName = ".keeper/index-key"
+	indexKeyLength   = 32
+)
+
+var (
+	ErrItemNameReserved = errors.New("item name is reserved")
+	ErrIndexKeyMissing  = errors.New("index key of zero-knowledge vault is not found on server")
+)
+
+// VaultStore interface describe requirements for client storage of vault mode used by ClientService.
+// Zero-knowledge mode of vault with given key is pinned once it is seen, so vault is never used
+// as plain one when server doesn't return its index key.
+type VaultStore interface {
+	ZeroKnowledge(secret string) (bool, error)
+	SetZeroKnowledge(secret string) error
+}
+
+// itemAttributes is item identity encrypted in zero-knowledge vaults.
+type itemAttributes struct {
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Metadata []Metadata `json:"metadata"`
+}
+
+// EnableZeroKnowledge hide names, types and metadata of user items from server.
+// Item names are replaced with keyed blind index, so items are still received by name,
+// and item identity is encrypted with item data key. Index key is random and kept as reserved item,
+// so it is re-wrapped as any other item when vault key is changed.
+// Items stored before are moved under blind names, their previous versions are removed with plain items.
+// Interrupted migration is finished by calling it again.
+func (s *ClientService) EnableZeroKnowledge(ctx context.Context, token string, secret string) error {
+	key, err := s.loadIndexKey(ctx, token, secret)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		if key, err = s.createIndexKey(ctx, token, secret); err != nil {
+			return err
+		}
+	}
+	if err := s.pinZeroKnowledge(secret); err != nil {
+		return err
+	}
+
+	summaries, err := s.getAllSummaries(ctx, token)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		if summary.Name == indexKeyItemName || len(summary.Attributes) > 0 {
+			continue
+		}
+		if err := s.blindItem(ctx, token, secret, key, summary.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndexKey store new random index key of names blind index as reserved item.
+func (s *ClientService) createIndexKey(ctx context.Context, token string, secret string) ([]byte, error) {
+	key := make([]byte, indexKeyLength)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	pbItem, err := encryptItem(secret, Item{Name: indexKeyItemName, Type: "key", Data: key}, nil)
+	if err != nil {
+		return nil, err
+	}
+	req := pb.CreateItemRequest{
+		Item: pbItem,
+	}
+	if _, err := s.client.CreateItem(getOutgoingContext(ctx, token), &req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.indexKeys[secret] = key
+	s.mu.Unlock()
+	return key, nil
+}
+
+// blindItem move plain item under its blind name. Plain item is removed only if it was not changed meanwhile,
+// till then it is source of truth, so blind copy left by interrupted migration is replaced.
+func (s *ClientService) blindItem(ctx context.Context, token string, secret string, indexKey []byte, name string) error {
+	plain, err := s.getItem(ctx, token, name)
+	if err != nil {
+		return err
+	}
+	item, err := decryptItem(secret, plain)
+	if err != nil {
+		return err
+	}
+	pbItem, err := encryptItem(secret, item, indexKey)
+	if err != nil {
+		return err
+	}
+
+	ctx = getOutgoingContext(ctx, token)
+	createReq := pb.CreateItemRequest{
+		Item: pbItem,
+	}
+	_, err = s.client.CreateItem(ctx, &createReq)
+	if status.Code(err) == codes.AlreadyExists {
+		updateReq := pb.UpdateItemRequest{
+			Item:    pbItem,
+			Replace: true,
+		}
+		_, err = s.client.UpdateItem(ctx, &updateReq)
+	}
+	if err != nil {
+		return err
+	}
+	deleteReq := pb.DeleteItemRequest{
+		Name:     name,
+		Revision: plain.GetRevision(),
+	}
+	_, err = s.client.DeleteItem(ctx, &deleteReq)
+	return err
+}
+
+// indexKey return names blind index key of user vault, nil key means that vault is not zero-knowledge one.
+// Vault pinned as zero-knowledge one without index key on server is refused, seen index key pins vault.
+func (s *ClientService) indexKey(ctx context.Context, token string, secret string) ([]byte, error) {
+	key, err := s.loadIndexKey(ctx, token, secret)
+	if err != nil {
+		return nil, err
+	}
+	if key != nil {
+		return key, s.pinZeroKnowledge(secret)
+	}
+	if s.vaultStore == nil {
+		return nil, nil
+	}
+	zeroKnowledge, err := s.vaultStore.ZeroKnowledge(secret)
+	if err != nil {
+		return nil, err
+	}
+	if zeroKnowledge {
+		return nil, ErrIndexKeyMissing
+	}
+	return nil, nil
+}
+
+// pinZeroKnowledge remember in vault store that vault with given key is zero-knowledge one.
+func (s *ClientService) pinZeroKnowledge(secret string) error {
+	if s.vaultStore == nil {
+		return nil
+	}
+	zeroKnowledge, err := s.vaultStore.ZeroKnowledge(secret)
+	if err != nil || zeroKnowledge {
+		return err
+	}
+	return s.vaultStore.SetZeroKnowledge(secret)
+}
+
+// loadIndexKey return names blind index key received from server, key is cached by vault key.
+func (s *ClientService) loadIndexKey(ctx context.Context, token string, secret string) ([]byte, error) {
+	s.mu.Lock()
+	key, ok := s.indexKeys[secret]
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	pbItem, err := s.getItem(ctx, token, indexKeyItemName)
+	switch status.Code(err) {
+	case codes.OK:
+		item, err := decryptItem(secret, pbItem)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting index key: %w", err)
+		}
+		key = item.Data
+	case codes.NotFound:
+		key = nil
+	default:
+		return nil, err
+	}
+	s.mu.Lock()
+	s.indexKeys[secret] = key
+	s.mu.Unlock()
+	return key, nil
+}
+
+// serverName return name item is stored on server with.
+func (s *ClientService) serverName(ctx context.Context, token string, secret string, name string) (string, error) {
+	if name == indexKeyItemName {
+		return "", ErrItemNameReserved
+	}
+	key, err := s.indexKey(ctx, token, secret)
+	if err != nil {
+		return "", err
+	}
+	if key == nil {
+		return name, nil
+	}
+	return blindName(key, name), nil
+}
+
+// blindName return keyed blind index value of item name.
+func blindName(indexKey []byte, name string) string {
+	mac := hmac.New(sha256.New, indexKey)
+	mac.Write([]byte(name))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// sealAttributes encrypt item identity with item data key and bind it to server item name.
+func sealAttributes(dataKey []byte, serverName string, item Item) ([]byte, error) {
+	attrs := itemAttributes{
+		Name:     item.Name,
+		Type:     item.Type,
+		Metadata: item.Metadata,
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling item attributes: %w", err)
+	}
+	return seal(dataKey, data, itemAssociatedData(serverName, "", nil))
+}
+
+// openAttributes decrypt item identity stored under server item name.
+// Strict data key refuses identity sealed without associated data, see unwrapDataKey.
+func openAttributes(dataKey []byte, strict bool, serverName string, data []byte) (itemAttributes, error) {
+	data, err := openEnvelope(dataKey, data, itemAssociatedData(serverName, "", nil), strict)
+	if err != nil {
+		return itemAttributes{}, err
+	}
+	var attrs itemAttributes
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return itemAttributes{}, fmt.Errorf("unmarshaling item attributes: %w", err)
+	}
+	return attrs, nil
+}
+
+// openSummary replace server item identity in summary with decrypted one for zero-knowledge vault items.
+func openSummary(secret string, summary ItemSummary) (ItemSummary, error) {
+	if len(summary.Attributes) == 0 {
+		return summary, nil
+	}
+	dataKey, strict, err := unwrapDataKey(secret, summary.DataKey)
+	if err != nil {
+		return ItemSummary{}, err
+	}
+	attrs, err := openAttributes(dataKey, strict, summary.Name, summary.Attributes)
+	if err != nil {
+		return ItemSummary{}, fmt.Errorf("item %s: %w", summary.Name, err)
+	}
+	summary.Name = attrs.Name
+	summary.Type = attrs.Type
+	summary.Metadata = attrs.Metadata
+	summary.Attributes = nil
+	return summary, nil
+}
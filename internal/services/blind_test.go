@@ -0,0 +1,257 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "keeper/gen/service"
+	"keeper/internal/services"
+)
+
+// memoryVaultStore keep zero-knowledge mode of vaults by vault key.
+type memoryVaultStore map[string]bool
+
+func (s memoryVaultStore) ZeroKnowledge(secret string) (bool, error) {
+	return s[secret], nil
+}
+
+func (s memoryVaultStore) SetZeroKnowledge(secret string) error {
+	s[secret] = true
+	return nil
+}
+
+// hidingIndexKeyClient pretend that server lost index key of zero-knowledge vault.
+type hidingIndexKeyClient struct {
+	pb.KeeperServiceClient
+}
+
+func (c hidingIndexKeyClient) GetItem(ctx context.Context, in *pb.GetItemRequest, opts ...grpc.CallOption) (*pb.GetItemResponse, error) {
+	if in.GetName() == ".keeper/index-key" {
+		return nil, status.Error(codes.NotFound, "item not found")
+	}
+	return c.KeeperServiceClient.GetItem(ctx, in, opts...)
+}
+
+// failingDeleteClient fail item removals till allowed, like interrupted client.
+type failingDeleteClient struct {
+	pb.KeeperServiceClient
+	allowed *bool
+}
+
+func (c failingDeleteClient) DeleteItem(ctx context.Context, in *pb.DeleteItemRequest, opts ...grpc.CallOption) (*pb.DeleteItemResponse, error) {
+	if !*c.allowed {
+		return nil, status.Error(codes.Unavailable, "connection lost")
+	}
+	return c.KeeperServiceClient.DeleteItem(ctx, in, opts...)
+}
+
+func TestEnableZeroKnowledgeMigration(t *testing.T) {
+	ctx := context.Background()
+	conn, _ := newTestServer(t)
+	var allowed bool
+	c := services.NewClientService(failingDeleteClient{KeeperServiceClient: conn, allowed: &allowed}, services.Device{Name: "test"})
+	res, err := c.Register(ctx, "user", "password", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []services.Item{
+		{Name: "bank", Type: "card", Data: []byte("1234"), Metadata: []services.Metadata{{Key: "bank", Value: "city"}}},
+		{Name: "mail", Type: "password", Data: []byte("secret")},
+	}
+	for _, item := range items {
+		if err := c.Add(ctx, res.Token, res.Key, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.EnableZeroKnowledge(ctx, res.Token, res.Key); status.Code(err) != codes.Unavailable {
+		t.Fatalf("interrupted EnableZeroKnowledge() error = %v, want Unavailable", err)
+	}
+	allowed = true
+	if err := c.EnableZeroKnowledge(ctx, res.Token, res.Key); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := conn.GetItemsList(metadata.AppendToOutgoingContext(ctx, "token", res.Token), &pb.GetItemsListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.GetItems()) != len(items)+1 {
+		t.Fatalf("server keeps %d items, want %d with index key", len(stored.GetItems()), len(items)+1)
+	}
+	for _, pbItem := range stored.GetItems() {
+		if pbItem.GetName() == ".keeper/index-key" {
+			continue
+		}
+		if len(pbItem.GetAttributes()) == 0 || pbItem.GetType() != "" || len(pbItem.GetMetadata()) != 0 {
+			t.Errorf("item %s identity is not hidden from server", pbItem.GetName())
+		}
+		for _, item := range items {
+			if pbItem.GetName() == item.Name {
+				t.Errorf("item %s is stored with plain name", item.Name)
+			}
+		}
+	}
+
+	list, _, err := c.List(ctx, res.Token, res.Key, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != len(items) {
+		t.Fatalf("List() returned %d items, want %d", len(list), len(items))
+	}
+	for _, want := range items {
+		got, err := c.Get(ctx, res.Token, res.Key, want.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Type != want.Type || string(got.Data) != string(want.Data) || len(got.Metadata) != len(want.Metadata) {
+			t.Errorf("Get(%s) = %+v, want %+v", want.Name, got, want)
+		}
+	}
+}
+
+func TestZeroKnowledgePinned(t *testing.T) {
+	ctx := context.Background()
+	conn, _ := newTestServer(t)
+	store := memoryVaultStore{}
+	c := services.NewClientService(conn, services.Device{Name: "test"})
+	c.SetVaultStore(store)
+	res, err := c.Register(ctx, "user", "password", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ZeroKnowledge {
+		t.Fatal("new vault is zero-knowledge one")
+	}
+	if err := c.EnableZeroKnowledge(ctx, res.Token, res.Key); err != nil {
+		t.Fatal(err)
+	}
+	if !store[res.Key] {
+		t.Fatal("enabled zero-knowledge mode is not pinned")
+	}
+	item := services.Item{Name: "note", Type: "text", Data: []byte("data")}
+	if err := c.Add(ctx, res.Token, res.Key, item); err != nil {
+		t.Fatal(err)
+	}
+
+	other := services.NewClientService(conn, services.Device{Name: "other"})
+	login, err := other.Login(ctx, "user", "password", "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !login.ZeroKnowledge {
+		t.Error("login doesn't report zero-knowledge vault")
+	}
+
+	hidden := services.NewClientService(hidingIndexKeyClient{conn}, services.Device{Name: "test"})
+	hidden.SetVaultStore(store)
+	if _, err := hidden.Get(ctx, res.Token, res.Key, "note"); !errors.Is(err, services.ErrIndexKeyMissing) {
+		t.Errorf("Get() error = %v, want %v", err, services.ErrIndexKeyMissing)
+	}
+	item.Name = "other"
+	if err := hidden.Add(ctx, res.Token, res.Key, item); !errors.Is(err, services.ErrIndexKeyMissing) {
+		t.Errorf("Add() error = %v, want %v", err, services.ErrIndexKeyMissing)
+	}
+	if _, err := hidden.Login(ctx, "user", "password", "secret", nil); !errors.Is(err, services.ErrIndexKeyMissing) {
+		t.Errorf("Login() error = %v, want %v", err, services.ErrIndexKeyMissing)
+	}
+}
+
+func TestZeroKnowledgeItems(t *testing.T) {
+	ctx := context.Background()
+	conn, _ := newTestServer(t)
+	c := services.NewClientService(conn, services.Device{Name: "test"})
+	res, err := c.Register(ctx, "user", "password", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.EnableZeroKnowledge(ctx, res.Token, res.Key); err != nil {
+		t.Fatal(err)
+	}
+	items := []services.Item{
+		{Name: "work/acme-card", Type: "card", Data: []byte("1234"), Metadata: []services.Metadata{{Key: "bank", Value: "acme"}}},
+		{Name: "work/mail", Type: "password", Data: []byte("secret")},
+		{Name: "home/note", Type: "text", Data: []byte("note")},
+	}
+	for _, item := range items {
+		if err := c.Add(ctx, res.Token, res.Key, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Add(ctx, res.Token, res.Key, items[0]); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Add() of existing item error = %v, want code %s", err, codes.AlreadyExists)
+	}
+
+	item, err := c.Get(ctx, res.Token, res.Key, "work/mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Data = []byte("new secret")
+	if err := c.Update(ctx, res.Token, res.Key, item); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(ctx, res.Token, res.Key, "home/note", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Server keeps only blind names and sealed identities, updated item stays under the same blind name.
+	stored, err := conn.GetItemsList(metadata.AppendToOutgoingContext(ctx, "token", res.Token), &pb.GetItemsListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.GetItems()) != 3 {
+		t.Fatalf("server keeps %d items, want 2 items and index key", len(stored.GetItems()))
+	}
+	for _, pbItem := range stored.GetItems() {
+		if pbItem.GetName() == ".keeper/index-key" {
+			continue
+		}
+		if strings.Contains(pbItem.GetName(), "work") || pbItem.GetType() != "" || len(pbItem.GetMetadata()) != 0 {
+			t.Errorf("server item %+v reveals item identity", pbItem)
+		}
+	}
+
+	list, _, err := c.List(ctx, res.Token, res.Key, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(list))
+	for _, summary := range list {
+		names = append(names, summary.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "work/acme-card,work/mail" {
+		t.Errorf("List() = %v, want decrypted names without index key", names)
+	}
+	found, err := c.Search(ctx, res.Token, res.Key, services.ItemFilter{Name: "work/*", Metadata: []services.Metadata{{Key: "bank", Value: "acme"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Name != "work/acme-card" || found[0].Type != "card" {
+		t.Errorf("Search() = %+v, want acme card", found)
+	}
+
+	other := services.NewClientService(conn, services.Device{Name: "other"})
+	got, err := other.Get(ctx, res.Token, res.Key, "work/mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "password" || string(got.Data) != "new secret" {
+		t.Errorf("Get() from other client = %+v, want updated mail", got)
+	}
+	if _, err := other.Get(ctx, res.Token, res.Key, "home/note"); status.Code(err) != codes.NotFound {
+		t.Errorf("Get() of deleted item error = %v, want code %s", err, codes.NotFound)
+	}
+	if _, err := c.Get(ctx, res.Token, res.Key, ".keeper/index-key"); !errors.Is(err, services.ErrItemNameReserved) {
+		t.Errorf("Get() of index key item error = %v, want %v", err, services.ErrItemNameReserved)
+	}
+}
@@ -5,117 +5,580 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"sort"
+	"sync"
 
 	"google.golang.org/grpc/metadata"
 
 	pb "keeper/gen/service"
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+	"keeper/pkg/srp"
+)
+
+var (
+	ErrSecretMismatch        = errors.New("item can't be decrypted with vault key derived from provided secret")
+	ErrVersionSecretMismatch = errors.New("item version is encrypted with secret used before rotation")
+	ErrOTPRequired           = errors.New("one-time password is required")
+
+	itemEventTypes = map[pb.ItemEvent_Type]string{
+		pb.ItemEvent_CREATED: ItemEventCreated,
+		pb.ItemEvent_UPDATED: ItemEventUpdated,
+		pb.ItemEvent_DELETED: ItemEventDeleted,
+	}
 )
 
 // ClientService implement logic for Keeper client application and work with GRPC server calls.
 type ClientService struct {
-	client pb.KeeperServiceClient
+	client     pb.KeeperServiceClient
+	device     Device
+	vaultStore VaultStore
+
+	mu        sync.Mutex
+	indexKeys map[string][]byte
 }
 
-// NewClientService construct new ClientService.
-func NewClientService(client pb.KeeperServiceClient) *ClientService {
+// NewClientService construct new ClientService, device is sent with login calls to be shown in user sessions.
+func NewClientService(client pb.KeeperServiceClient, device Device) *ClientService {
 	return &ClientService{
-		client: client,
+		client:    client,
+		device:    device,
+		indexKeys: map[string][]byte{},
 	}
 }
 
-// Register make Register rpc call.
-func (s *ClientService) Register(ctx context.Context, login string, password string) (string, error) {
+// SetVaultStore set storage zero-knowledge mode of vaults is pinned in, without it mode is trusted to server.
+func (s *ClientService) SetVaultStore(store VaultStore) {
+	s.vaultStore = store
+}
+
+// Register make Register rpc call and derive vault key from secret.
+func (s *ClientService) Register(ctx context.Context, login string, password string, secret string) (LoginResult, error) {
 	req := pb.LoginRequest{
 		Login:    login,
 		Password: password,
+		Device:   deviceToDeviceMessage(s.device),
 	}
 	res, err := s.client.Register(ctx, &req)
 	if err != nil {
-		return "", err
+		return LoginResult{}, err
+	}
+	key, err := deriveKey(secret, kdfMessageToKDF(res.GetKdf()))
+	if err != nil {
+		return LoginResult{}, err
+	}
+	result := LoginResult{
+		Token:        res.GetToken(),
+		RefreshToken: res.GetRefreshToken(),
+		Key:          key,
 	}
-	token := res.GetToken()
-	return token, nil
+	return result, nil
 }
 
-// Login make Login rpc call.
-func (s *ClientService) Login(ctx context.Context, login string, password string) (string, error) {
+// Login make Login rpc call and derive vault key from secret.
+// Accounts with two-factor authentication require one-time password or recovery code returned by otpCode.
+// Vault with outdated key derivation parameters is migrated to current default ones, so user items are re-encrypted.
+// Interrupted migration is continued on next login.
+func (s *ClientService) Login(ctx context.Context, login string, password string, secret string, otpCode func() (string, error)) (LoginResult, error) {
 	req := pb.LoginRequest{
 		Login:    login,
 		Password: password,
+		Device:   deviceToDeviceMessage(s.device),
 	}
 	res, err := s.client.Login(ctx, &req)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	return s.finishLogin(ctx, login, secret, res, otpCode)
+}
+
+// finishLogin finish login by one-time password when account requires it, derive vault key from secret,
+// migrate vault with outdated key derivation parameters and find out whether vault is zero-knowledge one.
+func (s *ClientService) finishLogin(ctx context.Context, login string, secret string, res *pb.LoginResponse, otpCode func() (string, error)) (LoginResult, error) {
+	var err error
+	if res.GetOtpChallenge() != "" {
+		if otpCode == nil {
+			return LoginResult{}, ErrOTPRequired
+		}
+		code, err := otpCode()
+		if err != nil {
+			return LoginResult{}, err
+		}
+		req := pb.VerifyOtpRequest{
+			Login:        login,
+			OtpChallenge: res.GetOtpChallenge(),
+			Code:         code,
+			Device:       deviceToDeviceMessage(s.device),
+		}
+		res, err = s.client.VerifyOtp(ctx, &req)
+		if err != nil {
+			return LoginResult{}, err
+		}
+	}
+	result := LoginResult{
+		Token:        res.GetToken(),
+		RefreshToken: res.GetRefreshToken(),
+	}
+
+	kdf := kdfMessageToKDF(res.GetKdf())
+	var previousKDF *KDF
+	if res.GetPreviousKdf() != nil {
+		prev := kdfMessageToKDF(res.GetPreviousKdf())
+		previousKDF = &prev
+	}
+	if previousKDF == nil && isKDFOutdated(kdf) {
+		newKDF, err := s.startMigration(ctx, result.Token)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		prev := kdf
+		previousKDF = &prev
+		kdf = newKDF
+	}
+
+	result.Key, err = deriveKey(secret, kdf)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if previousKDF != nil {
+		result.PreviousKey, err = deriveKey(secret, *previousKDF)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		if err := s.migrate(ctx, result.Token, result.PreviousKey, result.Key); err != nil {
+			return LoginResult{}, err
+		}
+	}
+	indexKey, err := s.indexKey(ctx, result.Token, result.Key)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	result.ZeroKnowledge = indexKey != nil
+	return result, nil
+}
+
+// RegisterSRP make RegisterSrp rpc call with password verifier instead of password and derive vault key from secret.
+func (s *ClientService) RegisterSRP(ctx context.Context, login string, password string, secret string) (LoginResult, error) {
+	verifier, err := newSRPVerifier(login, password)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	req := pb.RegisterSrpRequest{
+		Login:    login,
+		Verifier: verifier,
+		Device:   deviceToDeviceMessage(s.device),
+	}
+	res, err := s.client.RegisterSrp(ctx, &req)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	key, err := deriveKey(secret, kdfMessageToKDF(res.GetKdf()))
+	if err != nil {
+		return LoginResult{}, err
+	}
+	result := LoginResult{
+		Token:        res.GetToken(),
+		RefreshToken: res.GetRefreshToken(),
+		Key:          key,
+		SRP:          true,
+	}
+	return result, nil
+}
+
+// LoginSRP login by SRP-6a exchange, so password is never sent to server, and derive vault key from secret.
+// Server proves it knows password verifier before one-time password or secret are used.
+// Two-factor authentication and vault migration are handled like in Login.
+func (s *ClientService) LoginSRP(ctx context.Context, login string, password string, secret string, otpCode func() (string, error)) (LoginResult, error) {
+	proof, serverProof, err := s.srpProof(ctx, login, password)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	req := pb.FinishSrpLoginRequest{
+		Login:       login,
+		ClientProof: proof.Proof,
+		ExchangeId:  proof.ExchangeID,
+		Device:      deviceToDeviceMessage(s.device),
+	}
+	res, err := s.client.FinishSrpLogin(ctx, &req)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if !srp.VerifyServer(serverProof, res.GetSrpServerProof()) {
+		return LoginResult{}, ErrSRPServerProof
+	}
+	result, err := s.finishLogin(ctx, login, secret, res, otpCode)
+	if err != nil {
+		return LoginResult{}, err
+	}
+	result.SRP = true
+	return result, nil
+}
+
+// EnableSRP make SetSrpVerifier rpc call switching account with password login to zero-knowledge login
+// with new password, current password is sent to server for the last time.
+func (s *ClientService) EnableSRP(ctx context.Context, token string, login string, password string, newPassword string) error {
+	verifier, err := newSRPVerifier(login, newPassword)
+	if err != nil {
+		return err
+	}
+	req := pb.SetSrpVerifierRequest{
+		Password: password,
+		Verifier: verifier,
+	}
+	_, err = s.client.SetSrpVerifier(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// ChangeSRPPassword make SetSrpVerifier rpc call changing password of account with zero-knowledge login,
+// current password is proved by SRP-6a exchange and never sent to server.
+func (s *ClientService) ChangeSRPPassword(ctx context.Context, token string, login string, password string, newPassword string) error {
+	verifier, err := newSRPVerifier(login, newPassword)
+	if err != nil {
+		return err
+	}
+	proof, _, err := s.srpProof(ctx, login, password)
+	if err != nil {
+		return err
+	}
+	req := pb.SetSrpVerifierRequest{
+		SrpClientProof: proof.Proof,
+		SrpExchangeId:  proof.ExchangeID,
+		Verifier:       verifier,
+	}
+	_, err = s.client.SetSrpVerifier(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// DeleteAccountSRP make DeleteAccount rpc call for account with zero-knowledge login, password is proved
+// by SRP-6a exchange.
+func (s *ClientService) DeleteAccountSRP(ctx context.Context, token string, login string, password string) error {
+	proof, _, err := s.srpProof(ctx, login, password)
+	if err != nil {
+		return err
+	}
+	req := pb.DeleteAccountRequest{
+		SrpClientProof: proof.Proof,
+		SrpExchangeId:  proof.ExchangeID,
+	}
+	_, err = s.client.DeleteAccount(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// srpProof start SRP-6a exchange and return client proof for it with expected server proof.
+func (s *ClientService) srpProof(ctx context.Context, login string, password string) (SRPProof, []byte, error) {
+	clientSecret, clientPublic, err := srp.ClientKey()
+	if err != nil {
+		return SRPProof{}, nil, fmt.Errorf("srp client key generate: %w", err)
+	}
+	req := pb.StartSrpLoginRequest{
+		Login:        login,
+		ClientPublic: clientPublic,
+	}
+	res, err := s.client.StartSrpLogin(ctx, &req)
+	if err != nil {
+		return SRPProof{}, nil, err
+	}
+	kdf := kdfMessageToKDF(res.GetKdf())
+	stretched, err := stretchPassword(password, kdf)
+	if err != nil {
+		return SRPProof{}, nil, err
+	}
+	proof, serverProof, err := srp.ClientProof(login, stretched, kdf.Salt, clientSecret, res.GetServerPublic())
+	if err != nil {
+		return SRPProof{}, nil, err
+	}
+	return SRPProof{ExchangeID: res.GetExchangeId(), Proof: proof}, serverProof, nil
+}
+
+// newSRPVerifier return SRP-6a verifier of password stretched with new default parameters.
+func newSRPVerifier(login string, password string) (*pb.SrpVerifier, error) {
+	kdf, err := NewKDF()
+	if err != nil {
+		return nil, err
+	}
+	stretched, err := stretchPassword(password, kdf)
+	if err != nil {
+		return nil, err
+	}
+	verifier := pb.SrpVerifier{
+		Kdf:      kdfToKDFMessage(kdf),
+		Verifier: srp.Verifier(login, stretched, kdf.Salt),
+	}
+	return &verifier, nil
+}
+
+// RotateSecret re-encrypt all user items with vault key derived from new secret.
+// Server keeps rotation state till all items are re-encrypted, so interrupted rotation is continued
+// by calling RotateSecret with the same secrets again.
+func (s *ClientService) RotateSecret(ctx context.Context, token string, secret string, newSecret string) (LoginResult, error) {
+	res, err := s.client.GetKdf(getOutgoingContext(ctx, token), &pb.GetKdfRequest{})
+	if err != nil {
+		return LoginResult{}, err
+	}
+	result := LoginResult{
+		Token: token,
+	}
+
+	kdf := kdfMessageToKDF(res.GetKdf())
+	if res.GetPreviousKdf() != nil {
+		// Rotation was interrupted, current parameters belong to new secret already.
+		result.PreviousKey, err = deriveKey(secret, kdfMessageToKDF(res.GetPreviousKdf()))
+		if err != nil {
+			return LoginResult{}, err
+		}
+		result.Key, err = deriveKey(newSecret, kdf)
+		if err != nil {
+			return LoginResult{}, err
+		}
+	} else {
+		result.PreviousKey, err = deriveKey(secret, kdf)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		if err := s.checkKey(ctx, token, result.PreviousKey); err != nil {
+			return LoginResult{}, err
+		}
+		newKDF, err := s.startMigration(ctx, token)
+		if err != nil {
+			return LoginResult{}, err
+		}
+		result.Key, err = deriveKey(newSecret, newKDF)
+		if err != nil {
+			return LoginResult{}, err
+		}
+	}
+
+	if err := s.migrate(ctx, token, result.PreviousKey, result.Key); err != nil {
+		return LoginResult{}, err
+	}
+	return result, nil
+}
+
+// Logout make Logout rpc call revoking token.
+func (s *ClientService) Logout(ctx context.Context, token string) error {
+	_, err := s.client.Logout(getOutgoingContext(ctx, token), &pb.LogoutRequest{})
+	return err
+}
+
+// RevokeAllSessions make RevokeAllSessions rpc call revoking all user tokens including provided one.
+func (s *ClientService) RevokeAllSessions(ctx context.Context, token string) error {
+	_, err := s.client.RevokeAllSessions(getOutgoingContext(ctx, token), &pb.RevokeAllSessionsRequest{})
+	return err
+}
+
+// StartOTPEnrollment make StartOtpEnrollment rpc call and return provisioning URI for authenticator application.
+func (s *ClientService) StartOTPEnrollment(ctx context.Context, token string, password string) (string, error) {
+	req := pb.StartOtpEnrollmentRequest{
+		Password: password,
+	}
+	res, err := s.client.StartOtpEnrollment(getOutgoingContext(ctx, token), &req)
 	if err != nil {
 		return "", err
 	}
-	token := res.GetToken()
-	return token, nil
+	return res.GetUri(), nil
 }
 
-// List make GetItemsList rpc call.
-func (s *ClientService) List(ctx context.Context, token string) ([]string, error) {
-	ctx = getOutgoingContext(ctx, token)
-	req := pb.GetItemsListRequest{}
-	res, err := s.client.GetItemsList(ctx, &req)
+// StartOTPEnrollmentSRP make StartOtpEnrollment rpc call for account with zero-knowledge login, password is proved
+// by SRP-6a exchange.
+func (s *ClientService) StartOTPEnrollmentSRP(ctx context.Context, token string, login string, password string) (string, error) {
+	proof, _, err := s.srpProof(ctx, login, password)
+	if err != nil {
+		return "", err
+	}
+	req := pb.StartOtpEnrollmentRequest{
+		SrpClientProof: proof.Proof,
+		SrpExchangeId:  proof.ExchangeID,
+	}
+	res, err := s.client.StartOtpEnrollment(getOutgoingContext(ctx, token), &req)
+	if err != nil {
+		return "", err
+	}
+	return res.GetUri(), nil
+}
+
+// ConfirmOTPEnrollment make ConfirmOtpEnrollment rpc call and return recovery codes.
+func (s *ClientService) ConfirmOTPEnrollment(ctx context.Context, token string, code string) ([]string, error) {
+	req := pb.ConfirmOtpEnrollmentRequest{
+		Code: code,
+	}
+	res, err := s.client.ConfirmOtpEnrollment(getOutgoingContext(ctx, token), &req)
 	if err != nil {
 		return nil, err
 	}
-	items := res.GetNames()
-	return items, nil
+	return res.GetRecoveryCodes(), nil
 }
 
-// Get make GetItem rpc call.
-func (s *ClientService) Get(ctx context.Context, token string, secret string, name string) (Item, error) {
-	ctx = getOutgoingContext(ctx, token)
-	req := pb.GetItemRequest{
-		Name: name,
+// DisableOTP make DisableOtp rpc call.
+func (s *ClientService) DisableOTP(ctx context.Context, token string, password string, code string) error {
+	req := pb.DisableOtpRequest{
+		Code:     code,
+		Password: password,
 	}
-	res, err := s.client.GetItem(ctx, &req)
+	_, err := s.client.DisableOtp(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// DisableOTPSRP make DisableOtp rpc call for account with zero-knowledge login, password is proved
+// by SRP-6a exchange.
+func (s *ClientService) DisableOTPSRP(ctx context.Context, token string, login string, password string, code string) error {
+	proof, _, err := s.srpProof(ctx, login, password)
 	if err != nil {
-		return Item{}, err
+		return err
+	}
+	req := pb.DisableOtpRequest{
+		Code:           code,
+		SrpClientProof: proof.Proof,
+		SrpExchangeId:  proof.ExchangeID,
+	}
+	_, err = s.client.DisableOtp(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// ChangePassword make ChangePassword rpc call, other user tokens are revoked.
+func (s *ClientService) ChangePassword(ctx context.Context, token string, oldPassword string, newPassword string) error {
+	req := pb.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}
+	_, err := s.client.ChangePassword(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// DeleteAccount make DeleteAccount rpc call.
+func (s *ClientService) DeleteAccount(ctx context.Context, token string, password string) error {
+	req := pb.DeleteAccountRequest{
+		Password: password,
 	}
-	pbItem := res.GetItem()
-	data, err := decryptData(secret, pbItem.GetData())
+	_, err := s.client.DeleteAccount(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// ListSessions make ListSessions rpc call.
+func (s *ClientService) ListSessions(ctx context.Context, token string) ([]Session, error) {
+	res, err := s.client.ListSessions(getOutgoingContext(ctx, token), &pb.ListSessionsRequest{})
 	if err != nil {
-		return Item{}, err
+		return nil, err
 	}
-	item := Item{
-		Name: pbItem.GetName(),
-		Type: pbItem.GetType(),
-		Data: data,
+	sessions := make([]Session, 0, len(res.GetSessions()))
+	for _, msg := range res.GetSessions() {
+		session := Session{
+			ID: msg.GetId(),
+			Device: Device{
+				Name:          msg.GetDeviceName(),
+				ClientVersion: msg.GetClientVersion(),
+				IP:            msg.GetIp(),
+			},
+			CreatedAt:  msg.GetCreatedAt().AsTime(),
+			LastUsedAt: msg.GetLastUsedAt().AsTime(),
+			Current:    msg.GetCurrent(),
+		}
+		sessions = append(sessions, session)
 	}
-	item.Metadata = make([]Metadata, 0, len(pbItem.GetMetadata()))
-	for _, m := range pbItem.GetMetadata() {
-		mtd := Metadata{
-			Key:   m.GetKey(),
-			Value: m.GetValue(),
+	return sessions, nil
+}
+
+// RevokeSession make RevokeSession rpc call.
+func (s *ClientService) RevokeSession(ctx context.Context, token string, id string) error {
+	req := pb.RevokeSessionRequest{
+		Id: id,
+	}
+	_, err := s.client.RevokeSession(getOutgoingContext(ctx, token), &req)
+	return err
+}
+
+// List make GetItemsList rpc call and return items page with next page token.
+// Identity of zero-knowledge vault items is decrypted, so pages of such vaults are not ordered by name.
+func (s *ClientService) List(ctx context.Context, token string, secret string, pageSize int, pageToken string) ([]ItemSummary, string, error) {
+	summaries, nextPageToken, err := s.listPage(ctx, token, pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	items := make([]ItemSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.Name == indexKeyItemName {
+			continue
 		}
-		item.Metadata = append(item.Metadata, mtd)
+		item, err := openSummary(secret, summary)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, item)
 	}
-	return item, nil
+	return items, nextPageToken, nil
 }
 
-// Add make CreateItem rpc call.
-func (s *ClientService) Add(ctx context.Context, token string, secret string, item Item) error {
-	ctx = getOutgoingContext(ctx, token)
-	data, err := encryptData(secret, item.Data)
+// Search make SearchItems rpc call.
+// Server can't filter zero-knowledge vault items, so all items are received and filtered locally.
+func (s *ClientService) Search(ctx context.Context, token string, secret string, filter ItemFilter) ([]ItemSummary, error) {
+	indexKey, err := s.indexKey(ctx, token, secret)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	pbItem := pb.Item{
-		Name: item.Name,
-		Type: item.Type,
-		Data: data,
+	if indexKey != nil {
+		var items []ItemSummary
+		var pageToken string
+		for {
+			page, next, err := s.List(ctx, token, secret, 0, pageToken)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, page...)
+			if next == "" {
+				return filterSummaries(items, filter)
+			}
+			pageToken = next
+		}
 	}
-	for _, m := range item.Metadata {
+
+	req := pb.SearchItemsRequest{
+		Name: filter.Name,
+		Type: filter.Type,
+	}
+	for _, m := range filter.Metadata {
 		mtd := pb.Metadata{
 			Key:   m.Key,
 			Value: m.Value,
 		}
-		pbItem.Metadata = append(pbItem.Metadata, &mtd)
+		req.Metadata = append(req.Metadata, &mtd)
 	}
+	res, err := s.client.SearchItems(getOutgoingContext(ctx, token), &req)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ItemSummary, 0, len(res.GetItems()))
+	for _, pbItem := range res.GetItems() {
+		items = append(items, itemSummaryMessageToItemSummary(pbItem))
+	}
+	return items, nil
+}
+
+// Get make GetItem rpc call.
+func (s *ClientService) Get(ctx context.Context, token string, secret string, name string) (Item, error) {
+	serverName, err := s.serverName(ctx, token, secret, name)
+	if err != nil {
+		return Item{}, err
+	}
+	pbItem, err := s.getItem(ctx, token, serverName)
+	if err != nil {
+		return Item{}, err
+	}
+	return decryptItem(secret, pbItem)
+}
+
+// Add make CreateItem rpc call.
+func (s *ClientService) Add(ctx context.Context, token string, secret string, item Item) error {
+	pbItem, err := s.encryptItem(ctx, token, secret, item)
+	if err != nil {
+		return err
+	}
+	ctx = getOutgoingContext(ctx, token)
 	req := pb.CreateItemRequest{
-		Item: &pbItem,
+		Item: pbItem,
 	}
 	_, err = s.client.CreateItem(ctx, &req)
 	if err != nil {
@@ -124,27 +587,598 @@ func (s *ClientService) Add(ctx context.Context, token string, secret string, it
 	return nil
 }
 
-// Delete make DeleteItem rpc call.
-func (s *ClientService) Delete(ctx context.Context, token string, name string) error {
+// Update make UpdateItem rpc call, item revision is sent as expected current revision.
+func (s *ClientService) Update(ctx context.Context, token string, secret string, item Item) error {
+	pbItem, err := s.encryptItem(ctx, token, secret, item)
+	if err != nil {
+		return err
+	}
+	ctx = getOutgoingContext(ctx, token)
+	req := pb.UpdateItemRequest{
+		Item:     pbItem,
+		Revision: item.Revision,
+	}
+	_, err = s.client.UpdateItem(ctx, &req)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete make DeleteItem rpc call, zero revision skips server side revision check.
+func (s *ClientService) Delete(ctx context.Context, token string, secret string, name string, revision int64) error {
+	serverName, err := s.serverName(ctx, token, secret, name)
+	if err != nil {
+		return err
+	}
 	ctx = getOutgoingContext(ctx, token)
 	req := pb.DeleteItemRequest{
-		Name: name,
+		Name:     serverName,
+		Revision: revision,
+	}
+	_, err = s.client.DeleteItem(ctx, &req)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// History make GetItemHistory rpc call.
+func (s *ClientService) History(ctx context.Context, token string, secret string, name string) ([]ItemVersion, error) {
+	serverName, err := s.serverName(ctx, token, secret, name)
+	if err != nil {
+		return nil, err
+	}
+	ctx = getOutgoingContext(ctx, token)
+	req := pb.GetItemHistoryRequest{
+		Name: serverName,
+	}
+	res, err := s.client.GetItemHistory(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]ItemVersion, 0, len(res.GetVersions()))
+	for _, v := range res.GetVersions() {
+		version := ItemVersion{
+			Version:   v.GetVersion(),
+			UpdatedAt: v.GetUpdatedAt().AsTime(),
+			DataKey:   v.GetDataKey(),
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Restore make RestoreItemVersion rpc call, zero revision skips server side revision check.
+// Versions which data key can't be unwrapped with current vault key are not restored, they would be unreadable.
+func (s *ClientService) Restore(ctx context.Context, token string, secret string, name string, version int64, revision int64) error {
+	serverName, err := s.serverName(ctx, token, secret, name)
+	if err != nil {
+		return err
+	}
+	versions, err := s.History(ctx, token, secret, name)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v.Version != version || len(v.DataKey) == 0 {
+			continue
+		}
+		if _, err := unwrapKey(secret, v.DataKey); err != nil {
+			return ErrVersionSecretMismatch
+		}
+	}
+	ctx = getOutgoingContext(ctx, token)
+	req := pb.RestoreItemVersionRequest{
+		Name:     serverName,
+		Version:  version,
+		Revision: revision,
+	}
+	_, err = s.client.RestoreItemVersion(ctx, &req)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Changes make GetItemChanges rpc call.
+// Deleted items of zero-knowledge vault are named with names blind index, see itemNames.
+func (s *ClientService) Changes(ctx context.Context, token string, secret string, cursor int64) (ItemChanges, error) {
+	ctx = getOutgoingContext(ctx, token)
+	req := pb.GetItemChangesRequest{
+		Cursor: cursor,
+	}
+	res, err := s.client.GetItemChanges(ctx, &req)
+	if err != nil {
+		return ItemChanges{}, err
+	}
+	changes := ItemChanges{
+		Items:   make([]Item, 0, len(res.GetItems())),
+		Deleted: res.GetDeleted(),
+		Cursor:  res.GetCursor(),
+	}
+	for _, pbItem := range res.GetItems() {
+		if pbItem.GetName() == indexKeyItemName {
+			continue
+		}
+		item, err := decryptItem(secret, pbItem)
+		if err != nil {
+			return ItemChanges{}, err
+		}
+		changes.Items = append(changes.Items, item)
+	}
+	return changes, nil
+}
+
+// Watch make WatchItems rpc call and pass every received event to handler until stream or context is finished.
+func (s *ClientService) Watch(ctx context.Context, token string, handler func(event ItemEvent) error) error {
+	ctx = getOutgoingContext(ctx, token)
+	req := pb.WatchItemsRequest{}
+	stream, err := s.client.WatchItems(ctx, &req)
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		event := ItemEvent{
+			Type:      itemEventTypes[msg.GetType()],
+			Name:      msg.GetName(),
+			UpdatedAt: msg.GetUpdatedAt().AsTime(),
+			Revision:  msg.GetRevision(),
+		}
+		if err := handler(event); err != nil {
+			return err
+		}
 	}
-	_, err := s.client.DeleteItem(ctx, &req)
+}
+
+// startMigration start server side migration to new default vault key derivation parameters.
+func (s *ClientService) startMigration(ctx context.Context, token string) (KDF, error) {
+	kdf, err := NewKDF()
 	if err != nil {
+		return KDF{}, err
+	}
+	req := pb.StartKdfMigrationRequest{
+		Kdf: kdfToKDFMessage(kdf),
+	}
+	if _, err := s.client.StartKdfMigration(getOutgoingContext(ctx, token), &req); err != nil {
+		return KDF{}, err
+	}
+	return kdf, nil
+}
+
+// migrate re-encrypt user items with new key and finish server side migration.
+func (s *ClientService) migrate(ctx context.Context, token string, oldKey string, newKey string) error {
+	if err := s.rekey(ctx, token, oldKey, newKey); err != nil {
 		return err
 	}
+	_, err := s.client.FinishKdfMigration(getOutgoingContext(ctx, token), &pb.FinishKdfMigrationRequest{})
+	return err
+}
+
+// checkKey check that every user item is decrypted with key.
+func (s *ClientService) checkKey(ctx context.Context, token string, key string) error {
+	summaries, err := s.getAllSummaries(ctx, token)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		if len(summary.DataKey) > 0 {
+			if _, err := unwrapKey(key, summary.DataKey); err != nil {
+				return fmt.Errorf("item %s: %w", summary.Name, ErrSecretMismatch)
+			}
+			continue
+		}
+		pbItem, err := s.getItem(ctx, token, summary.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := decryptItem(key, pbItem); err != nil {
+			return fmt.Errorf("item %s: %w", summary.Name, ErrSecretMismatch)
+		}
+	}
+	return nil
+}
+
+// rekey re-wrap with new key data key of every user item still wrapped with old key,
+// so item data is never re-uploaded. Items stored before envelope encryption or sealed without associated data
+// are re-encrypted with new data key replacing their history, so such data doesn't stay on server.
+// All items are checked before the first change, so wrong keys never leave items encrypted with third key.
+// Data keys of previous item versions are re-wrapped too, so they can be restored after rotation.
+func (s *ClientService) rekey(ctx context.Context, token string, oldKey string, newKey string) error {
+	summaries, err := s.getAllSummaries(ctx, token)
+	if err != nil {
+		return err
+	}
+	// staleItem is item re-encrypted with new data key, blind items are stored under blind index of their name.
+	type staleItem struct {
+		item  Item
+		blind bool
+	}
+	var staleKeys []*pb.UpdateItemKeyRequest
+	var staleItems []staleItem
+	for _, summary := range summaries {
+		if len(summary.DataKey) > 0 {
+			var wrappedKey []byte
+			if _, err := unwrapKey(newKey, summary.DataKey); err != nil {
+				dataKey, strict, err := unwrapDataKey(oldKey, summary.DataKey)
+				if err != nil {
+					return fmt.Errorf("item %s: %w", summary.Name, ErrSecretMismatch)
+				}
+				if !strict {
+					pbItem, err := s.getItem(ctx, token, summary.Name)
+					if err != nil {
+						return err
+					}
+					if isSealedWithoutAD(pbItem.GetData()) {
+						item, err := decryptItem(oldKey, pbItem)
+						if err != nil {
+							return fmt.Errorf("item %s: %w", summary.Name, ErrSecretMismatch)
+						}
+						staleItems = append(staleItems, staleItem{item: item, blind: len(pbItem.GetAttributes()) > 0})
+						continue
+					}
+				}
+				if wrappedKey, err = wrapKey(newKey, dataKey); err != nil {
+					return err
+				}
+			}
+			versionKeys, err := s.rewrapVersionKeys(ctx, token, summary.Name, oldKey, newKey)
+			if err != nil {
+				return err
+			}
+			if wrappedKey == nil && versionKeys == nil {
+				continue
+			}
+			if wrappedKey == nil {
+				wrappedKey = summary.DataKey
+			}
+			req := pb.UpdateItemKeyRequest{
+				Name:            summary.Name,
+				DataKey:         wrappedKey,
+				Revision:        summary.Revision,
+				VersionDataKeys: versionKeys,
+			}
+			staleKeys = append(staleKeys, &req)
+			continue
+		}
+		pbItem, err := s.getItem(ctx, token, summary.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := decryptItem(newKey, pbItem); err == nil {
+			continue
+		}
+		item, err := decryptItem(oldKey, pbItem)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", summary.Name, ErrSecretMismatch)
+		}
+		staleItems = append(staleItems, staleItem{item: item})
+	}
+
+	ctx = getOutgoingContext(ctx, token)
+	for _, req := range staleKeys {
+		if _, err := s.client.UpdateItemKey(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, stale := range staleItems {
+		var indexKey []byte
+		if stale.blind {
+			// Index key is not changed by rekey, so item keeps its server name.
+			if indexKey, err = s.indexKey(ctx, token, oldKey); err != nil {
+				return err
+			}
+		}
+		pbItem, err := encryptItem(newKey, stale.item, indexKey)
+		if err != nil {
+			return err
+		}
+		req := pb.UpdateItemRequest{
+			Item:     pbItem,
+			Revision: stale.item.Revision,
+			Replace:  true,
+		}
+		if _, err := s.client.UpdateItem(ctx, &req); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// rewrapVersionKeys return data keys of item previous versions re-wrapped with new key, oldest first,
+// or nil when no version needs it. Versions already wrapped with new key get empty key, so they are kept.
+// Versions wrapped with neither key are kept too, they were left by rotation before history was re-wrapped.
+// Versions stored before envelope encryption make update needed, server removes them.
+func (s *ClientService) rewrapVersionKeys(ctx context.Context, token string, name string, oldKey string, newKey string) ([][]byte, error) {
+	res, err := s.client.GetItemHistory(getOutgoingContext(ctx, token), &pb.GetItemHistoryRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	var changed bool
+	versionKeys := make([][]byte, len(res.GetVersions()))
+	for i, v := range res.GetVersions() {
+		if len(v.GetDataKey()) == 0 {
+			changed = true
+			continue
+		}
+		wrappedKey, err := rewrapKey(oldKey, newKey, v.GetDataKey())
+		if err != nil || wrappedKey == nil {
+			continue
+		}
+		versionKeys[i] = wrappedKey
+		changed = true
+	}
+	if !changed {
+		return nil, nil
+	}
+	return versionKeys, nil
+}
+
+// listPage make GetItemsList rpc call and return items page as stored on server.
+func (s *ClientService) listPage(ctx context.Context, token string, pageSize int, pageToken string) ([]ItemSummary, string, error) {
+	req := pb.GetItemsListRequest{
+		PageSize:  int32(pageSize),
+		PageToken: pageToken,
+	}
+	res, err := s.client.GetItemsList(getOutgoingContext(ctx, token), &req)
+	if err != nil {
+		return nil, "", err
+	}
+	items := make([]ItemSummary, 0, len(res.GetItems()))
+	for _, pbItem := range res.GetItems() {
+		items = append(items, itemSummaryMessageToItemSummary(pbItem))
+	}
+	return items, res.GetNextPageToken(), nil
+}
+
+// getAllSummaries receive summaries of all user items as stored on server.
+func (s *ClientService) getAllSummaries(ctx context.Context, token string) ([]ItemSummary, error) {
+	var summaries []ItemSummary
+	var pageToken string
+	for {
+		page, next, err := s.listPage(ctx, token, 0, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, page...)
+		if next == "" {
+			return summaries, nil
+		}
+		pageToken = next
+	}
+}
+
+// getItem receive user item with encrypted data.
+func (s *ClientService) getItem(ctx context.Context, token string, name string) (*pb.Item, error) {
+	req := pb.GetItemRequest{
+		Name: name,
+	}
+	res, err := s.client.GetItem(getOutgoingContext(ctx, token), &req)
+	if err != nil {
+		return nil, err
+	}
+	return res.GetItem(), nil
+}
+
 func getOutgoingContext(ctx context.Context, token string) context.Context {
 	md := metadata.New(map[string]string{"token": token})
 	ctx = metadata.NewOutgoingContext(ctx, md)
 	return ctx
 }
 
+// itemNames return names items are stored on server with, mapped to item names.
+func (s *ClientService) itemNames(ctx context.Context, token string, secret string, names []string) (map[string]string, error) {
+	indexKey, err := s.indexKey(ctx, token, secret)
+	if err != nil {
+		return nil, err
+	}
+	serverNames := make(map[string]string, len(names))
+	for _, name := range names {
+		if indexKey != nil {
+			serverNames[blindName(indexKey, name)] = name
+		} else {
+			serverNames[name] = name
+		}
+	}
+	return serverNames, nil
+}
+
+// encryptItem encrypt item for storing on server, reserved item names are rejected.
+func (s *ClientService) encryptItem(ctx context.Context, token string, secret string, item Item) (*pb.Item, error) {
+	if item.Name == indexKeyItemName {
+		return nil, ErrItemNameReserved
+	}
+	indexKey, err := s.indexKey(ctx, token, secret)
+	if err != nil {
+		return nil, err
+	}
+	return encryptItem(secret, item, indexKey)
+}
+
+// encryptItem encrypt item data with new data key wrapped by vault key.
+// Not nil index key means zero-knowledge vault, item is stored under blind index of its name
+// and its identity is encrypted with data key.
+func encryptItem(secret string, item Item, indexKey []byte) (*pb.Item, error) {
+	dataKey, wrappedKey, err := newDataKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	data, err := seal(dataKey, item.Data, itemAssociatedData(item.Name, item.Type, item.Metadata))
+	if err != nil {
+		return nil, err
+	}
+	pbItem := pb.Item{
+		Name:    item.Name,
+		Type:    item.Type,
+		Data:    data,
+		DataKey: wrappedKey,
+	}
+	if indexKey != nil {
+		pbItem.Name = blindName(indexKey, item.Name)
+		pbItem.Type = ""
+		pbItem.Attributes, err = sealAttributes(dataKey, pbItem.Name, item)
+		if err != nil {
+			return nil, err
+		}
+		return &pbItem, nil
+	}
+	for _, m := range item.Metadata {
+		mtd := pb.Metadata{
+			Key:   m.Key,
+			Value: m.Value,
+		}
+		pbItem.Metadata = append(pbItem.Metadata, &mtd)
+	}
+	return &pbItem, nil
+}
+
+// decryptItem decrypt item data and identity of zero-knowledge vault item.
+// Items stored before envelope encryption have no data key and are decrypted with vault key directly.
+// Data sealed without associated data is refused for data keys wrapped after items were re-sealed with it.
+func decryptItem(secret string, pbItem *pb.Item) (Item, error) {
+	item := Item{
+		Name:     pbItem.GetName(),
+		Type:     pbItem.GetType(),
+		Revision: pbItem.GetRevision(),
+	}
+	item.Metadata = make([]Metadata, 0, len(pbItem.GetMetadata()))
+	for _, m := range pbItem.GetMetadata() {
+		mtd := Metadata{
+			Key:   m.GetKey(),
+			Value: m.GetValue(),
+		}
+		item.Metadata = append(item.Metadata, mtd)
+	}
+	if len(pbItem.GetDataKey()) == 0 {
+		data, err := decryptData(secret, pbItem.GetData())
+		if err != nil {
+			return Item{}, err
+		}
+		item.Data = data
+		return item, nil
+	}
+
+	dataKey, strict, err := unwrapDataKey(secret, pbItem.GetDataKey())
+	if err != nil {
+		return Item{}, err
+	}
+	if len(pbItem.GetAttributes()) > 0 {
+		attrs, err := openAttributes(dataKey, strict, pbItem.GetName(), pbItem.GetAttributes())
+		if err != nil {
+			return Item{}, err
+		}
+		item.Name = attrs.Name
+		item.Type = attrs.Type
+		item.Metadata = attrs.Metadata
+	}
+	data, err := openEnvelope(dataKey, pbItem.GetData(), itemAssociatedData(item.Name, item.Type, item.Metadata), strict)
+	if err != nil {
+		return Item{}, err
+	}
+	item.Data = data
+	return item, nil
+}
+
+// filterSummaries return summaries matching filter ordered by name.
+func filterSummaries(summaries []ItemSummary, filter ItemFilter) ([]ItemSummary, error) {
+	matcher, err := repository.NewItemMatcher(itemFilterServiceToItemFilterEntity(filter))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ItemSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		entitySummary := entity.ItemSummary{
+			Name:     summary.Name,
+			Type:     summary.Type,
+			Metadata: itemServiceToItemEntity(Item{Metadata: summary.Metadata}, "", "").Metadata,
+		}
+		if matcher.Match(entitySummary) {
+			items = append(items, summary)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// itemAssociatedData encode item identity for authenticating it together with item data,
+// so data of one item is never accepted as data of another one.
+// Every field is prefixed with its length, so different items never produce the same encoding.
+func itemAssociatedData(name string, itemType string, metadata []Metadata) []byte {
+	fields := []string{name, itemType}
+	for _, m := range metadata {
+		fields = append(fields, m.Key, m.Value)
+	}
+	var ad []byte
+	for _, field := range fields {
+		ad = binary.AppendUvarint(ad, uint64(len(field)))
+		ad = append(ad, field...)
+	}
+	return ad
+}
+
+func itemSummaryMessageToItemSummary(pbItem *pb.ItemSummary) ItemSummary {
+	item := ItemSummary{
+		Name:       pbItem.GetName(),
+		Type:       pbItem.GetType(),
+		DataKey:    pbItem.GetDataKey(),
+		Attributes: pbItem.GetAttributes(),
+		Revision:   pbItem.GetRevision(),
+		CreatedAt:  pbItem.GetCreatedAt().AsTime(),
+		UpdatedAt:  pbItem.GetUpdatedAt().AsTime(),
+	}
+	for _, m := range pbItem.GetMetadata() {
+		mtd := Metadata{
+			Key:   m.GetKey(),
+			Value: m.GetValue(),
+		}
+		item.Metadata = append(item.Metadata, mtd)
+	}
+	return item
+}
+
+func kdfToKDFMessage(kdf KDF) *pb.Kdf {
+	return &pb.Kdf{
+		Version: kdf.Version,
+		Salt:    kdf.Salt,
+		Time:    kdf.Time,
+		Memory:  kdf.Memory,
+		Threads: kdf.Threads,
+	}
+}
+
+func deviceToDeviceMessage(device Device) *pb.Device {
+	return &pb.Device{
+		Name:          device.Name,
+		ClientVersion: device.ClientVersion,
+	}
+}
+
+func kdfMessageToKDF(msg *pb.Kdf) KDF {
+	return KDF{
+		Version: msg.GetVersion(),
+		Salt:    msg.GetSalt(),
+		Time:    msg.GetTime(),
+		Memory:  msg.GetMemory(),
+		Threads: msg.GetThreads(),
+	}
+}
+
+// encryptData encrypt data with base64 encoded vault key.
 func encryptData(secret string, data []byte) ([]byte, error) {
-	c, err := aes.NewCipher([]byte(secret))
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("decode vault key: %w", err)
+	}
+	c, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -161,8 +1195,13 @@ func encryptData(secret string, data []byte) ([]byte, error) {
 	return enc, nil
 }
 
+// decryptData decrypt data with base64 encoded vault key.
 func decryptData(secret string, data []byte) ([]byte, error) {
-	c, err := aes.NewCipher([]byte(secret))
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("decode vault key: %w", err)
+	}
+	c, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +1209,9 @@ func decryptData(secret string, data []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(data) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrCiphertextShort
+	}
 	nonce := data[len(data)-gcm.NonceSize():]
 	dec, err := gcm.Open(nil, nonce, data[:len(data)-gcm.NonceSize()], nil)
 	if err != nil {
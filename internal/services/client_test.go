@@ -0,0 +1,471 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "keeper/gen/service"
+	"keeper/internal/entity"
+	"keeper/internal/handlers/server"
+	"keeper/internal/repository/memory"
+	"keeper/internal/services"
+)
+
+// newTestClient start server with memory repositories and return client connected to it and server users storage.
+func newTestClient(t *testing.T) (*services.ClientService, *memory.UserRepository) {
+	t.Helper()
+	client, userRepository := newTestServer(t)
+	return services.NewClientService(client, services.Device{Name: "test"}), userRepository
+}
+
+// newTestServer start server with memory repositories and return connection to it and server users storage,
+// so several clients can share one server.
+func newTestServer(t *testing.T) (pb.KeeperServiceClient, *memory.UserRepository) {
+	t.Helper()
+	idGenerator := &services.UuidGenerator{}
+	userRepository := memory.NewUserRepository()
+	tokenRepository := memory.NewTokenRepository(time.Hour)
+	itemRepository := memory.NewItemRepository()
+	s := server.NewKeeperServer(server.KeeperServerConfig{
+		Log: zap.NewNop().Sugar(),
+		Auth: services.NewAuthService(
+			idGenerator,
+			&services.BCryptPasswordHasher{Cost: 4},
+			userRepository,
+			tokenRepository,
+			itemRepository,
+			memory.NewAttemptRepository(),
+			memory.NewSRPExchangeRepository(),
+			[]byte("secret"),
+			time.Hour,
+		),
+		Token: services.NewTokenService(userRepository, tokenRepository),
+		Item:  services.NewItemService(idGenerator, itemRepository),
+	})
+	listen := bufconn.Listen(1024 * 1024)
+	go func() {
+		//goland:noinspection GoUnhandledErrorResult
+		s.Serve(listen)
+	}()
+	t.Cleanup(s.Close)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listen.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		//goland:noinspection GoUnhandledErrorResult
+		conn.Close()
+	})
+	return pb.NewKeeperServiceClient(conn), userRepository
+}
+
+func TestRestoreAfterRotateSecret(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(c *services.ClientService, ctx context.Context) (services.LoginResult, error)
+	}{
+		{
+			name: "password",
+			register: func(c *services.ClientService, ctx context.Context) (services.LoginResult, error) {
+				return c.Register(ctx, "user", "password", "secret1")
+			},
+		},
+		{
+			name: "zero-knowledge",
+			register: func(c *services.ClientService, ctx context.Context) (services.LoginResult, error) {
+				return c.RegisterSRP(ctx, "user", "password", "secret1")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, _ := newTestClient(t)
+			res, err := tt.register(c, ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			item := services.Item{Name: "note", Type: "text", Data: []byte("v1")}
+			if err := c.Add(ctx, res.Token, res.Key, item); err != nil {
+				t.Fatal(err)
+			}
+			for _, data := range []string{"v2", "v3"} {
+				if item, err = c.Get(ctx, res.Token, res.Key, "note"); err != nil {
+					t.Fatal(err)
+				}
+				item.Data = []byte(data)
+				if err := c.Update(ctx, res.Token, res.Key, item); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Every rotation re-wraps history, so versions stored under any previous secret are restored.
+			steps := []struct {
+				secret    string
+				newSecret string
+				version   int
+				want      string
+			}{
+				{secret: "secret1", newSecret: "secret2", version: 0, want: "v1"},
+				{secret: "secret2", newSecret: "secret3", version: 1, want: "v2"},
+			}
+			for _, step := range steps {
+				if res, err = c.RotateSecret(ctx, res.Token, step.secret, step.newSecret); err != nil {
+					t.Fatal(err)
+				}
+				versions, err := c.History(ctx, res.Token, res.Key, "note")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := c.Restore(ctx, res.Token, res.Key, "note", versions[step.version].Version, 0); err != nil {
+					t.Fatal(err)
+				}
+				got, err := c.Get(ctx, res.Token, res.Key, "note")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got.Data) != step.want {
+					t.Errorf("restored data = %s, want %s", got.Data, step.want)
+				}
+			}
+		})
+	}
+}
+
+// legacyKey return vault key derived by legacy MD5 key derivation.
+func legacyKey(secret string) string {
+	hash := md5.Sum([]byte(secret))
+	return base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString(hash[:])))
+}
+
+func TestLoginMigratesKDF(t *testing.T) {
+	kdf, err := services.NewKDF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		kdf         entity.KDF
+		previousKDF *entity.KDF
+	}{
+		{
+			name: "legacy",
+			kdf:  entity.KDF{Version: services.KDFVersionLegacy},
+		},
+		{
+			name: "interrupted migration",
+			kdf: entity.KDF{
+				Version: kdf.Version,
+				Salt:    kdf.Salt,
+				Time:    kdf.Time,
+				Memory:  kdf.Memory,
+				Threads: kdf.Threads,
+			},
+			previousKDF: &entity.KDF{Version: services.KDFVersionLegacy},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, userRepository := newTestClient(t)
+			res, err := c.Register(ctx, "user", "password", "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Vault items are encrypted with legacy key, like vaults created before Argon2id.
+			if err := userRepository.UpdateKDF(ctx, "user", entity.KDF{Version: services.KDFVersionLegacy}, nil); err != nil {
+				t.Fatal(err)
+			}
+			oldKey := legacyKey("secret")
+			if err := c.Add(ctx, res.Token, oldKey, services.Item{Name: "note", Type: "text", Data: []byte("v1")}); err != nil {
+				t.Fatal(err)
+			}
+			item, err := c.Get(ctx, res.Token, oldKey, "note")
+			if err != nil {
+				t.Fatal(err)
+			}
+			item.Data = []byte("v2")
+			if err := c.Update(ctx, res.Token, oldKey, item); err != nil {
+				t.Fatal(err)
+			}
+			if err := userRepository.UpdateKDF(ctx, "user", tt.kdf, tt.previousKDF); err != nil {
+				t.Fatal(err)
+			}
+
+			if res, err = c.Login(ctx, "user", "password", "secret", nil); err != nil {
+				t.Fatal(err)
+			}
+			if res.PreviousKey != oldKey || res.Key == oldKey {
+				t.Fatalf("login keys are not migrated from legacy key")
+			}
+			user, err := userRepository.GetByLogin(ctx, "user")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.KDF.Version != services.KDFVersionArgon2idAD || user.PreviousKDF != nil {
+				t.Fatalf("migration is not finished, KDF %+v, previous KDF %+v", user.KDF, user.PreviousKDF)
+			}
+			if _, err := c.Get(ctx, res.Token, oldKey, "note"); err == nil {
+				t.Fatal("item is decrypted with legacy key after migration")
+			}
+			got, err := c.Get(ctx, res.Token, res.Key, "note")
+			if err != nil || string(got.Data) != "v2" {
+				t.Fatalf("Get() = %s, %v, want v2", got.Data, err)
+			}
+			versions, err := c.History(ctx, res.Token, res.Key, "note")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Restore(ctx, res.Token, res.Key, "note", versions[0].Version, 0); err != nil {
+				t.Fatal(err)
+			}
+			if got, err = c.Get(ctx, res.Token, res.Key, "note"); err != nil || string(got.Data) != "v1" {
+				t.Fatalf("restored Get() = %s, %v, want v1", got.Data, err)
+			}
+
+			// Next login derives the same key without migration.
+			next, err := c.Login(ctx, "user", "password", "secret", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.Key != res.Key || next.PreviousKey != "" {
+				t.Errorf("second login keys are changed")
+			}
+		})
+	}
+}
+
+func TestRotateSecretMismatch(t *testing.T) {
+	ctx := context.Background()
+	c, userRepository := newTestClient(t)
+	res, err := c.Register(ctx, "user", "password", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(ctx, res.Token, res.Key, services.Item{Name: "note", Type: "text", Data: []byte("v1")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RotateSecret(ctx, res.Token, "wrong", "secret2"); !errors.Is(err, services.ErrSecretMismatch) {
+		t.Fatalf("RotateSecret() error = %v, want %v", err, services.ErrSecretMismatch)
+	}
+	user, err := userRepository.GetByLogin(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.PreviousKDF != nil {
+		t.Fatal("rotation is started with wrong secret")
+	}
+	if _, err := c.Get(ctx, res.Token, res.Key, "note"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreRevision(t *testing.T) {
+	tests := []struct {
+		name string
+		// revision returns expected revision from revision of item after the last update.
+		revision func(current int64) int64
+		wantCode codes.Code
+		wantData string
+	}{
+		{name: "current revision", revision: func(current int64) int64 { return current }, wantData: "v1"},
+		{name: "zero revision", revision: func(int64) int64 { return 0 }, wantData: "v1"},
+		{name: "stale revision", revision: func(current int64) int64 { return current - 1 }, wantCode: codes.FailedPrecondition, wantData: "v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, _ := newTestClient(t)
+			res, err := c.Register(ctx, "user", "password", "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Add(ctx, res.Token, res.Key, services.Item{Name: "note", Type: "text", Data: []byte("v1")}); err != nil {
+				t.Fatal(err)
+			}
+			item, err := c.Get(ctx, res.Token, res.Key, "note")
+			if err != nil {
+				t.Fatal(err)
+			}
+			item.Data = []byte("v2")
+			if err := c.Update(ctx, res.Token, res.Key, item); err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.Restore(ctx, res.Token, res.Key, "note", 1, tt.revision(item.Revision+1))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Restore() error = %v, want code %s", err, tt.wantCode)
+			}
+			got, err := c.Get(ctx, res.Token, res.Key, "note")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got.Data) != tt.wantData {
+				t.Errorf("item data = %s, want %s", got.Data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestEditItem(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+	res, err := c.Register(ctx, "user", "password", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := services.Item{
+		Name:     "mail",
+		Type:     "password",
+		Data:     []byte(`{"login":"user","password":"old"}`),
+		Metadata: []services.Metadata{{Key: "site", Value: "mail.example"}},
+	}
+	if err := c.Add(ctx, res.Token, res.Key, item); err != nil {
+		t.Fatal(err)
+	}
+	if item, err = c.Get(ctx, res.Token, res.Key, "mail"); err != nil {
+		t.Fatal(err)
+	}
+	item.Data = []byte(`{"login":"user","password":"new"}`)
+	item.Metadata = append(item.Metadata, services.Metadata{Key: "note", Value: "work"})
+	if err := c.Update(ctx, res.Token, res.Key, item); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Get(ctx, res.Token, res.Key, "mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "password" || string(got.Data) != string(item.Data) {
+		t.Errorf("edited item = %s of type %s, want %s of type password", got.Data, got.Type, item.Data)
+	}
+	if len(got.Metadata) != 2 || got.Metadata[1] != item.Metadata[1] {
+		t.Errorf("edited item metadata = %+v, want %+v", got.Metadata, item.Metadata)
+	}
+	if got.Revision != item.Revision+1 {
+		t.Errorf("edited item revision = %d, want %d", got.Revision, item.Revision+1)
+	}
+
+	item.Name = "absent"
+	if err := c.Update(ctx, res.Token, res.Key, item); status.Code(err) != codes.NotFound {
+		t.Errorf("Update() of absent item error = %v, want code %s", err, codes.NotFound)
+	}
+}
+
+func TestListItemSummaries(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+	res, err := c.Register(ctx, "user", "password", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	card := services.Item{Name: "bank", Type: "card", Data: []byte("1234"), Metadata: []services.Metadata{{Key: "bank", Value: "acme"}}}
+	note := services.Item{Name: "note", Type: "text", Data: []byte("v1")}
+	for _, item := range []services.Item{card, note} {
+		if err := c.Add(ctx, res.Token, res.Key, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if note, err = c.Get(ctx, res.Token, res.Key, "note"); err != nil {
+		t.Fatal(err)
+	}
+	note.Data = []byte("v2")
+	if err := c.Update(ctx, res.Token, res.Key, note); err != nil {
+		t.Fatal(err)
+	}
+
+	list, next, err := c.List(ctx, res.Token, res.Key, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || next != "" {
+		t.Fatalf("List() = %+v, next %q, want 2 items on one page", list, next)
+	}
+	got := map[string]services.ItemSummary{}
+	for _, summary := range list {
+		got[summary.Name] = summary
+	}
+	if s := got["bank"]; s.Type != "card" || len(s.Metadata) != 1 || s.Metadata[0] != card.Metadata[0] {
+		t.Errorf("bank summary = %+v, want card with metadata %+v", s, card.Metadata)
+	}
+	s := got["note"]
+	if s.Type != "text" || s.CreatedAt.Before(start) || !s.UpdatedAt.After(s.CreatedAt) {
+		t.Errorf("note summary = %+v, want text created after %s and updated later", s, start)
+	}
+}
+
+func TestRotateSecretRewrapsDataKeys(t *testing.T) {
+	ctx := context.Background()
+	conn, _ := newTestServer(t)
+	c := services.NewClientService(conn, services.Device{Name: "test"})
+	res, err := c.Register(ctx, "user", "password", "secret1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("large binary "), 1024)
+	for _, name := range []string{"first", "second"} {
+		if err := c.Add(ctx, res.Token, res.Key, services.Item{Name: name, Type: "binary", Data: data}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	serverItem := func(name string) *pb.Item {
+		t.Helper()
+		out, err := conn.GetItem(metadata.AppendToOutgoingContext(ctx, "token", res.Token), &pb.GetItemRequest{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out.GetItem()
+	}
+	first, second := serverItem("first"), serverItem("second")
+	if len(first.GetDataKey()) == 0 || bytes.Equal(first.GetDataKey(), second.GetDataKey()) {
+		t.Error("items are not sealed with own data keys")
+	}
+	if bytes.Equal(first.GetData(), second.GetData()) {
+		t.Error("the same data of different items has the same ciphertext")
+	}
+
+	oldKey := res.Key
+	if res, err = c.RotateSecret(ctx, res.Token, "secret1", "secret2"); err != nil {
+		t.Fatal(err)
+	}
+	rotated := serverItem("first")
+	if !bytes.Equal(rotated.GetData(), first.GetData()) {
+		t.Error("item data is re-encrypted by rotation, only data key should be rewrapped")
+	}
+	if bytes.Equal(rotated.GetDataKey(), first.GetDataKey()) {
+		t.Error("item data key is not rewrapped by rotation")
+	}
+	got, err := c.Get(ctx, res.Token, res.Key, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, data) {
+		t.Error("rotated item data is changed")
+	}
+	if _, err := c.Get(ctx, res.Token, oldKey, "first"); err == nil {
+		t.Error("rotated item is opened with previous vault key")
+	}
+}
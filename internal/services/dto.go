@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Metadata DTO
@@ -14,12 +15,116 @@ type Metadata struct {
 
 // Item DTO
 type Item struct {
+	Name       string
+	Type       string
+	Data       []byte
+	DataKey    []byte
+	Attributes []byte
+	Metadata   []Metadata
+	Revision   int64
+}
+
+// ItemSummary DTO
+type ItemSummary struct {
+	Name       string
+	Type       string
+	DataKey    []byte
+	Attributes []byte
+	Metadata   []Metadata
+	Revision   int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ItemFilter DTO
+type ItemFilter struct {
 	Name     string
 	Type     string
-	Data     []byte
 	Metadata []Metadata
 }
 
+// ItemVersion DTO
+type ItemVersion struct {
+	Version   int64
+	UpdatedAt time.Time
+	DataKey   []byte
+}
+
+// ItemChanges DTO
+type ItemChanges struct {
+	Items   []Item
+	Deleted []string
+	Cursor  int64
+}
+
+// KDF DTO
+type KDF struct {
+	Version int32
+	Salt    []byte
+	Time    uint32
+	Memory  uint32
+	Threads uint32
+}
+
+// AuthResult DTO, PreviousKDF is set while user vault key migration is not finished.
+// OTPChallenge is set instead of other fields when login requires one-time password.
+// SRPServerProof is set for zero-knowledge login.
+type AuthResult struct {
+	Token          string
+	RefreshToken   string
+	KDF            KDF
+	PreviousKDF    *KDF
+	OTPChallenge   string
+	SRPServerProof []byte
+}
+
+// SRPVerifier DTO of SRP-6a password verifier with parameters of password stretching before verifier calculation.
+type SRPVerifier struct {
+	KDF      KDF
+	Verifier []byte
+}
+
+// SRPChallenge DTO of started SRP-6a exchange, ExchangeID is sent back with client proof.
+type SRPChallenge struct {
+	ExchangeID   string
+	KDF          KDF
+	ServerPublic []byte
+}
+
+// SRPProof DTO of SRP-6a client proof for started exchange.
+type SRPProof struct {
+	ExchangeID string
+	Proof      []byte
+}
+
+// Device DTO, IP is set by server from request peer address.
+type Device struct {
+	Name          string
+	ClientVersion string
+	IP            string
+}
+
+// Session DTO, Current is set for session of token the request is made with.
+type Session struct {
+	ID         string
+	Device     Device
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	Current    bool
+}
+
+// LoginResult DTO, PreviousKey is set when vault was migrated to Key during login.
+// SRP is set for accounts with zero-knowledge login, their password should never be sent to server.
+// ZeroKnowledge is set for vaults hiding item identity from server, see ClientService.EnableZeroKnowledge.
+type LoginResult struct {
+	Token         string
+	RefreshToken  string
+	Key           string
+	PreviousKey   string
+	SRP           bool
+	ZeroKnowledge bool
+}
+
 // FieldError contain field and error for fields validation logic.
 type FieldError struct {
 	Field string
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// envelopeVersion is the first byte of item data and data key ciphertexts, followed by nonce and sealed data.
+	// Version 1 ciphertexts are sealed without associated data, version 2 ones authenticate associated data.
+	envelopeVersionNoAD byte = 1
+	envelopeVersion     byte = 2
+
+	dataKeyLength = 32
+)
+
+// dataKeyAD is associated data of wrapped data keys. Data sealed with such data key is opened only
+// with associated data, so version 1 ciphertexts are refused for it. Data keys wrapped without it
+// belong to items stored before associated data was authenticated.
+var dataKeyAD = []byte("keeper data key")
+
+var (
+	ErrCiphertextVersion = errors.New("unsupported ciphertext version")
+	ErrCiphertextShort   = errors.New("ciphertext is too short")
+)
+
+// newDataKey return new random item data key and the same key wrapped by vault key.
+func newDataKey(secret string) ([]byte, []byte, error) {
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, err
+	}
+	wrappedKey, err := wrapKey(secret, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataKey, wrappedKey, nil
+}
+
+// wrapKey encrypt data key with base64 encoded vault key, data sealed with it must have associated data.
+func wrapKey(secret string, dataKey []byte) ([]byte, error) {
+	return wrapDataKey(secret, dataKey, true)
+}
+
+func wrapDataKey(secret string, dataKey []byte, strict bool) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("decode vault key: %w", err)
+	}
+	var ad []byte
+	if strict {
+		ad = dataKeyAD
+	}
+	return seal(key, dataKey, ad)
+}
+
+// unwrapKey decrypt data key with base64 encoded vault key.
+func unwrapKey(secret string, wrappedKey []byte) ([]byte, error) {
+	dataKey, _, err := unwrapDataKey(secret, wrappedKey)
+	return dataKey, err
+}
+
+// unwrapDataKey decrypt data key with base64 encoded vault key and report
+// that data sealed with it must have associated data.
+func unwrapDataKey(secret string, wrappedKey []byte) ([]byte, bool, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, false, fmt.Errorf("decode vault key: %w", err)
+	}
+	if dataKey, err := openStrict(key, wrappedKey, dataKeyAD); err == nil {
+		return dataKey, true, nil
+	}
+	dataKey, err := open(key, wrappedKey, nil)
+	return dataKey, false, err
+}
+
+// rewrapKey return data key wrapped by old key re-wrapped with new key keeping its associated data requirement,
+// so previous versions sealed without associated data stay readable. Nil is returned for data key
+// already wrapped with new key.
+func rewrapKey(oldKey string, newKey string, wrappedKey []byte) ([]byte, error) {
+	if _, err := unwrapKey(newKey, wrappedKey); err == nil {
+		return nil, nil
+	}
+	dataKey, strict, err := unwrapDataKey(oldKey, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return wrapDataKey(newKey, dataKey, strict)
+}
+
+// isSealedWithoutAD report that ciphertext was sealed before associated data was authenticated.
+func isSealedWithoutAD(data []byte) bool {
+	return len(data) > 0 && data[0] == envelopeVersionNoAD
+}
+
+func seal(key []byte, data []byte, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	enc := make([]byte, 1+gcm.NonceSize(), 1+gcm.NonceSize()+len(data)+gcm.Overhead())
+	enc[0] = envelopeVersion
+	if _, err := io.ReadFull(rand.Reader, enc[1:]); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(enc, enc[1:], data, ad), nil
+}
+
+// open decrypt data sealed by seal, version 1 ciphertexts are opened without associated data.
+func open(key []byte, data []byte, ad []byte) ([]byte, error) {
+	return openEnvelope(key, data, ad, false)
+}
+
+// openStrict decrypt data sealed by seal refusing version 1 ciphertexts, they have no associated data.
+func openStrict(key []byte, data []byte, ad []byte) ([]byte, error) {
+	return openEnvelope(key, data, ad, true)
+}
+
+func openEnvelope(key []byte, data []byte, ad []byte, strict bool) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 1+gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrCiphertextShort
+	}
+	switch {
+	case data[0] == envelopeVersion:
+	case data[0] == envelopeVersionNoAD && !strict:
+		ad = nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrCiphertextVersion, data[0])
+	}
+	nonce := data[1 : 1+gcm.NonceSize()]
+	return gcm.Open(nil, nonce, data[1+gcm.NonceSize():], ad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	pb "keeper/gen/service"
+)
+
+// errAny match any error in tests.
+var errAny = errors.New("any error")
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// sealWithoutAD seal data as version 1 ciphertext, like items stored before associated data was authenticated.
+func sealWithoutAD(t *testing.T, key []byte, data []byte) []byte {
+	t.Helper()
+	enc, err := seal(key, data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc[0] = envelopeVersionNoAD
+	return enc
+}
+
+// wrapKeyWithoutAD wrap data key like items stored before associated data was authenticated.
+func wrapKeyWithoutAD(t *testing.T, secret string, dataKey []byte) []byte {
+	t.Helper()
+	wrappedKey, err := wrapDataKey(secret, dataKey, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wrappedKey
+}
+
+func TestUnwrapDataKey(t *testing.T) {
+	secret := newTestKey(t)
+	dataKey, wrappedKey, err := newDataKey(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := base64.StdEncoding.DecodeString(secret)
+
+	tests := []struct {
+		name       string
+		secret     string
+		wrappedKey []byte
+		wantStrict bool
+		wantErr    bool
+	}{
+		{name: "strict", secret: secret, wrappedKey: wrappedKey, wantStrict: true},
+		{name: "without associated data", secret: secret, wrappedKey: wrapKeyWithoutAD(t, secret, dataKey)},
+		{name: "version 1", secret: secret, wrappedKey: sealWithoutAD(t, key, dataKey)},
+		{name: "wrong secret", secret: newTestKey(t), wrappedKey: wrappedKey, wantErr: true},
+		{name: "short", secret: secret, wrappedKey: wrappedKey[:10], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, strict, err := unwrapDataKey(tt.secret, tt.wrappedKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unwrapDataKey() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strict != tt.wantStrict || !bytes.Equal(got, dataKey) {
+				t.Errorf("unwrapDataKey() = %x, %v, want %x, %v", got, strict, dataKey, tt.wantStrict)
+			}
+		})
+	}
+}
+
+func TestRewrapKey(t *testing.T) {
+	oldKey, newKey := newTestKey(t), newTestKey(t)
+	dataKey, wrappedKey, err := newDataKey(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrapped, err := wrapKey(newKey, dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		wrappedKey []byte
+		wantNil    bool
+		wantStrict bool
+		wantErr    bool
+	}{
+		{name: "strict", wrappedKey: wrappedKey, wantStrict: true},
+		{name: "without associated data", wrappedKey: wrapKeyWithoutAD(t, oldKey, dataKey)},
+		{name: "already re-wrapped", wrappedKey: rewrapped, wantNil: true},
+		{name: "wrapped with other key", wrappedKey: wrapKeyWithoutAD(t, newTestKey(t), dataKey), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewrapKey(oldKey, newKey, tt.wrappedKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rewrapKey() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("rewrapKey() = %x, want nil %v", got, tt.wantNil)
+			}
+			if tt.wantNil {
+				return
+			}
+			unwrapped, strict, err := unwrapDataKey(newKey, got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strict != tt.wantStrict || !bytes.Equal(unwrapped, dataKey) {
+				t.Errorf("re-wrapped key = %x, %v, want %x, %v", unwrapped, strict, dataKey, tt.wantStrict)
+			}
+		})
+	}
+}
+
+func TestOpenEnvelope(t *testing.T) {
+	key := make([]byte, dataKeyLength)
+	ad := []byte("ad")
+	sealed, err := seal(key, []byte("data"), ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutAD := sealWithoutAD(t, key, []byte("data"))
+	unknown := append([]byte{3}, sealed[1:]...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		ad      []byte
+		strict  bool
+		wantErr error
+	}{
+		{name: "sealed", data: sealed, ad: ad},
+		{name: "sealed strict", data: sealed, ad: ad, strict: true},
+		{name: "sealed with other associated data", data: sealed, ad: []byte("other"), wantErr: errAny},
+		{name: "version 1 ignores associated data", data: withoutAD, ad: ad},
+		{name: "version 1 strict", data: withoutAD, ad: ad, strict: true, wantErr: ErrCiphertextVersion},
+		{name: "unknown version", data: unknown, ad: ad, wantErr: ErrCiphertextVersion},
+		{name: "short", data: sealed[:20], ad: ad, wantErr: ErrCiphertextShort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := openEnvelope(key, tt.data, tt.ad, tt.strict)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("openEnvelope() error = %v", err)
+			case tt.wantErr == errAny && err == nil:
+				t.Fatal("openEnvelope() error is nil")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Fatalf("openEnvelope() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && string(got) != "data" {
+				t.Errorf("openEnvelope() = %s, want data", got)
+			}
+			if isSealedWithoutAD(tt.data) != (tt.data[0] == envelopeVersionNoAD) {
+				t.Errorf("isSealedWithoutAD() = %v", isSealedWithoutAD(tt.data))
+			}
+		})
+	}
+}
+
+func TestDecryptItem(t *testing.T) {
+	secret := newTestKey(t)
+	item := Item{
+		Name:     "card",
+		Type:     "card",
+		Data:     []byte("data"),
+		Metadata: []Metadata{{Key: "bank", Value: "bank"}},
+	}
+	ad := itemAssociatedData(item.Name, item.Type, item.Metadata)
+	sealed, err := encryptItem(secret, item, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataKey, err := unwrapKey(secret, sealed.GetDataKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexKey := make([]byte, dataKeyLength)
+	blind, err := encryptItem(secret, item, indexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := encryptData(secret, item.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		item    *pb.Item
+		wantErr bool
+	}{
+		{
+			name: "sealed",
+			item: sealed,
+		},
+		{
+			name: "blind",
+			item: blind,
+		},
+		{
+			name: "sealed with vault key before data keys",
+			item: &pb.Item{Name: item.Name, Type: item.Type, Metadata: sealed.GetMetadata(), Data: legacy},
+		},
+		{
+			name:    "renamed",
+			item:    &pb.Item{Name: "other", Type: sealed.GetType(), Metadata: sealed.GetMetadata(), Data: sealed.GetData(), DataKey: sealed.GetDataKey()},
+			wantErr: true,
+		},
+		{
+			name:    "metadata changed",
+			item:    &pb.Item{Name: sealed.GetName(), Type: sealed.GetType(), Data: sealed.GetData(), DataKey: sealed.GetDataKey()},
+			wantErr: true,
+		},
+		{
+			name:    "blind renamed",
+			item:    &pb.Item{Name: "other", Attributes: blind.GetAttributes(), Data: blind.GetData(), DataKey: blind.GetDataKey()},
+			wantErr: true,
+		},
+		{
+			name: "stored before associated data",
+			item: &pb.Item{Name: sealed.GetName(), Type: sealed.GetType(), Metadata: sealed.GetMetadata(), Data: sealWithoutAD(t, dataKey, item.Data), DataKey: wrapKeyWithoutAD(t, secret, dataKey)},
+		},
+		{
+			name:    "downgraded to version 1 with strict data key",
+			item:    &pb.Item{Name: sealed.GetName(), Type: sealed.GetType(), Metadata: sealed.GetMetadata(), Data: sealWithoutAD(t, dataKey, item.Data), DataKey: sealed.GetDataKey()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decryptItem(secret, tt.item)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decryptItem() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gotAD := itemAssociatedData(got.Name, got.Type, got.Metadata)
+			if string(got.Data) != string(item.Data) || !bytes.Equal(gotAD, ad) {
+				t.Errorf("decryptItem() = %+v, want %+v", got, item)
+			}
+		})
+	}
+}
+
+func TestBlindName(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, indexKeyLength)
+	otherKey := bytes.Repeat([]byte{2}, indexKeyLength)
+	name := blindName(key, "work/bank card")
+	if name != blindName(key, "work/bank card") {
+		t.Error("blind name of the same item name is not stable")
+	}
+	if name == blindName(key, "work/bank card2") || name == blindName(otherKey, "work/bank card") {
+		t.Error("blind name doesn't depend on item name and index key")
+	}
+	// Blind names are used as S3 object and bbolt keys, so they have no path separators.
+	if strings.ContainsAny(name, "/.") || strings.Contains(name, "bank") {
+		t.Errorf("blind name %q is not opaque key", name)
+	}
+}
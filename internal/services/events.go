@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	ItemEventCreated = "created"
+	ItemEventUpdated = "updated"
+	ItemEventDeleted = "deleted"
+
+	// itemEventsBufferSize is subscriber channel size, slower subscriber is unsubscribed.
+	itemEventsBufferSize = 16
+)
+
+var (
+	ErrItemEventsOverflow = errors.New("item events are not received in time, sync items and watch again")
+)
+
+// ItemEvent DTO
+type ItemEvent struct {
+	Type      string
+	Name      string
+	UpdatedAt time.Time
+	Revision  int64
+}
+
+// itemEventBroker deliver item events to subscribers of the same user within one server process.
+type itemEventBroker struct {
+	mu          *sync.RWMutex
+	subscribers map[string]map[chan ItemEvent]struct{}
+}
+
+func newItemEventBroker() *itemEventBroker {
+	return &itemEventBroker{
+		mu:          new(sync.RWMutex),
+		subscribers: map[string]map[chan ItemEvent]struct{}{},
+	}
+}
+
+// subscribe return channel with user item events and function for unsubscribing.
+// Channel is closed when subscriber is unsubscribed, including unsubscribing of slow subscriber by publish.
+func (b *itemEventBroker) subscribe(userID string) (<-chan ItemEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan ItemEvent, itemEventsBufferSize)
+	if _, ok := b.subscribers[userID]; !ok {
+		b.subscribers[userID] = map[chan ItemEvent]struct{}{}
+	}
+	b.subscribers[userID][ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.unsubscribe(userID, ch)
+	}
+	return ch, unsubscribe
+}
+
+// publish send event to all user subscribers without blocking, subscriber with full channel is unsubscribed,
+// so it learns about missed events instead of silently losing them.
+func (b *itemEventBroker) publish(userID string, event ItemEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- event:
+		default:
+			b.unsubscribe(userID, ch)
+		}
+	}
+}
+
+// unsubscribe remove subscriber channel and close it, removed channel is skipped. Lock should be held by caller.
+func (b *itemEventBroker) unsubscribe(userID string, ch chan ItemEvent) {
+	if _, ok := b.subscribers[userID][ch]; !ok {
+		return
+	}
+	delete(b.subscribers[userID], ch)
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+	close(ch)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestItemEventBrokerOverflow(t *testing.T) {
+	b := newItemEventBroker()
+	slow, unsubscribeSlow := b.subscribe("user")
+	fast, unsubscribeFast := b.subscribe("user")
+	defer unsubscribeFast()
+	other, unsubscribeOther := b.subscribe("other")
+	defer unsubscribeOther()
+
+	for i := 0; i < itemEventsBufferSize+1; i++ {
+		event := ItemEvent{Type: ItemEventUpdated, Name: "note", Revision: int64(i + 1)}
+		b.publish("user", event)
+		if got := <-fast; got != event {
+			t.Fatalf("fast subscriber event = %+v, want %+v", got, event)
+		}
+	}
+
+	// Slow subscriber receives buffered events, then closed channel tells it that the rest were missed.
+	for i := 0; i < itemEventsBufferSize; i++ {
+		event, ok := <-slow
+		if !ok {
+			t.Fatalf("slow subscriber channel closed after %d events, want %d", i, itemEventsBufferSize)
+		}
+		if event.Revision != int64(i+1) {
+			t.Fatalf("slow subscriber event revision = %d, want %d", event.Revision, i+1)
+		}
+	}
+	if _, ok := <-slow; ok {
+		t.Fatal("slow subscriber channel is not closed on overflow")
+	}
+	unsubscribeSlow()
+
+	b.publish("user", ItemEvent{Type: ItemEventDeleted, Name: "note"})
+	if got := <-fast; got.Type != ItemEventDeleted {
+		t.Errorf("fast subscriber event after overflow = %+v, want deleted event", got)
+	}
+	select {
+	case event := <-other:
+		t.Errorf("other user subscriber received event %+v", event)
+	default:
+	}
+}
@@ -2,20 +2,34 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"time"
 
 	"keeper/internal/entity"
+	"keeper/internal/repository"
 )
 
 const (
 	itemNameMinLength = 1
+
+	listDefaultPageSize = 100
+	listMaxPageSize     = 1000
+
+	// changesOverlap moves returned cursor back for catching items stored concurrently with changes request.
+	changesOverlap = 10 * time.Second
+)
+
+var (
+	ErrItemVersionNotFound = errors.New("item version not found")
 )
 
 // ItemService implement logic for working with items.
 type ItemService struct {
 	idGenerator    IdGenerator
 	itemRepository ItemRepository
+	events         *itemEventBroker
 }
 
 // NewItemService construct new ItemService.
@@ -23,6 +37,7 @@ func NewItemService(idGenerator IdGenerator, itemRepository ItemRepository) *Ite
 	return &ItemService{
 		idGenerator:    idGenerator,
 		itemRepository: itemRepository,
+		events:         newItemEventBroker(),
 	}
 }
 
@@ -41,13 +56,19 @@ func (s *ItemService) Create(ctx context.Context, userID string, item Item) erro
 	}
 
 	createdItem := itemServiceToItemEntity(item, s.idGenerator.Generate(), userID)
+	createdItem.Revision = 1
 	createdItem.CreatedAt = time.Now()
 	createdItem.UpdatedAt = createdItem.CreatedAt
-	return s.itemRepository.Create(ctx, createdItem)
+	if err := s.itemRepository.Create(ctx, createdItem); err != nil {
+		return err
+	}
+	s.publish(ItemEventCreated, createdItem)
+	return nil
 }
 
 // Update save new version of item for user in storage.
-func (s *ItemService) Update(ctx context.Context, userID string, item Item) error {
+// Revision is expected current item revision, zero revision skips the check.
+func (s *ItemService) Update(ctx context.Context, userID string, item Item, revision int64) error {
 	existedItem, err := s.itemRepository.GetByUserIDAndName(ctx, userID, item.Name)
 	if err != nil {
 		return err
@@ -56,7 +77,48 @@ func (s *ItemService) Update(ctx context.Context, userID string, item Item) erro
 	updatedItem.Name = existedItem.Name // Name can't be changed
 	updatedItem.CreatedAt = existedItem.CreatedAt
 	updatedItem.UpdatedAt = time.Now()
-	return s.itemRepository.Update(ctx, updatedItem)
+	if err := s.itemRepository.Update(ctx, updatedItem, revision); err != nil {
+		return err
+	}
+	s.publishStored(ctx, ItemEventUpdated, updatedItem)
+	return nil
+}
+
+// Replace save new version of item for user in storage dropping its previous versions,
+// so data encrypted with replaced vault key doesn't stay in item history.
+// Revision is expected current item revision, zero revision skips the check.
+func (s *ItemService) Replace(ctx context.Context, userID string, item Item, revision int64) error {
+	existedItem, err := s.itemRepository.GetByUserIDAndName(ctx, userID, item.Name)
+	if err != nil {
+		return err
+	}
+	replacedItem := itemServiceToItemEntity(item, existedItem.ID, userID)
+	replacedItem.Name = existedItem.Name // Name can't be changed
+	replacedItem.CreatedAt = existedItem.CreatedAt
+	replacedItem.UpdatedAt = time.Now()
+	if err := s.itemRepository.Replace(ctx, replacedItem, revision); err != nil {
+		return err
+	}
+	s.publishStored(ctx, ItemEventUpdated, replacedItem)
+	return nil
+}
+
+// UpdateKey replace data key of user item and data keys of its previous versions, oldest first,
+// without changing encrypted item data. Empty version key keeps version data key unchanged,
+// versions without data key are removed as they are encrypted directly with replaced vault key.
+// Revision is expected current item revision, zero revision skips the check.
+func (s *ItemService) UpdateKey(ctx context.Context, userID string, name string, dataKey []byte, versionKeys [][]byte, revision int64) error {
+	item, err := s.itemRepository.GetByUserIDAndName(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	item.DataKey = dataKey
+	item.UpdatedAt = time.Now()
+	if err := s.itemRepository.UpdateDataKey(ctx, item, versionKeys, revision); err != nil {
+		return err
+	}
+	s.publishStored(ctx, ItemEventUpdated, item)
+	return nil
 }
 
 // Get receive user item from storage.
@@ -69,30 +131,152 @@ func (s *ItemService) Get(ctx context.Context, userID string, name string) (Item
 }
 
 // Delete remove user item from storage.
-func (s *ItemService) Delete(ctx context.Context, userID string, name string) error {
+// Revision is expected current item revision, zero revision skips the check.
+func (s *ItemService) Delete(ctx context.Context, userID string, name string, revision int64) error {
 	item, err := s.itemRepository.GetByUserIDAndName(ctx, userID, name)
 	if err != nil {
 		return err
 	}
-	return s.itemRepository.Delete(ctx, item)
+	if err := s.itemRepository.Delete(ctx, item, revision); err != nil {
+		return err
+	}
+	item.UpdatedAt = time.Now()
+	s.publish(ItemEventDeleted, item)
+	return nil
+}
+
+// List receive page of user items list from storage and token for the next page.
+// Empty next page token means there are no more items.
+func (s *ItemService) List(ctx context.Context, userID string, pageSize int, pageToken string) ([]ItemSummary, string, error) {
+	if pageSize <= 0 {
+		pageSize = listDefaultPageSize
+	}
+	if pageSize > listMaxPageSize {
+		pageSize = listMaxPageSize
+	}
+	after, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return []ItemSummary{}, "", FieldErrors{{Field: "page_token", Error: "invalid token"}}
+	}
+
+	// One extra item shows that next page exists.
+	items, err := s.itemRepository.FindByUser(ctx, userID, pageSize+1, string(after))
+	if err != nil {
+		return []ItemSummary{}, "", err
+	}
+	var nextPageToken string
+	if len(items) > pageSize {
+		items = items[:pageSize]
+		nextPageToken = base64.RawURLEncoding.EncodeToString([]byte(items[pageSize-1].Name))
+	}
+	summaries := make([]ItemSummary, 0, len(items))
+	for _, item := range items {
+		summaries = append(summaries, itemSummaryEntityToItemSummaryService(item))
+	}
+	return summaries, nextPageToken, nil
 }
 
-// List receive user items list from storage.
-func (s *ItemService) List(ctx context.Context, userID string) ([]string, error) {
-	items, err := s.itemRepository.FindByUser(ctx, userID)
+// Search receive user items matching filter from storage.
+// Filter name is item name substring or glob pattern, metadata record with empty value matches any value of its key.
+func (s *ItemService) Search(ctx context.Context, userID string, filter ItemFilter) ([]ItemSummary, error) {
+	items, err := s.itemRepository.FindByFilter(ctx, userID, itemFilterServiceToItemFilterEntity(filter))
 	if err != nil {
-		return []string{}, err
+		if errors.Is(err, repository.ErrBadPattern) {
+			return []ItemSummary{}, FieldErrors{{Field: "name", Error: err.Error()}}
+		}
+		return []ItemSummary{}, err
+	}
+	summaries := make([]ItemSummary, 0, len(items))
+	for _, item := range items {
+		summaries = append(summaries, itemSummaryEntityToItemSummaryService(item))
+	}
+	return summaries, nil
+}
+
+// History receive user item previous versions from storage, oldest first.
+func (s *ItemService) History(ctx context.Context, userID string, name string) ([]ItemVersion, error) {
+	items, err := s.itemRepository.FindVersions(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]ItemVersion, 0, len(items))
+	for i, item := range items {
+		v := ItemVersion{
+			Version:   int64(i + 1),
+			UpdatedAt: item.UpdatedAt,
+			DataKey:   item.DataKey,
+		}
+		versions = append(versions, v)
 	}
-	return items, nil
+	return versions, nil
+}
+
+// Restore save selected previous version of item as new version of item.
+// Revision is expected current item revision, zero revision skips the check.
+func (s *ItemService) Restore(ctx context.Context, userID string, name string, version int64, revision int64) error {
+	items, err := s.itemRepository.FindVersions(ctx, userID, name)
+	if err != nil {
+		return err
+	}
+	if version < 1 || version > int64(len(items)) {
+		return ErrItemVersionNotFound
+	}
+	return s.Update(ctx, userID, itemEntityToItemService(items[version-1]), revision)
+}
+
+// Changes receive user items changed after cursor and new cursor for next call.
+func (s *ItemService) Changes(ctx context.Context, userID string, cursor int64) (ItemChanges, error) {
+	now := time.Now()
+	items, deleted, err := s.itemRepository.FindChanges(ctx, userID, time.Unix(0, cursor))
+	if err != nil {
+		return ItemChanges{}, err
+	}
+	changes := ItemChanges{
+		Items:   make([]Item, 0, len(items)),
+		Deleted: deleted,
+		Cursor:  now.Add(-changesOverlap).UnixNano(),
+	}
+	for _, item := range items {
+		changes.Items = append(changes.Items, itemEntityToItemService(item))
+	}
+	return changes, nil
+}
+
+// Watch subscribe to user item events, returned function should be called for unsubscribing.
+// Events are delivered only for changes made through this service instance. Channel is closed
+// for subscriber not receiving events in time, its stream should be finished with ErrItemEventsOverflow.
+func (s *ItemService) Watch(userID string) (<-chan ItemEvent, func()) {
+	return s.events.subscribe(userID)
+}
+
+func (s *ItemService) publish(eventType string, item entity.Item) {
+	event := ItemEvent{
+		Type:      eventType,
+		Name:      item.Name,
+		UpdatedAt: item.UpdatedAt,
+		Revision:  item.Revision,
+	}
+	s.events.publish(item.UserID, event)
+}
+
+// publishStored publish event of written item as it is stored, so event carries revision assigned by storage.
+// Written item is published when it can't be received, watchers receive its current state on sync.
+func (s *ItemService) publishStored(ctx context.Context, eventType string, item entity.Item) {
+	if stored, err := s.itemRepository.GetByUserIDAndName(ctx, item.UserID, item.Name); err == nil {
+		item = stored
+	}
+	s.publish(eventType, item)
 }
 
 func itemServiceToItemEntity(in Item, id string, userID string) entity.Item {
 	out := entity.Item{
-		ID:     id,
-		UserID: userID,
-		Name:   in.Name,
-		Type:   in.Type,
-		Data:   in.Data,
+		ID:         id,
+		UserID:     userID,
+		Name:       in.Name,
+		Type:       in.Type,
+		Data:       in.Data,
+		DataKey:    in.DataKey,
+		Attributes: in.Attributes,
 	}
 	for _, m := range in.Metadata {
 		md := entity.Metadata{
@@ -106,12 +290,50 @@ func itemServiceToItemEntity(in Item, id string, userID string) entity.Item {
 
 func itemEntityToItemService(in entity.Item) Item {
 	out := Item{
+		Name:       in.Name,
+		Type:       in.Type,
+		Data:       in.Data,
+		DataKey:    in.DataKey,
+		Attributes: in.Attributes,
+		Revision:   in.Revision,
+	}
+	for _, m := range in.Metadata {
+		md := Metadata{
+			Key:   m.Key,
+			Value: m.Value,
+		}
+		out.Metadata = append(out.Metadata, md)
+	}
+	return out
+}
+
+func itemSummaryEntityToItemSummaryService(in entity.ItemSummary) ItemSummary {
+	out := ItemSummary{
+		Name:       in.Name,
+		Type:       in.Type,
+		DataKey:    in.DataKey,
+		Attributes: in.Attributes,
+		Revision:   in.Revision,
+		CreatedAt:  in.CreatedAt,
+		UpdatedAt:  in.UpdatedAt,
+	}
+	for _, m := range in.Metadata {
+		md := Metadata{
+			Key:   m.Key,
+			Value: m.Value,
+		}
+		out.Metadata = append(out.Metadata, md)
+	}
+	return out
+}
+
+func itemFilterServiceToItemFilterEntity(in ItemFilter) entity.ItemFilter {
+	out := entity.ItemFilter{
 		Name: in.Name,
 		Type: in.Type,
-		Data: in.Data,
 	}
 	for _, m := range in.Metadata {
-		md := Metadata{
+		md := entity.Metadata{
 			Key:   m.Key,
 			Value: m.Value,
 		}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"keeper/internal/repository"
+	"keeper/internal/repository/file"
+	"keeper/internal/repository/memory"
+)
+
+// itemRepositories are item storages checked by item service tests.
+var itemRepositories = []struct {
+	name       string
+	repository func(t *testing.T) ItemRepository
+}{
+	{
+		name: "memory",
+		repository: func(t *testing.T) ItemRepository {
+			return memory.NewItemRepository()
+		},
+	},
+	{
+		name: "file",
+		repository: func(t *testing.T) ItemRepository {
+			return file.NewItemRepository(newTestDB(t))
+		},
+	},
+}
+
+func TestItemEventRevision(t *testing.T) {
+	ctx := context.Background()
+	s := NewItemService(&UuidGenerator{}, memory.NewItemRepository())
+	events, unsubscribe := s.Watch("user")
+	defer unsubscribe()
+
+	item := Item{Name: "note", Type: "text", Data: []byte("v1")}
+	tests := []struct {
+		name         string
+		change       func() error
+		wantType     string
+		wantRevision int64
+	}{
+		{
+			name:         "create",
+			change:       func() error { return s.Create(ctx, "user", item) },
+			wantType:     ItemEventCreated,
+			wantRevision: 1,
+		},
+		{
+			name:         "update without revision check",
+			change:       func() error { return s.Update(ctx, "user", item, 0) },
+			wantType:     ItemEventUpdated,
+			wantRevision: 2,
+		},
+		{
+			name:         "update",
+			change:       func() error { return s.Update(ctx, "user", item, 2) },
+			wantType:     ItemEventUpdated,
+			wantRevision: 3,
+		},
+		{
+			name:         "restore",
+			change:       func() error { return s.Restore(ctx, "user", "note", 1, 0) },
+			wantType:     ItemEventUpdated,
+			wantRevision: 4,
+		},
+		{
+			name:         "update key",
+			change:       func() error { return s.UpdateKey(ctx, "user", "note", []byte("key"), nil, 4) },
+			wantType:     ItemEventUpdated,
+			wantRevision: 5,
+		},
+		{
+			name:         "replace",
+			change:       func() error { return s.Replace(ctx, "user", item, 0) },
+			wantType:     ItemEventUpdated,
+			wantRevision: 6,
+		},
+		{
+			name:         "delete",
+			change:       func() error { return s.Delete(ctx, "user", "note", 6) },
+			wantType:     ItemEventDeleted,
+			wantRevision: 6,
+		},
+	}
+	for _, tt := range tests {
+		if err := tt.change(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		event := <-events
+		if event.Type != tt.wantType || event.Name != "note" || event.Revision != tt.wantRevision {
+			t.Errorf("%s: event = %+v, want %s of revision %d", tt.name, event, tt.wantType, tt.wantRevision)
+		}
+	}
+}
+
+func TestItemHistory(t *testing.T) {
+	for _, tt := range itemRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewItemService(&UuidGenerator{}, tt.repository(t))
+			item := Item{Name: "note", Type: "text", Data: []byte("v1"), DataKey: []byte("k1")}
+			if err := s.Create(ctx, "user", item); err != nil {
+				t.Fatal(err)
+			}
+			for _, data := range []string{"v2", "v3"} {
+				item.Data = []byte(data)
+				item.DataKey = []byte("k" + data[1:])
+				if err := s.Update(ctx, "user", item, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			versions, err := s.History(ctx, "user", "note")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(versions) != 2 {
+				t.Fatalf("History() returned %d versions, want 2", len(versions))
+			}
+			for i, v := range versions {
+				if v.Version != int64(i+1) || string(v.DataKey) != []string{"k1", "k2"}[i] {
+					t.Errorf("version %d = %+v, want data key of v%d", i+1, v, i+1)
+				}
+			}
+
+			if err := s.Restore(ctx, "user", "note", 1, 0); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.Get(ctx, "user", "note")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got.Data) != "v1" {
+				t.Errorf("restored data = %q, want %q", got.Data, "v1")
+			}
+			if versions, err = s.History(ctx, "user", "note"); err != nil || len(versions) != 3 {
+				t.Errorf("History() after restore returned %d versions, %v, want 3", len(versions), err)
+			}
+			for _, version := range []int64{0, 4} {
+				if err := s.Restore(ctx, "user", "note", version, 0); !errors.Is(err, ErrItemVersionNotFound) {
+					t.Errorf("Restore(%d) error = %v, want %v", version, err, ErrItemVersionNotFound)
+				}
+			}
+
+			if err := s.Replace(ctx, "user", item, 0); err != nil {
+				t.Fatal(err)
+			}
+			if versions, err = s.History(ctx, "user", "note"); err != nil || len(versions) != 0 {
+				t.Errorf("History() after replace returned %d versions, %v, want none", len(versions), err)
+			}
+		})
+	}
+}
+
+func TestItemRevisionMismatch(t *testing.T) {
+	changes := []struct {
+		name   string
+		change func(s *ItemService, ctx context.Context, item Item, revision int64) error
+	}{
+		{
+			name: "update",
+			change: func(s *ItemService, ctx context.Context, item Item, revision int64) error {
+				return s.Update(ctx, "user", item, revision)
+			},
+		},
+		{
+			name: "replace",
+			change: func(s *ItemService, ctx context.Context, item Item, revision int64) error {
+				return s.Replace(ctx, "user", item, revision)
+			},
+		},
+		{
+			name: "update key",
+			change: func(s *ItemService, ctx context.Context, item Item, revision int64) error {
+				return s.UpdateKey(ctx, "user", item.Name, item.DataKey, nil, revision)
+			},
+		},
+		{
+			name: "restore",
+			change: func(s *ItemService, ctx context.Context, item Item, revision int64) error {
+				return s.Restore(ctx, "user", item.Name, 1, revision)
+			},
+		},
+		{
+			name: "delete",
+			change: func(s *ItemService, ctx context.Context, item Item, revision int64) error {
+				return s.Delete(ctx, "user", item.Name, revision)
+			},
+		},
+	}
+	for _, tt := range itemRepositories {
+		for _, c := range changes {
+			t.Run(tt.name+"/"+c.name, func(t *testing.T) {
+				ctx := context.Background()
+				s := NewItemService(&UuidGenerator{}, tt.repository(t))
+				item := Item{Name: "note", Type: "text", Data: []byte("v1"), DataKey: []byte("k1")}
+				if err := s.Create(ctx, "user", item); err != nil {
+					t.Fatal(err)
+				}
+				// Another client changes item after it was received with revision 1.
+				item.Data, item.DataKey = []byte("v2"), []byte("k2")
+				if err := s.Update(ctx, "user", item, 1); err != nil {
+					t.Fatal(err)
+				}
+
+				item.Data, item.DataKey = []byte("v3"), []byte("k3")
+				if err := c.change(s, ctx, item, 1); !errors.Is(err, repository.ErrItemRevisionMismatch) {
+					t.Fatalf("stale change error = %v, want %v", err, repository.ErrItemRevisionMismatch)
+				}
+				got, err := s.Get(ctx, "user", "note")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Revision != 2 || string(got.Data) != "v2" || string(got.DataKey) != "k2" {
+					t.Errorf("item after stale change = %+v, want unchanged revision 2", got)
+				}
+				if err := c.change(s, ctx, item, 2); err != nil {
+					t.Errorf("change of current revision error = %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestItemListPages(t *testing.T) {
+	for _, tt := range itemRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewItemService(&UuidGenerator{}, tt.repository(t))
+			names := []string{"a", "b", "c", "d", "e"}
+			// Items are created out of order, pages should follow name order.
+			for _, i := range []int{3, 0, 4, 1, 2} {
+				if err := s.Create(ctx, "user", Item{Name: names[i], Type: "text"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got []string
+			var pageToken string
+			for pages := 1; ; pages++ {
+				page, next, err := s.List(ctx, "user", 2, pageToken)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(page) > 2 {
+					t.Fatalf("page %d has %d items, want at most 2", pages, len(page))
+				}
+				for _, item := range page {
+					got = append(got, item.Name)
+				}
+				if next == "" {
+					if pages != 3 {
+						t.Errorf("listed %d pages, want 3", pages)
+					}
+					break
+				}
+				pageToken = next
+			}
+			if strings.Join(got, ",") != strings.Join(names, ",") {
+				t.Errorf("listed items = %v, want %v", got, names)
+			}
+
+			// Exactly full page has no next page.
+			if page, next, err := s.List(ctx, "user", len(names), ""); err != nil || len(page) != len(names) || next != "" {
+				t.Errorf("List() of full page = %d items, next %q, %v, want %d items without next page", len(page), next, err, len(names))
+			}
+			var fieldErrors FieldErrors
+			if _, _, err := s.List(ctx, "user", 2, "not base64!"); !errors.As(err, &fieldErrors) {
+				t.Errorf("List() with invalid token error = %v, want field error", err)
+			}
+		})
+	}
+}
+
+func TestItemSearch(t *testing.T) {
+	items := []Item{
+		{Name: "work/acme", Type: "card", Metadata: []Metadata{{Key: "bank", Value: "acme"}}},
+		{Name: "work/mail", Type: "password", Metadata: []Metadata{{Key: "site", Value: "mail.example"}}},
+		{Name: "home/bank", Type: "card", Metadata: []Metadata{{Key: "bank", Value: "city"}}},
+		{Name: "home/note", Type: "text"},
+	}
+	tests := []struct {
+		name   string
+		filter ItemFilter
+		want   []string
+	}{
+		{name: "substring", filter: ItemFilter{Name: "ba"}, want: []string{"home/bank"}},
+		{name: "glob", filter: ItemFilter{Name: "work/*"}, want: []string{"work/acme", "work/mail"}},
+		{name: "type", filter: ItemFilter{Type: "card"}, want: []string{"home/bank", "work/acme"}},
+		{name: "metadata value", filter: ItemFilter{Metadata: []Metadata{{Key: "bank", Value: "acme"}}}, want: []string{"work/acme"}},
+		{name: "metadata key", filter: ItemFilter{Metadata: []Metadata{{Key: "bank"}}}, want: []string{"home/bank", "work/acme"}},
+		{name: "glob and type", filter: ItemFilter{Name: "home/*", Type: "card"}, want: []string{"home/bank"}},
+		{name: "nothing", filter: ItemFilter{Type: "file"}, want: []string{}},
+	}
+	for _, tt := range itemRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewItemService(&UuidGenerator{}, tt.repository(t))
+			for _, item := range items {
+				if err := s.Create(ctx, "user", item); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.Create(ctx, "other", items[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, search := range tests {
+				list, err := s.Search(ctx, "user", search.filter)
+				if err != nil {
+					t.Fatalf("%s: %v", search.name, err)
+				}
+				got := make([]string, 0, len(list))
+				for _, item := range list {
+					got = append(got, item.Name)
+				}
+				if strings.Join(got, ",") != strings.Join(search.want, ",") {
+					t.Errorf("%s: Search() = %v, want %v", search.name, got, search.want)
+				}
+			}
+
+			var fieldErrors FieldErrors
+			if _, err := s.Search(ctx, "user", ItemFilter{Name: "work/["}); !errors.As(err, &fieldErrors) {
+				t.Errorf("Search() with bad pattern error = %v, want field error", err)
+			}
+		})
+	}
+}
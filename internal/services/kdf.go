@@ -0,0 +1,126 @@
+package services
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// KDFVersionLegacy is unsalted MD5 hash of secret, supported only for migration of existing vaults.
+	KDFVersionLegacy int32 = 0
+	// KDFVersionArgon2id is Argon2id with per-user salt.
+	KDFVersionArgon2id int32 = 1
+	// KDFVersionArgon2idAD is Argon2id with per-user salt for vaults which items are re-sealed
+	// with associated data, older vaults are migrated to it on login.
+	KDFVersionArgon2idAD int32 = 2
+
+	kdfSaltLength = 16
+	kdfKeyLength  = 32
+
+	// Default Argon2id parameters follow RFC 9106 recommendation for memory constrained environments.
+	kdfDefaultTime    = 3
+	kdfDefaultMemory  = 64 * 1024
+	kdfDefaultThreads = 4
+
+	kdfMaxTime    = 64
+	kdfMinMemory  = 19 * 1024
+	kdfMaxMemory  = 1024 * 1024
+	kdfMaxThreads = 255
+)
+
+var (
+	ErrKDFMigrationInProgress = errors.New("vault key migration is already in progress")
+	ErrKDFUnsupported         = errors.New("unsupported vault key derivation version")
+)
+
+// NewKDF return current default vault key derivation parameters with new random salt.
+func NewKDF() (KDF, error) {
+	salt := make([]byte, kdfSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return KDF{}, fmt.Errorf("salt generate: %w", err)
+	}
+	kdf := KDF{
+		Version: KDFVersionArgon2idAD,
+		Salt:    salt,
+		Time:    kdfDefaultTime,
+		Memory:  kdfDefaultMemory,
+		Threads: kdfDefaultThreads,
+	}
+	return kdf, nil
+}
+
+// validateKDF check vault key derivation parameters are supported and not weaker than minimal ones.
+func validateKDF(kdf KDF) error {
+	if kdf.Version != KDFVersionArgon2id && kdf.Version != KDFVersionArgon2idAD {
+		return FieldErrors{{Field: "kdf.version", Error: fmt.Sprintf("should be %d or %d", KDFVersionArgon2id, KDFVersionArgon2idAD)}}
+	}
+	var fields FieldErrors
+	if len(kdf.Salt) < kdfSaltLength {
+		fields = append(fields, FieldError{
+			Field: "kdf.salt",
+			Error: fmt.Sprintf("length should be greater or equal %d", kdfSaltLength),
+		})
+	}
+	if kdf.Time < 1 || kdf.Time > kdfMaxTime {
+		fields = append(fields, FieldError{
+			Field: "kdf.time",
+			Error: fmt.Sprintf("should be between 1 and %d", kdfMaxTime),
+		})
+	}
+	if kdf.Memory < kdfMinMemory || kdf.Memory > kdfMaxMemory {
+		fields = append(fields, FieldError{
+			Field: "kdf.memory",
+			Error: fmt.Sprintf("should be between %d and %d", kdfMinMemory, kdfMaxMemory),
+		})
+	}
+	if kdf.Threads < 1 || kdf.Threads > kdfMaxThreads {
+		fields = append(fields, FieldError{
+			Field: "kdf.threads",
+			Error: fmt.Sprintf("should be between 1 and %d", kdfMaxThreads),
+		})
+	}
+	if len(fields) > 0 {
+		return fields
+	}
+	return nil
+}
+
+// isKDFOutdated check if vault key derivation parameters are weaker than current default ones.
+func isKDFOutdated(kdf KDF) bool {
+	return kdf.Version < KDFVersionArgon2idAD ||
+		kdf.Time < kdfDefaultTime ||
+		kdf.Memory < kdfDefaultMemory
+}
+
+// deriveKey derive base64 encoded vault key from user secret.
+func deriveKey(secret string, kdf KDF) (string, error) {
+	var key []byte
+	switch kdf.Version {
+	case KDFVersionLegacy:
+		// Legacy key is hex encoded MD5 hash used as key bytes.
+		hash := md5.Sum([]byte(secret))
+		key = []byte(hex.EncodeToString(hash[:]))
+	case KDFVersionArgon2id, KDFVersionArgon2idAD:
+		if err := validateKDF(kdf); err != nil {
+			return "", fmt.Errorf("vault key derivation parameters: %w", err)
+		}
+		key = argon2.IDKey([]byte(secret), kdf.Salt, kdf.Time, kdf.Memory, uint8(kdf.Threads), kdfKeyLength)
+	default:
+		return "", ErrKDFUnsupported
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// stretchPassword derive SRP-6a password from login password by Argon2id, so stolen verifier is slow to brute-force.
+func stretchPassword(password string, kdf KDF) ([]byte, error) {
+	if err := validateKDF(kdf); err != nil {
+		return nil, fmt.Errorf("password stretching parameters: %w", err)
+	}
+	return argon2.IDKey([]byte(password), kdf.Salt, kdf.Time, kdf.Memory, uint8(kdf.Threads), kdfKeyLength), nil
+}
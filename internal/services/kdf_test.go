@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	kdf, err := NewKDF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kdf.Memory = kdfMinMemory
+	kdf.Time = 1
+	otherSalt := kdf
+	otherSalt.Salt = make([]byte, kdfSaltLength)
+	previous := kdf
+	previous.Version = KDFVersionArgon2id
+	weak := kdf
+	weak.Memory = kdfMinMemory - 1
+
+	key, err := deriveKey("secret", kdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := base64.StdEncoding.DecodeString(key); len(raw) != kdfKeyLength {
+		t.Fatalf("key length = %d, want %d", len(raw), kdfKeyLength)
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		kdf      KDF
+		want     string
+		wantDiff bool
+		wantErr  error
+	}{
+		{
+			name:   "legacy",
+			secret: "secret",
+			kdf:    KDF{Version: KDFVersionLegacy},
+			// Hex encoded MD5 of "secret".
+			want: base64.StdEncoding.EncodeToString([]byte("5ebe2294ecd0e0f08eab7690d2a6ee69")),
+		},
+		{name: "same parameters", secret: "secret", kdf: kdf, want: key},
+		{name: "version without associated data", secret: "secret", kdf: previous, want: key},
+		{name: "other secret", secret: "secret2", kdf: kdf, wantDiff: true},
+		{name: "other salt", secret: "secret", kdf: otherSalt, wantDiff: true},
+		{name: "weak parameters", secret: "secret", kdf: weak, wantErr: errAny},
+		{name: "unsupported version", secret: "secret", kdf: KDF{Version: 3}, wantErr: ErrKDFUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := deriveKey(tt.secret, tt.kdf)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("deriveKey() error = %v", err)
+			case tt.wantErr == errAny && err == nil:
+				t.Fatal("deriveKey() error is nil")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Fatalf("deriveKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if tt.wantDiff && got == key {
+				t.Errorf("deriveKey() = %s, want other key", got)
+			}
+			if !tt.wantDiff && got != tt.want {
+				t.Errorf("deriveKey() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateKDF(t *testing.T) {
+	kdf, err := NewKDF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		update     func(kdf *KDF)
+		wantFields []string
+	}{
+		{name: "default", update: func(kdf *KDF) {}},
+		{name: "version without associated data", update: func(kdf *KDF) { kdf.Version = KDFVersionArgon2id }},
+		{name: "legacy", update: func(kdf *KDF) { kdf.Version = KDFVersionLegacy }, wantFields: []string{"kdf.version"}},
+		{name: "short salt", update: func(kdf *KDF) { kdf.Salt = kdf.Salt[:8] }, wantFields: []string{"kdf.salt"}},
+		{name: "zero time", update: func(kdf *KDF) { kdf.Time = 0 }, wantFields: []string{"kdf.time"}},
+		{name: "large time", update: func(kdf *KDF) { kdf.Time = kdfMaxTime + 1 }, wantFields: []string{"kdf.time"}},
+		{name: "low memory", update: func(kdf *KDF) { kdf.Memory = kdfMinMemory - 1 }, wantFields: []string{"kdf.memory"}},
+		{name: "large memory", update: func(kdf *KDF) { kdf.Memory = kdfMaxMemory + 1 }, wantFields: []string{"kdf.memory"}},
+		{
+			name: "zero threads and memory",
+			update: func(kdf *KDF) {
+				kdf.Threads = 0
+				kdf.Memory = 0
+			},
+			wantFields: []string{"kdf.memory", "kdf.threads"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := kdf
+			tt.update(&in)
+			err := validateKDF(in)
+			var fields FieldErrors
+			if err != nil && !errors.As(err, &fields) {
+				t.Fatalf("validateKDF() error = %v, want FieldErrors", err)
+			}
+			if len(fields) != len(tt.wantFields) {
+				t.Fatalf("validateKDF() = %v, want fields %v", fields, tt.wantFields)
+			}
+			for i, f := range fields {
+				if f.Field != tt.wantFields[i] {
+					t.Errorf("validateKDF() field = %s, want %s", f.Field, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIsKDFOutdated(t *testing.T) {
+	kdf, err := NewKDF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		update func(kdf *KDF)
+		want   bool
+	}{
+		{name: "default", update: func(kdf *KDF) {}},
+		{name: "stronger", update: func(kdf *KDF) { kdf.Time++ }},
+		{name: "legacy", update: func(kdf *KDF) { *kdf = KDF{Version: KDFVersionLegacy} }, want: true},
+		{name: "version without associated data", update: func(kdf *KDF) { kdf.Version = KDFVersionArgon2id }, want: true},
+		{name: "lower time", update: func(kdf *KDF) { kdf.Time-- }, want: true},
+		{name: "lower memory", update: func(kdf *KDF) { kdf.Memory-- }, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := kdf
+			tt.update(&in)
+			if got := isKDFOutdated(in); got != tt.want {
+				t.Errorf("isKDFOutdated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
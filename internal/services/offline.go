@@ -0,0 +1,625 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	operationCreate = "create"
+	operationUpdate = "update"
+	operationDelete = "delete"
+)
+
+var (
+	ErrOfflineQueued = errors.New("server unavailable, change queued for next sync")
+)
+
+// SyncResult DTO
+type SyncResult struct {
+	Pushed    int
+	Conflicts []string
+	Pulled    int
+	Deleted   int
+}
+
+// OfflineClientService wrap ClientService with local encrypted vault replica.
+// Reads fall back to replica and writes are queued in it while server is unavailable,
+// replica is maintained only after first Sync call created it.
+type OfflineClientService struct {
+	client    *ClientService
+	vaultPath string
+}
+
+// NewOfflineClientService construct new OfflineClientService.
+func NewOfflineClientService(client *ClientService, vaultPath string) *OfflineClientService {
+	return &OfflineClientService{
+		client:    client,
+		vaultPath: vaultPath,
+	}
+}
+
+// Register make Register rpc call.
+func (s *OfflineClientService) Register(ctx context.Context, login string, password string, secret string) (LoginResult, error) {
+	return s.client.Register(ctx, login, password, secret)
+}
+
+// Login make Login rpc call, replica is re-encrypted when vault key was migrated during login.
+func (s *OfflineClientService) Login(ctx context.Context, login string, password string, secret string, otpCode func() (string, error)) (LoginResult, error) {
+	result, err := s.client.Login(ctx, login, password, secret, otpCode)
+	if err != nil {
+		return result, err
+	}
+	if err := s.rekey(result); err != nil {
+		return LoginResult{}, err
+	}
+	return result, nil
+}
+
+// RegisterSRP make RegisterSrp rpc call.
+func (s *OfflineClientService) RegisterSRP(ctx context.Context, login string, password string, secret string) (LoginResult, error) {
+	return s.client.RegisterSRP(ctx, login, password, secret)
+}
+
+// LoginSRP login by SRP-6a exchange, replica is re-encrypted when vault key was migrated during login.
+func (s *OfflineClientService) LoginSRP(ctx context.Context, login string, password string, secret string, otpCode func() (string, error)) (LoginResult, error) {
+	result, err := s.client.LoginSRP(ctx, login, password, secret, otpCode)
+	if err != nil {
+		return result, err
+	}
+	if err := s.rekey(result); err != nil {
+		return LoginResult{}, err
+	}
+	return result, nil
+}
+
+// RotateSecret re-encrypt all user items and replica with vault key derived from new secret.
+func (s *OfflineClientService) RotateSecret(ctx context.Context, token string, secret string, newSecret string) (LoginResult, error) {
+	result, err := s.client.RotateSecret(ctx, token, secret, newSecret)
+	if err != nil {
+		return result, err
+	}
+	if err := s.rekey(result); err != nil {
+		return LoginResult{}, err
+	}
+	return result, nil
+}
+
+// Logout make Logout rpc call.
+func (s *OfflineClientService) Logout(ctx context.Context, token string) error {
+	return s.client.Logout(ctx, token)
+}
+
+// RevokeAllSessions make RevokeAllSessions rpc call.
+func (s *OfflineClientService) RevokeAllSessions(ctx context.Context, token string) error {
+	return s.client.RevokeAllSessions(ctx, token)
+}
+
+// StartOTPEnrollment make StartOtpEnrollment rpc call.
+func (s *OfflineClientService) StartOTPEnrollment(ctx context.Context, token string, password string) (string, error) {
+	return s.client.StartOTPEnrollment(ctx, token, password)
+}
+
+// StartOTPEnrollmentSRP make StartOtpEnrollment rpc call for account with zero-knowledge login.
+func (s *OfflineClientService) StartOTPEnrollmentSRP(ctx context.Context, token string, login string, password string) (string, error) {
+	return s.client.StartOTPEnrollmentSRP(ctx, token, login, password)
+}
+
+// ConfirmOTPEnrollment make ConfirmOtpEnrollment rpc call.
+func (s *OfflineClientService) ConfirmOTPEnrollment(ctx context.Context, token string, code string) ([]string, error) {
+	return s.client.ConfirmOTPEnrollment(ctx, token, code)
+}
+
+// DisableOTP make DisableOtp rpc call.
+func (s *OfflineClientService) DisableOTP(ctx context.Context, token string, password string, code string) error {
+	return s.client.DisableOTP(ctx, token, password, code)
+}
+
+// DisableOTPSRP make DisableOtp rpc call for account with zero-knowledge login.
+func (s *OfflineClientService) DisableOTPSRP(ctx context.Context, token string, login string, password string, code string) error {
+	return s.client.DisableOTPSRP(ctx, token, login, password, code)
+}
+
+// ChangePassword make ChangePassword rpc call.
+func (s *OfflineClientService) ChangePassword(ctx context.Context, token string, oldPassword string, newPassword string) error {
+	return s.client.ChangePassword(ctx, token, oldPassword, newPassword)
+}
+
+// DeleteAccount make DeleteAccount rpc call and remove replica of deleted account items.
+func (s *OfflineClientService) DeleteAccount(ctx context.Context, token string, password string) error {
+	if err := s.client.DeleteAccount(ctx, token, password); err != nil {
+		return err
+	}
+	if err := os.Remove(s.vaultPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove replica: %w", err)
+	}
+	return nil
+}
+
+// EnableSRP make SetSrpVerifier rpc call with current password.
+func (s *OfflineClientService) EnableSRP(ctx context.Context, token string, login string, password string, newPassword string) error {
+	return s.client.EnableSRP(ctx, token, login, password, newPassword)
+}
+
+// ChangeSRPPassword make SetSrpVerifier rpc call with current password proof.
+func (s *OfflineClientService) ChangeSRPPassword(ctx context.Context, token string, login string, password string, newPassword string) error {
+	return s.client.ChangeSRPPassword(ctx, token, login, password, newPassword)
+}
+
+// DeleteAccountSRP delete account with zero-knowledge login and remove replica of deleted account items.
+func (s *OfflineClientService) DeleteAccountSRP(ctx context.Context, token string, login string, password string) error {
+	if err := s.client.DeleteAccountSRP(ctx, token, login, password); err != nil {
+		return err
+	}
+	if err := os.Remove(s.vaultPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove replica: %w", err)
+	}
+	return nil
+}
+
+// ListSessions make ListSessions rpc call.
+func (s *OfflineClientService) ListSessions(ctx context.Context, token string) ([]Session, error) {
+	return s.client.ListSessions(ctx, token)
+}
+
+// RevokeSession make RevokeSession rpc call.
+func (s *OfflineClientService) RevokeSession(ctx context.Context, token string, id string) error {
+	return s.client.RevokeSession(ctx, token, id)
+}
+
+// List return items page from server or all items from replica as single page when server unavailable.
+// Replica does not keep items timestamps, so they are zero for items from replica.
+func (s *OfflineClientService) List(ctx context.Context, token string, secret string, pageSize int, pageToken string) ([]ItemSummary, string, error) {
+	items, nextPageToken, err := s.client.List(ctx, token, secret, pageSize, pageToken)
+	if !isUnavailable(err) {
+		return items, nextPageToken, err
+	}
+	v, vErr := loadVault(s.vaultPath, secret)
+	if vErr != nil {
+		return nil, "", err
+	}
+	if pageToken != "" {
+		return []ItemSummary{}, "", nil
+	}
+	items, err = v.summaries(ItemFilter{})
+	return items, "", err
+}
+
+// Search return items matching filter from server or from replica when server unavailable.
+func (s *OfflineClientService) Search(ctx context.Context, token string, secret string, filter ItemFilter) ([]ItemSummary, error) {
+	items, err := s.client.Search(ctx, token, secret, filter)
+	if !isUnavailable(err) {
+		return items, err
+	}
+	v, vErr := loadVault(s.vaultPath, secret)
+	if vErr != nil {
+		return nil, err
+	}
+	return v.summaries(filter)
+}
+
+// Get return item from server or from replica when server unavailable.
+func (s *OfflineClientService) Get(ctx context.Context, token string, secret string, name string) (Item, error) {
+	item, err := s.client.Get(ctx, token, secret, name)
+	if !isUnavailable(err) {
+		return item, err
+	}
+	v, vErr := loadVault(s.vaultPath, secret)
+	if vErr != nil {
+		return Item{}, err
+	}
+	item, ok := v.Items[name]
+	if !ok {
+		return Item{}, status.Error(codes.NotFound, "item not found in local vault")
+	}
+	return item, nil
+}
+
+// Add create item on server or queue creation in replica when server unavailable.
+func (s *OfflineClientService) Add(ctx context.Context, token string, secret string, item Item) error {
+	err := s.client.Add(ctx, token, secret, item)
+	if !isUnavailable(err) {
+		if err == nil {
+			s.refresh(ctx, token, secret)
+		}
+		return err
+	}
+	v, vErr := loadVault(s.vaultPath, secret)
+	if vErr != nil {
+		return err
+	}
+	if _, ok := v.Items[item.Name]; ok {
+		return status.Error(codes.AlreadyExists, "item already exist in local vault")
+	}
+	v.queue(vaultOperation{Type: operationCreate, Item: item})
+	v.Items[item.Name] = item
+	if err := v.save(s.vaultPath, secret); err != nil {
+		return err
+	}
+	return ErrOfflineQueued
+}
+
+// Update update item on server or queue updating in replica when server unavailable.
+func (s *OfflineClientService) Update(ctx context.Context, token string, secret string, item Item) error {
+	err := s.client.Update(ctx, token, secret, item)
+	if !isUnavailable(err) {
+		if err == nil {
+			s.refresh(ctx, token, secret)
+		}
+		return err
+	}
+	v, vErr := loadVault(s.vaultPath, secret)
+	if vErr != nil {
+		return err
+	}
+	if _, ok := v.Items[item.Name]; !ok {
+		return status.Error(codes.NotFound, "item not found in local vault")
+	}
+	v.queue(vaultOperation{Type: operationUpdate, Item: item, Revision: item.Revision})
+	v.Items[item.Name] = item
+	if err := v.save(s.vaultPath, secret); err != nil {
+		return err
+	}
+	return ErrOfflineQueued
+}
+
+// Delete remove item on server or queue removing in replica when server unavailable.
+// Item revision known by client is sent as expected one, see knownRevision.
+func (s *OfflineClientService) Delete(ctx context.Context, token string, secret string, name string) error {
+	v, vErr := loadVault(s.vaultPath, secret)
+	revision, err := s.knownRevision(ctx, token, secret, name, v)
+	if err != nil {
+		return err
+	}
+	err = s.client.Delete(ctx, token, secret, name, revision)
+	if !isUnavailable(err) {
+		if err == nil {
+			s.refresh(ctx, token, secret)
+		}
+		return err
+	}
+	if vErr != nil {
+		return err
+	}
+	item, ok := v.Items[name]
+	if !ok {
+		return status.Error(codes.NotFound, "item not found in local vault")
+	}
+	v.queue(vaultOperation{Type: operationDelete, Item: Item{Name: name}, Revision: item.Revision})
+	delete(v.Items, name)
+	if err := v.save(s.vaultPath, secret); err != nil {
+		return err
+	}
+	return ErrOfflineQueued
+}
+
+// EnableZeroKnowledge hide identity of items from server, see ClientService.EnableZeroKnowledge.
+// Replica keeps items by their names, so only revisions of moved items are refreshed.
+func (s *OfflineClientService) EnableZeroKnowledge(ctx context.Context, token string, secret string) error {
+	if err := s.client.EnableZeroKnowledge(ctx, token, secret); err != nil {
+		return err
+	}
+	s.refresh(ctx, token, secret)
+	return nil
+}
+
+// History make GetItemHistory rpc call.
+func (s *OfflineClientService) History(ctx context.Context, token string, secret string, name string) ([]ItemVersion, error) {
+	return s.client.History(ctx, token, secret, name)
+}
+
+// Restore make RestoreItemVersion rpc call, item revision known by client is sent as expected one, see knownRevision.
+func (s *OfflineClientService) Restore(ctx context.Context, token string, secret string, name string, version int64) error {
+	v, _ := loadVault(s.vaultPath, secret)
+	revision, err := s.knownRevision(ctx, token, secret, name, v)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Restore(ctx, token, secret, name, version, revision); err != nil {
+		return err
+	}
+	s.refresh(ctx, token, secret)
+	return nil
+}
+
+// knownRevision return item revision from replica or from server when replica doesn't keep item,
+// so item changed by another client since it was received is not overwritten or removed.
+// Zero revision is returned when server is unavailable.
+func (s *OfflineClientService) knownRevision(ctx context.Context, token string, secret string, name string, v *vault) (int64, error) {
+	if v != nil {
+		if item, ok := v.Items[name]; ok {
+			return item.Revision, nil
+		}
+	}
+	item, err := s.client.Get(ctx, token, secret, name)
+	if err != nil && !isUnavailable(err) {
+		return 0, err
+	}
+	return item.Revision, nil
+}
+
+// Watch make WatchItems rpc call, replica is refreshed before passing every event to handler
+// and when server aborts watching because events were missed.
+func (s *OfflineClientService) Watch(ctx context.Context, token string, secret string, handler func(event ItemEvent) error) error {
+	err := s.client.Watch(ctx, token, func(event ItemEvent) error {
+		s.refresh(ctx, token, secret)
+		return handler(event)
+	})
+	if status.Code(err) == codes.Aborted {
+		s.refresh(ctx, token, secret)
+	}
+	return err
+}
+
+// Sync replay queued changes on server and receive server changes into replica.
+// Queued change conflicting with server state is kept on server as item copy named "<name>.conflict-<unix time>".
+func (s *OfflineClientService) Sync(ctx context.Context, token string, secret string) (SyncResult, error) {
+	var result SyncResult
+	v, err := loadVault(s.vaultPath, secret)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return result, err
+		}
+		v = newVault()
+	}
+
+	for i, op := range v.Pending {
+		err := s.push(ctx, token, secret, op)
+		switch status.Code(err) {
+		case codes.OK:
+			result.Pushed++
+			continue
+		case codes.AlreadyExists, codes.FailedPrecondition, codes.NotFound:
+			if op.Type != operationDelete {
+				conflicted := op.Item
+				conflicted.Name = fmt.Sprintf("%s.conflict-%d", op.Item.Name, time.Now().Unix())
+				err = s.client.Add(ctx, token, secret, conflicted)
+			} else {
+				err = nil
+			}
+			if err == nil {
+				result.Conflicts = append(result.Conflicts, op.Item.Name)
+				continue
+			}
+		}
+		v.Pending = v.Pending[i:]
+		if sErr := v.save(s.vaultPath, secret); sErr != nil {
+			return result, sErr
+		}
+		return result, err
+	}
+	v.Pending = nil
+
+	changes, err := s.changes(ctx, token, secret, v)
+	if err != nil {
+		if sErr := v.save(s.vaultPath, secret); sErr != nil {
+			return result, sErr
+		}
+		return result, err
+	}
+	v.apply(changes)
+	result.Pulled = len(changes.Items)
+	result.Deleted = len(changes.Deleted)
+
+	return result, v.save(s.vaultPath, secret)
+}
+
+func (s *OfflineClientService) push(ctx context.Context, token string, secret string, op vaultOperation) error {
+	switch op.Type {
+	case operationCreate:
+		return s.client.Add(ctx, token, secret, op.Item)
+	case operationUpdate:
+		item := op.Item
+		item.Revision = op.Revision
+		return s.client.Update(ctx, token, secret, item)
+	case operationDelete:
+		return s.client.Delete(ctx, token, secret, op.Item.Name, op.Revision)
+	default:
+		return fmt.Errorf("unknown vault operation: %s", op.Type)
+	}
+}
+
+// refresh receive server changes into existing replica, errors are ignored as replica is updated on next sync.
+func (s *OfflineClientService) refresh(ctx context.Context, token string, secret string) {
+	v, err := loadVault(s.vaultPath, secret)
+	if err != nil {
+		return
+	}
+	changes, err := s.changes(ctx, token, secret, v)
+	if err != nil {
+		return
+	}
+	v.apply(changes)
+	//goland:noinspection GoUnhandledErrorResult
+	v.save(s.vaultPath, secret)
+}
+
+// changes receive server changes since last replica update with deleted items named as in replica.
+func (s *OfflineClientService) changes(ctx context.Context, token string, secret string, v *vault) (ItemChanges, error) {
+	changes, err := s.client.Changes(ctx, token, secret, v.Cursor)
+	if err != nil || len(changes.Deleted) == 0 {
+		return changes, err
+	}
+	names := make([]string, 0, len(v.Items))
+	for name := range v.Items {
+		names = append(names, name)
+	}
+	serverNames, err := s.client.itemNames(ctx, token, secret, names)
+	if err != nil {
+		return ItemChanges{}, err
+	}
+	deleted := make([]string, 0, len(changes.Deleted))
+	for _, serverName := range changes.Deleted {
+		if name, ok := serverNames[serverName]; ok {
+			deleted = append(deleted, name)
+		}
+	}
+	changes.Deleted = deleted
+	return changes, nil
+}
+
+// rekey re-encrypt replica when vault key was changed.
+func (s *OfflineClientService) rekey(result LoginResult) error {
+	if result.PreviousKey == "" {
+		return nil
+	}
+	v, err := loadVault(s.vaultPath, result.PreviousKey)
+	if err != nil {
+		// Replica is absent or already re-encrypted.
+		return nil
+	}
+	return v.save(s.vaultPath, result.Key)
+}
+
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// vaultOperation is queued change for replaying on server, revision is item revision change was based on.
+type vaultOperation struct {
+	Type     string `json:"type"`
+	Item     Item   `json:"item"`
+	Revision int64  `json:"revision"`
+}
+
+// vault is local replica of user items with queued changes.
+type vault struct {
+	Cursor  int64            `json:"cursor"`
+	Items   map[string]Item  `json:"items"`
+	Pending []vaultOperation `json:"pending"`
+}
+
+func newVault() *vault {
+	return &vault{
+		Items: map[string]Item{},
+	}
+}
+
+func loadVault(path string, secret string) (*vault, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading vault file: %w", err)
+	}
+	data, err = decryptData(secret, data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting vault file: %w", err)
+	}
+	v := newVault()
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("unmarshaling vault: %w", err)
+	}
+	return v, nil
+}
+
+// save write vault to temporary file synced to disk and move it over existing one, so crash never leaves broken vault.
+func (v *vault) save(path string, secret string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling vault: %w", err)
+	}
+	data, err = encryptData(secret, data)
+	if err != nil {
+		return fmt.Errorf("encrypting vault: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("creating vault file: %w", err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		//goland:noinspection GoUnhandledErrorResult
+		f.Close()
+		return fmt.Errorf("saving vault: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		//goland:noinspection GoUnhandledErrorResult
+		f.Close()
+		return fmt.Errorf("saving vault: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("saving vault: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("saving vault: %w", err)
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("saving vault: %w", err)
+	}
+	return nil
+}
+
+// syncDir flush directory entries, so renamed file survives crash.
+func syncDir(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer d.Close()
+	return d.Sync()
+}
+
+// queue add operation merging it with already queued operation for the same item,
+// so every item has at most one queued operation based on last synced revision.
+func (v *vault) queue(op vaultOperation) {
+	for i, queued := range v.Pending {
+		if queued.Item.Name != op.Item.Name {
+			continue
+		}
+		switch {
+		case queued.Type == operationCreate && op.Type == operationDelete:
+			v.Pending = append(v.Pending[:i], v.Pending[i+1:]...)
+		case queued.Type == operationDelete && op.Type == operationCreate:
+			v.Pending[i] = vaultOperation{Type: operationUpdate, Item: op.Item, Revision: queued.Revision}
+		case op.Type == operationDelete:
+			v.Pending[i] = vaultOperation{Type: operationDelete, Item: op.Item, Revision: queued.Revision}
+		default:
+			v.Pending[i].Item = op.Item
+		}
+		return
+	}
+	v.Pending = append(v.Pending, op)
+}
+
+// summaries return summaries of vault items matching filter ordered by name, vault does not keep items timestamps.
+func (v *vault) summaries(filter ItemFilter) ([]ItemSummary, error) {
+	items := make([]ItemSummary, 0, len(v.Items))
+	for _, item := range v.Items {
+		summary := ItemSummary{
+			Name:     item.Name,
+			Type:     item.Type,
+			Metadata: item.Metadata,
+		}
+		items = append(items, summary)
+	}
+	return filterSummaries(items, filter)
+}
+
+// apply store server changes in vault, items with queued operations keep local state.
+func (v *vault) apply(changes ItemChanges) {
+	pending := make(map[string]bool, len(v.Pending))
+	for _, op := range v.Pending {
+		pending[op.Item.Name] = true
+	}
+	for _, name := range changes.Deleted {
+		if !pending[name] {
+			delete(v.Items, name)
+		}
+	}
+	for _, item := range changes.Items {
+		if !pending[item.Name] {
+			v.Items[item.Name] = item
+		}
+	}
+	v.Cursor = changes.Cursor
+}
@@ -0,0 +1,260 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "keeper/gen/service"
+	"keeper/internal/services"
+)
+
+// switchableClient fail item calls with Unavailable code while client is offline.
+type switchableClient struct {
+	pb.KeeperServiceClient
+	offline *bool
+}
+
+var errServerUnavailable = status.Error(codes.Unavailable, "connection refused")
+
+func (c switchableClient) CreateItem(ctx context.Context, in *pb.CreateItemRequest, opts ...grpc.CallOption) (*pb.CreateItemResponse, error) {
+	if *c.offline {
+		return nil, errServerUnavailable
+	}
+	return c.KeeperServiceClient.CreateItem(ctx, in, opts...)
+}
+
+func (c switchableClient) UpdateItem(ctx context.Context, in *pb.UpdateItemRequest, opts ...grpc.CallOption) (*pb.UpdateItemResponse, error) {
+	if *c.offline {
+		return nil, errServerUnavailable
+	}
+	return c.KeeperServiceClient.UpdateItem(ctx, in, opts...)
+}
+
+func (c switchableClient) GetItem(ctx context.Context, in *pb.GetItemRequest, opts ...grpc.CallOption) (*pb.GetItemResponse, error) {
+	if *c.offline {
+		return nil, errServerUnavailable
+	}
+	return c.KeeperServiceClient.GetItem(ctx, in, opts...)
+}
+
+func (c switchableClient) DeleteItem(ctx context.Context, in *pb.DeleteItemRequest, opts ...grpc.CallOption) (*pb.DeleteItemResponse, error) {
+	if *c.offline {
+		return nil, errServerUnavailable
+	}
+	return c.KeeperServiceClient.DeleteItem(ctx, in, opts...)
+}
+
+func (c switchableClient) GetItemsList(ctx context.Context, in *pb.GetItemsListRequest, opts ...grpc.CallOption) (*pb.GetItemsListResponse, error) {
+	if *c.offline {
+		return nil, errServerUnavailable
+	}
+	return c.KeeperServiceClient.GetItemsList(ctx, in, opts...)
+}
+
+func (c switchableClient) SearchItems(ctx context.Context, in *pb.SearchItemsRequest, opts ...grpc.CallOption) (*pb.SearchItemsResponse, error) {
+	if *c.offline {
+		return nil, errServerUnavailable
+	}
+	return c.KeeperServiceClient.SearchItems(ctx, in, opts...)
+}
+
+func (c switchableClient) GetItemChanges(ctx context.Context, in *pb.GetItemChangesRequest, opts ...grpc.CallOption) (*pb.GetItemChangesResponse, error) {
+	if *c.offline {
+		return nil, errServerUnavailable
+	}
+	return c.KeeperServiceClient.GetItemChanges(ctx, in, opts...)
+}
+
+// itemNames return sorted names of item summaries.
+func itemNames(items []services.ItemSummary) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOfflineDeleteRevision(t *testing.T) {
+	tests := []struct {
+		name string
+		// sync creates replica of the first client before item is changed by the second one.
+		sync     bool
+		wantCode codes.Code
+	}{
+		{name: "replica has changed item", sync: true, wantCode: codes.FailedPrecondition},
+		{name: "without replica", wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, _ := newTestServer(t)
+			first := services.NewOfflineClientService(
+				services.NewClientService(client, services.Device{Name: "first"}),
+				filepath.Join(t.TempDir(), "vault"),
+			)
+			second := services.NewClientService(client, services.Device{Name: "second"})
+
+			res, err := first.Register(ctx, "user", "password", "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := first.Add(ctx, res.Token, res.Key, services.Item{Name: "note", Type: "text", Data: []byte("v1")}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.sync {
+				if _, err := first.Sync(ctx, res.Token, res.Key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			item, err := second.Get(ctx, res.Token, res.Key, "note")
+			if err != nil {
+				t.Fatal(err)
+			}
+			item.Data = []byte("v2")
+			if err := second.Update(ctx, res.Token, res.Key, item); err != nil {
+				t.Fatal(err)
+			}
+
+			err = first.Delete(ctx, res.Token, res.Key, "note")
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Delete() error = %v, want code %s", err, tt.wantCode)
+			}
+			_, err = second.Get(ctx, res.Token, res.Key, "note")
+			if deleted := status.Code(err) == codes.NotFound; deleted != (tt.wantCode == codes.OK) {
+				t.Errorf("item deleted = %v after Delete() with code %s", deleted, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOfflineSync(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestServer(t)
+	var offline bool
+	first := services.NewOfflineClientService(
+		services.NewClientService(switchableClient{KeeperServiceClient: client, offline: &offline}, services.Device{Name: "first"}),
+		filepath.Join(t.TempDir(), "vault"),
+	)
+	second := services.NewClientService(client, services.Device{Name: "second"})
+
+	res, err := first.Register(ctx, "user", "password", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"bank", "mail", "note"} {
+		if err := first.Add(ctx, res.Token, res.Key, services.Item{Name: name, Type: "text", Data: []byte(name + " v1")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result, err := first.Sync(ctx, res.Token, res.Key); err != nil || result.Pulled != 3 {
+		t.Fatalf("first Sync() = %+v, %v, want 3 pulled items", result, err)
+	}
+
+	// Second client changes note after first one went offline with its replica.
+	note, err := second.Get(ctx, res.Token, res.Key, "note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	note.Data = []byte("note v2")
+	if err := second.Update(ctx, res.Token, res.Key, note); err != nil {
+		t.Fatal(err)
+	}
+
+	offline = true
+	if item, err := first.Get(ctx, res.Token, res.Key, "bank"); err != nil || string(item.Data) != "bank v1" {
+		t.Fatalf("offline Get() = %s, %v, want item from replica", item.Data, err)
+	}
+	changes := []struct {
+		name   string
+		change func() error
+	}{
+		{name: "add", change: func() error {
+			return first.Add(ctx, res.Token, res.Key, services.Item{Name: "card", Type: "text", Data: []byte("card v1")})
+		}},
+		{name: "add removed later", change: func() error {
+			return first.Add(ctx, res.Token, res.Key, services.Item{Name: "draft", Type: "text", Data: []byte("draft")})
+		}},
+		{name: "remove added", change: func() error { return first.Delete(ctx, res.Token, res.Key, "draft") }},
+		{name: "delete", change: func() error { return first.Delete(ctx, res.Token, res.Key, "bank") }},
+		{name: "update", change: func() error {
+			item, err := first.Get(ctx, res.Token, res.Key, "mail")
+			if err != nil {
+				return err
+			}
+			item.Data = []byte("mail v2")
+			return first.Update(ctx, res.Token, res.Key, item)
+		}},
+		{name: "update changed on server", change: func() error {
+			item, err := first.Get(ctx, res.Token, res.Key, "note")
+			if err != nil {
+				return err
+			}
+			item.Data = []byte("note v2 offline")
+			return first.Update(ctx, res.Token, res.Key, item)
+		}},
+	}
+	for _, c := range changes {
+		if err := c.change(); !errors.Is(err, services.ErrOfflineQueued) {
+			t.Fatalf("offline %s error = %v, want %v", c.name, err, services.ErrOfflineQueued)
+		}
+	}
+	items, _, err := first.List(ctx, res.Token, res.Key, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(itemNames(items), ","), "card,mail,note"; got != want {
+		t.Errorf("offline List() = %s, want %s", got, want)
+	}
+
+	offline = false
+	result, err := first.Sync(ctx, res.Token, res.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pushed != 3 || len(result.Conflicts) != 1 || result.Conflicts[0] != "note" {
+		t.Errorf("Sync() = %+v, want 3 pushed changes and note conflict", result)
+	}
+
+	items, _, err = second.List(ctx, res.Token, res.Key, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := itemNames(items)
+	if len(names) != 4 || strings.Join(names[:3], ",") != "card,mail,note" || !strings.HasPrefix(names[3], "note.conflict-") {
+		t.Fatalf("server items = %v, want card, mail, note and note conflict copy", names)
+	}
+	want := map[string]string{"card": "card v1", "mail": "mail v2", "note": "note v2", names[3]: "note v2 offline"}
+	for name, data := range want {
+		item, err := second.Get(ctx, res.Token, res.Key, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(item.Data) != data {
+			t.Errorf("server item %s = %s, want %s", name, item.Data, data)
+		}
+	}
+
+	// Replica keeps server state after sync.
+	offline = true
+	for name, data := range want {
+		item, err := first.Get(ctx, res.Token, res.Key, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(item.Data) != data {
+			t.Errorf("replica item %s = %s, want %s", name, item.Data, data)
+		}
+	}
+	if _, err := first.Get(ctx, res.Token, res.Key, "bank"); status.Code(err) != codes.NotFound {
+		t.Errorf("deleted item Get() from replica error = %v, want code %s", err, codes.NotFound)
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+	"keeper/pkg/totp"
+)
+
+const (
+	otpIssuer = "Keeper"
+	// otpSkew is number of time steps one-time passwords are accepted before and after current one.
+	otpSkew              = 1
+	otpChallengeLifetime = 5 * time.Minute
+	// otpChallengeAttempts is number of wrong one-time passwords login challenge is dropped after.
+	otpChallengeAttempts = 5
+	recoveryCodesCount   = 10
+	// recoveryCodeLength is length in bytes of random recovery code, it is shown as two groups of base32 letters.
+	recoveryCodeLength = 5
+)
+
+var (
+	ErrOTPInvalid              = errors.New("invalid one-time password")
+	ErrOTPEnabled              = errors.New("two-factor authentication is already enabled")
+	ErrOTPNotEnabled           = errors.New("two-factor authentication is not enabled")
+	ErrOTPEnrollmentNotStarted = errors.New("two-factor authentication enrollment is not started")
+
+	recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// StartOTPEnrollment generate new user TOTP secret and return its provisioning URI for authenticator application
+// after checking current password or proof of started SRP exchange for users with zero-knowledge login.
+// Secret is not used for login till enrollment is confirmed with one-time password.
+func (s *AuthService) StartOTPEnrollment(ctx context.Context, login string, password string, proof SRPProof, ip string) (string, error) {
+	user, err := s.verifyCredentials(ctx, login, password, proof, ip)
+	if err != nil {
+		return "", err
+	}
+	otp := copyOTP(user.OTP)
+	if otp.Secret != nil {
+		return "", ErrOTPEnabled
+	}
+	otp.PendingSecret, err = totp.NewSecret()
+	if err != nil {
+		return "", fmt.Errorf("otp secret generate: %w", err)
+	}
+	if err := s.userRepository.SwapOTP(ctx, login, user.OTP, &otp); err != nil {
+		return "", err
+	}
+	return totp.URI(otpIssuer, login, otp.PendingSecret), nil
+}
+
+// ConfirmOTPEnrollment enable two-factor authentication when one-time password matches pending secret
+// and return recovery codes for login without authenticator application.
+func (s *AuthService) ConfirmOTPEnrollment(ctx context.Context, login string, code string) ([]string, error) {
+	user, err := s.userRepository.GetByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	otp := copyOTP(user.OTP)
+	if otp.Secret != nil {
+		return nil, ErrOTPEnabled
+	}
+	if otp.PendingSecret == nil {
+		return nil, ErrOTPEnrollmentNotStarted
+	}
+	step, ok := totp.Validate(otp.PendingSecret, code, time.Now(), otpSkew)
+	if !ok {
+		return nil, ErrOTPInvalid
+	}
+
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("recovery code generate: %w", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, tokenHash(normalizeRecoveryCode(code)))
+	}
+	enabled := entity.OTP{
+		Secret:             otp.PendingSecret,
+		RecoveryCodeHashes: hashes,
+		LastStep:           step,
+	}
+	if err := s.swapCheckedOTP(ctx, login, user.OTP, &enabled); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableOTP disable user two-factor authentication, current password or proof of started SRP exchange
+// for users with zero-knowledge login and one-time password or recovery code are required.
+// Wrong passwords and codes are counted as failed login attempts.
+func (s *AuthService) DisableOTP(ctx context.Context, login string, password string, proof SRPProof, code string, ip string) error {
+	if err := s.reserveAttempt(ctx, login, ip); err != nil {
+		return err
+	}
+	user, err := s.checkCredentials(ctx, login, password, proof)
+	if err != nil {
+		return err
+	}
+	otp := copyOTP(user.OTP)
+	if otp.Secret == nil {
+		if err := s.releaseAttempt(ctx, login, ip); err != nil {
+			return err
+		}
+		return ErrOTPNotEnabled
+	}
+	if !checkOTP(&otp, code, time.Now()) {
+		return ErrOTPInvalid
+	}
+	if err := s.swapCheckedOTP(ctx, login, user.OTP, nil); err != nil {
+		return err
+	}
+	return s.resetAttempts(ctx, login, ip)
+}
+
+// VerifyOTP finish login of user with two-factor authentication by login challenge and one-time password
+// or recovery code, new user tokens are issued to device. Challenge is dropped after few wrong passwords,
+// wrong passwords are counted as failed login attempts too.
+func (s *AuthService) VerifyOTP(ctx context.Context, login string, challenge string, code string, device Device) (AuthResult, error) {
+	if err := s.reserveAttempt(ctx, login, device.IP); err != nil {
+		return AuthResult{}, err
+	}
+	user, err := s.userRepository.GetByLogin(ctx, login)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	otp := copyOTP(user.OTP)
+	now := time.Now()
+	if otp.Secret == nil || otp.ChallengeHash == "" || now.After(otp.ChallengeExpiresAt) ||
+		!checkTokenSecret(challenge, otp.ChallengeHash) {
+		return AuthResult{}, ErrOTPInvalid
+	}
+
+	valid := checkOTP(&otp, code, now)
+	if valid {
+		otp.ChallengeHash = ""
+	} else {
+		otp.ChallengeAttempts++
+		if otp.ChallengeAttempts >= otpChallengeAttempts {
+			otp.ChallengeHash = ""
+		}
+	}
+	if err := s.swapCheckedOTP(ctx, login, user.OTP, &otp); err != nil {
+		return AuthResult{}, err
+	}
+	user.OTP = &otp
+	if !valid {
+		return AuthResult{}, ErrOTPInvalid
+	}
+	if err := s.resetAttempts(ctx, login, device.IP); err != nil {
+		return AuthResult{}, err
+	}
+
+	token, refreshToken, err := s.createToken(ctx, user, device)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("user token generate: %w", err)
+	}
+	return userEntityToAuthResult(user, token, refreshToken), nil
+}
+
+// startOTPChallenge store new login challenge of user with two-factor authentication and return it.
+func (s *AuthService) startOTPChallenge(ctx context.Context, user entity.User) (string, error) {
+	challenge, err := randomString(tokenSecretLength)
+	if err != nil {
+		return "", fmt.Errorf("otp challenge generate: %w", err)
+	}
+	otp := copyOTP(user.OTP)
+	otp.ChallengeHash = tokenHash(challenge)
+	otp.ChallengeExpiresAt = time.Now().Add(otpChallengeLifetime)
+	otp.ChallengeAttempts = 0
+	if err := s.userRepository.SwapOTP(ctx, user.Login, user.OTP, &otp); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// swapCheckedOTP store two-factor authentication state changed by checked one-time password or recovery code.
+// State changed by concurrent request means the same password could be used twice, so it is rejected.
+func (s *AuthService) swapCheckedOTP(ctx context.Context, login string, old *entity.OTP, otp *entity.OTP) error {
+	err := s.userRepository.SwapOTP(ctx, login, old, otp)
+	if errors.Is(err, repository.ErrOTPChanged) {
+		return ErrOTPInvalid
+	}
+	return err
+}
+
+// checkOTP check one-time password or recovery code.
+// Time step of password is remembered, so password can't be reused, used recovery code is removed.
+func checkOTP(otp *entity.OTP, code string, now time.Time) bool {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(otp.Secret, code, now, otpSkew)
+		if !ok || step <= otp.LastStep {
+			return false
+		}
+		otp.LastStep = step
+		return true
+	}
+
+	hash := tokenHash(normalizeRecoveryCode(code))
+	for i, h := range otp.RecoveryCodeHashes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			hashes := make([]string, 0, len(otp.RecoveryCodeHashes)-1)
+			hashes = append(hashes, otp.RecoveryCodeHashes[:i]...)
+			otp.RecoveryCodeHashes = append(hashes, otp.RecoveryCodeHashes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// newRecoveryCode return random recovery code formatted as two groups of letters and digits.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeLength)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+	return code[:len(code)/2] + "-" + code[len(code)/2:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// copyOTP return copy of user two-factor authentication state, so stored entity is never changed in place.
+func copyOTP(otp *entity.OTP) entity.OTP {
+	if otp == nil {
+		return entity.OTP{}
+	}
+	return *otp
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository/file"
+	"keeper/internal/repository/memory"
+	"keeper/pkg/totp"
+)
+
+func TestCheckOTP(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	recovery, err := newRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otp := entity.OTP{
+		Secret:             secret,
+		RecoveryCodeHashes: []string{tokenHash(normalizeRecoveryCode(recovery))},
+		LastStep:           totp.Step(now) - 2,
+	}
+
+	// Steps share otp state, so every step checks result of previous ones.
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "wrong code", code: "000000", want: false},
+		{name: "previous step", code: totp.Code(secret, now.Add(-totp.Period)), want: true},
+		{name: "previous step reused", code: totp.Code(secret, now.Add(-totp.Period)), want: false},
+		{name: "current step", code: " " + totp.Code(secret, now) + " ", want: true},
+		{name: "current step reused", code: totp.Code(secret, now), want: false},
+		{name: "older step after newer one", code: totp.Code(secret, now.Add(-totp.Period)), want: false},
+		{name: "wrong recovery code", code: "aaaa-bbbb-cccc", want: false},
+		{name: "recovery code", code: strings.ToUpper(strings.ReplaceAll(recovery, "-", "")), want: true},
+		{name: "recovery code reused", code: recovery, want: false},
+	}
+	for _, tt := range tests {
+		if got := checkOTP(&otp, tt.code, now); got != tt.want {
+			t.Fatalf("%s: checkOTP() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if otp.LastStep != totp.Step(now) {
+		t.Errorf("LastStep = %d, want %d", otp.LastStep, totp.Step(now))
+	}
+	if len(otp.RecoveryCodeHashes) != 0 {
+		t.Errorf("used recovery code is not removed")
+	}
+}
+
+// barrierUserRepository hold user reads till given number of readers got user, so they all read the same state.
+type barrierUserRepository struct {
+	UserRepository
+	readers sync.WaitGroup
+}
+
+func (r *barrierUserRepository) GetByLogin(ctx context.Context, login string) (entity.User, error) {
+	user, err := r.UserRepository.GetByLogin(ctx, login)
+	r.readers.Done()
+	r.readers.Wait()
+	return user, err
+}
+
+func TestVerifyOTPConcurrent(t *testing.T) {
+	userRepositories := []struct {
+		name       string
+		repository func(t *testing.T) UserRepository
+	}{
+		{
+			name: "memory",
+			repository: func(t *testing.T) UserRepository {
+				return memory.NewUserRepository()
+			},
+		},
+		{
+			name: "file",
+			repository: func(t *testing.T) UserRepository {
+				return file.NewUserRepository(newTestDB(t))
+			},
+		},
+	}
+	for _, tt := range userRepositories {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepository := tt.repository(t)
+			barrier := barrierUserRepository{UserRepository: userRepository}
+			s := NewAuthService(
+				&UuidGenerator{},
+				&BCryptPasswordHasher{Cost: 4},
+				&barrier,
+				memory.NewTokenRepository(time.Hour),
+				memory.NewItemRepository(),
+				memory.NewAttemptRepository(),
+				memory.NewSRPExchangeRepository(),
+				[]byte("secret"),
+				time.Hour,
+			)
+			if err := userRepository.Create(ctx, entity.User{ID: "id", Login: "user"}); err != nil {
+				t.Fatal(err)
+			}
+			secret := []byte("12345678901234567890")
+			otp := entity.OTP{
+				Secret:             secret,
+				ChallengeHash:      tokenHash("challenge"),
+				ChallengeExpiresAt: time.Now().Add(otpChallengeLifetime),
+			}
+			if err := userRepository.SwapOTP(ctx, "user", nil, &otp); err != nil {
+				t.Fatal(err)
+			}
+
+			// Every request reads the same state, only one of them may spend the code.
+			code := totp.Code(secret, time.Now())
+			const requests = 5
+			barrier.readers.Add(requests)
+			errs := make(chan error, requests)
+			var wg sync.WaitGroup
+			for i := 0; i < requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := s.VerifyOTP(ctx, "user", "challenge", code, Device{})
+					errs <- err
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			var passed int
+			for err := range errs {
+				switch {
+				case err == nil:
+					passed++
+				case !errors.Is(err, ErrOTPInvalid):
+					t.Fatalf("VerifyOTP() error = %v, want %v", err, ErrOTPInvalid)
+				}
+			}
+			if passed != 1 {
+				t.Errorf("VerifyOTP() passed %d times, want once", passed)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	argon2idPrefix       = "$argon2id$"
+	argon2idSaltLength   = 16
+	argon2idKeyLength    = 32
+	defaultArgon2Memory  = 64 * 1024
+	defaultArgon2Time    = 3
+	defaultArgon2Threads = 4
+	defaultBCryptCost    = 13
+)
+
+var (
+	ErrPasswordHashFormat = errors.New("invalid password hash format")
+
+	phcEncoding = base64.RawStdEncoding
+)
+
+// Argon2idPasswordHasher contains implementation for creating Argon2id password hash in PHC string format and checks.
+// Memory is set in KiB, zero parameters are replaced with defaults.
+type Argon2idPasswordHasher struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+// Generate creates password hash.
+func (ph *Argon2idPasswordHasher) Generate(password string) (string, error) {
+	params := ph.params()
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, argon2idKeyLength)
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		params.memory,
+		params.time,
+		params.threads,
+		phcEncoding.EncodeToString(salt),
+		phcEncoding.EncodeToString(key),
+	), nil
+}
+
+// Check compare password and password hash, bcrypt hashes are checked too.
+func (ph *Argon2idPasswordHasher) Check(password string, hash string) bool {
+	return checkPasswordHash(password, hash)
+}
+
+// NeedsRehash report that hash is not Argon2id hash or its parameters are lower than configured.
+func (ph *Argon2idPasswordHasher) NeedsRehash(hash string) bool {
+	params, _, _, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+	want := ph.params()
+	return params.memory < want.memory || params.time < want.time || params.threads < want.threads
+}
+
+func (ph *Argon2idPasswordHasher) params() argon2idParams {
+	params := argon2idParams{
+		memory:  ph.Memory,
+		time:    ph.Time,
+		threads: ph.Threads,
+	}
+	if params.memory == 0 {
+		params.memory = defaultArgon2Memory
+	}
+	if params.time == 0 {
+		params.time = defaultArgon2Time
+	}
+	if params.threads == 0 {
+		params.threads = defaultArgon2Threads
+	}
+	return params
+}
+
+type argon2idParams struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// parseArgon2idHash return parameters, salt and key of Argon2id hash in PHC string format.
+func parseArgon2idHash(hash string) (argon2idParams, []byte, []byte, error) {
+	if !strings.HasPrefix(hash, argon2idPrefix) {
+		return argon2idParams{}, nil, nil, ErrPasswordHashFormat
+	}
+	parts := strings.Split(strings.TrimPrefix(hash, argon2idPrefix), "$")
+	if len(parts) != 4 {
+		return argon2idParams{}, nil, nil, ErrPasswordHashFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[0], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2idParams{}, nil, nil, ErrPasswordHashFormat
+	}
+	var params argon2idParams
+	if _, err := fmt.Sscanf(parts[1], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return argon2idParams{}, nil, nil, ErrPasswordHashFormat
+	}
+	if params.time == 0 || params.threads == 0 {
+		return argon2idParams{}, nil, nil, ErrPasswordHashFormat
+	}
+	salt, err := phcEncoding.DecodeString(parts[2])
+	if err != nil {
+		return argon2idParams{}, nil, nil, ErrPasswordHashFormat
+	}
+	key, err := phcEncoding.DecodeString(parts[3])
+	if err != nil || len(key) == 0 {
+		return argon2idParams{}, nil, nil, ErrPasswordHashFormat
+	}
+	return params, salt, key, nil
+}
+
+// checkPasswordHash compare password with Argon2id or bcrypt hash, so hashes of both algorithms stay valid
+// when server algorithm is changed and they are upgraded on login.
+func checkPasswordHash(password string, hash string) bool {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		params, salt, key, err := parseArgon2idHash(hash)
+		if err != nil {
+			return false
+		}
+		actual := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(actual, key) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"keeper/internal/repository/memory"
+)
+
+func TestPasswordHasherCheck(t *testing.T) {
+	argon2id, err := (&Argon2idPasswordHasher{Memory: 64, Time: 1, Threads: 1}).Generate("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcrypt, err := (&BCryptPasswordHasher{Cost: 4}).Generate("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(argon2id, "$")
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		want     bool
+	}{
+		{name: "argon2id", password: "password", hash: argon2id, want: true},
+		{name: "argon2id wrong password", password: "passwore", hash: argon2id},
+		{name: "bcrypt", password: "password", hash: bcrypt, want: true},
+		{name: "bcrypt wrong password", password: "passwore", hash: bcrypt},
+		{name: "empty hash", password: "password", hash: ""},
+		{name: "argon2id unknown version", password: "password", hash: strings.Replace(argon2id, "v=19", "v=16", 1)},
+		{name: "argon2id zero time", password: "password", hash: strings.Replace(argon2id, "t=1", "t=0", 1)},
+		{name: "argon2id without key", password: "password", hash: strings.Join(parts[:len(parts)-1], "$")},
+		{name: "argon2id invalid salt", password: "password", hash: strings.Replace(argon2id, parts[4], "!", 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, hasher := range []PasswordHasher{&Argon2idPasswordHasher{}, &BCryptPasswordHasher{}} {
+				if got := hasher.Check(tt.password, tt.hash); got != tt.want {
+					t.Errorf("%T.Check() = %v, want %v", hasher, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	weak := &Argon2idPasswordHasher{Memory: 64, Time: 1, Threads: 1}
+	argon2id, err := weak.Generate("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcrypt, err := (&BCryptPasswordHasher{Cost: 4}).Generate("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		hasher PasswordHasher
+		hash   string
+		want   bool
+	}{
+		{name: "argon2id same parameters", hasher: weak, hash: argon2id},
+		{name: "argon2id hash with lower memory", hasher: weak, hash: strings.Replace(argon2id, "m=64", "m=32", 1), want: true},
+		{name: "argon2id hash with higher memory", hasher: weak, hash: strings.Replace(argon2id, "m=64", "m=128", 1)},
+		{name: "argon2id higher memory", hasher: &Argon2idPasswordHasher{Memory: 128, Time: 1, Threads: 1}, hash: argon2id, want: true},
+		{name: "argon2id higher time", hasher: &Argon2idPasswordHasher{Memory: 64, Time: 2, Threads: 1}, hash: argon2id, want: true},
+		{name: "argon2id higher threads", hasher: &Argon2idPasswordHasher{Memory: 64, Time: 1, Threads: 2}, hash: argon2id, want: true},
+		{name: "argon2id defaults", hasher: &Argon2idPasswordHasher{}, hash: argon2id, want: true},
+		{name: "bcrypt by argon2id", hasher: weak, hash: bcrypt, want: true},
+		{name: "bcrypt same cost", hasher: &BCryptPasswordHasher{Cost: 4}, hash: bcrypt},
+		{name: "bcrypt higher cost", hasher: &BCryptPasswordHasher{Cost: 5}, hash: bcrypt, want: true},
+		{name: "argon2id by bcrypt", hasher: &BCryptPasswordHasher{Cost: 4}, hash: argon2id, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthRehashPassword(t *testing.T) {
+	ctx := context.Background()
+	userRepository := memory.NewUserRepository()
+	newAuthService := func(hasher PasswordHasher) *AuthService {
+		return NewAuthService(
+			&UuidGenerator{},
+			hasher,
+			userRepository,
+			memory.NewTokenRepository(time.Hour),
+			memory.NewItemRepository(),
+			memory.NewAttemptRepository(),
+			memory.NewSRPExchangeRepository(),
+			[]byte("secret"),
+			time.Hour,
+		)
+	}
+	if _, err := newAuthService(&BCryptPasswordHasher{Cost: 4}).Register(ctx, "user", "password", Device{}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newAuthService(&Argon2idPasswordHasher{Memory: 64, Time: 1, Threads: 1})
+	if _, err := s.Auth(ctx, "user", "passwore", Device{}); err != ErrUserInvalidPassword {
+		t.Fatalf("Auth() error = %v, want %v", err, ErrUserInvalidPassword)
+	}
+	user, err := userRepository.GetByLogin(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(user.PasswordHash, argon2idPrefix) {
+		t.Fatal("password hash is upgraded after wrong password")
+	}
+
+	if _, err := s.Auth(ctx, "user", "password", Device{}); err != nil {
+		t.Fatal(err)
+	}
+	if user, err = userRepository.GetByLogin(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(user.PasswordHash, argon2idPrefix) {
+		t.Fatalf("password hash %s is not upgraded", user.PasswordHash)
+	}
+	if _, err := s.Auth(ctx, "user", "password", Device{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "keeper/gen/service"
+)
+
+const refreshTokenMethod = "/keeper.KeeperService/RefreshToken"
+
+var (
+	ErrNoRefreshToken = errors.New("refresh token is not available")
+)
+
+// TokenStore interface describe requirements for client tokens storage used by TokenRefresher.
+type TokenStore interface {
+	RefreshToken() (string, error)
+	UpdateToken(token string, refreshToken string) error
+}
+
+// TokenRefresher implement GRPC client interceptors receiving new access token by refresh token
+// when call fails with Unauthenticated code, the call is retried once with new access token.
+type TokenRefresher struct {
+	store  TokenStore
+	client pb.KeeperServiceClient
+
+	mu sync.Mutex
+	// refreshed maps expired access tokens to new ones, so later calls with expired token don't refresh it again.
+	refreshed map[string]string
+}
+
+// NewTokenRefresher construct TokenRefresher, new access and refresh tokens are saved to store.
+func NewTokenRefresher(store TokenStore) *TokenRefresher {
+	return &TokenRefresher{
+		store:     store,
+		refreshed: map[string]string{},
+	}
+}
+
+// SetConn set connection for RefreshToken rpc calls, usually it is the connection interceptors are used with.
+func (r *TokenRefresher) SetConn(conn grpc.ClientConnInterface) {
+	r.client = pb.NewKeeperServiceClient(conn)
+}
+
+// UnaryInterceptor return interceptor refreshing access token of unary calls.
+func (r *TokenRefresher) UnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		token := outgoingToken(ctx)
+		if token == "" || method == refreshTokenMethod {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		token = r.currentToken(token)
+		err := invoker(withOutgoingToken(ctx, token), method, req, reply, cc, opts...)
+		if status.Code(err) != codes.Unauthenticated {
+			return err
+		}
+		newToken, refreshErr := r.refresh(ctx, token)
+		if refreshErr != nil {
+			return err
+		}
+		return invoker(withOutgoingToken(ctx, newToken), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamInterceptor return interceptor refreshing access token of server streaming calls.
+// Stream is reopened only when it fails before first received message.
+func (r *TokenRefresher) StreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		token := outgoingToken(ctx)
+		if token == "" || desc.ClientStreams {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		token = r.currentToken(token)
+		stream, err := streamer(withOutgoingToken(ctx, token), desc, cc, method, opts...)
+		if err != nil {
+			return nil, err
+		}
+		reopen := func(ctx context.Context) (grpc.ClientStream, error) {
+			newToken, err := r.refresh(ctx, token)
+			if err != nil {
+				return nil, err
+			}
+			return streamer(withOutgoingToken(ctx, newToken), desc, cc, method, opts...)
+		}
+		return &refreshingStream{ClientStream: stream, ctx: ctx, reopen: reopen}, nil
+	}
+}
+
+// currentToken return the latest access token refreshed from token.
+func (r *TokenRefresher) currentToken(token string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for {
+		newToken, ok := r.refreshed[token]
+		if !ok {
+			return token
+		}
+		token = newToken
+	}
+}
+
+// refresh receive new access and refresh tokens instead of expired ones and save them,
+// concurrent calls refresh token once since refresh token can be used only once.
+func (r *TokenRefresher) refresh(ctx context.Context, expired string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token, ok := r.refreshed[expired]; ok {
+		return token, nil
+	}
+	if r.client == nil {
+		return "", ErrNoRefreshToken
+	}
+	refreshToken, err := r.store.RefreshToken()
+	if err != nil {
+		return "", err
+	}
+	if refreshToken == "" {
+		return "", ErrNoRefreshToken
+	}
+	req := pb.RefreshTokenRequest{
+		RefreshToken: refreshToken,
+	}
+	res, err := r.client.RefreshToken(metadata.NewOutgoingContext(ctx, metadata.MD{}), &req)
+	if err != nil {
+		return "", err
+	}
+	if err := r.store.UpdateToken(res.GetToken(), res.GetRefreshToken()); err != nil {
+		return "", err
+	}
+	r.refreshed[expired] = res.GetToken()
+	return res.GetToken(), nil
+}
+
+// refreshingStream wrap client stream for reopening it with new access token when it fails with Unauthenticated code.
+type refreshingStream struct {
+	grpc.ClientStream
+	ctx      context.Context
+	reopen   func(ctx context.Context) (grpc.ClientStream, error)
+	sent     []interface{}
+	closed   bool
+	received bool
+}
+
+// SendMsg send message and keep it for resending to reopened stream.
+func (s *refreshingStream) SendMsg(m interface{}) error {
+	s.sent = append(s.sent, m)
+	return s.ClientStream.SendMsg(m)
+}
+
+// CloseSend close stream sending direction.
+func (s *refreshingStream) CloseSend() error {
+	s.closed = true
+	return s.ClientStream.CloseSend()
+}
+
+// RecvMsg receive message, stream is reopened once when it fails before first message.
+func (s *refreshingStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.received = true
+		return nil
+	}
+	if s.received || s.reopen == nil || status.Code(err) != codes.Unauthenticated {
+		return err
+	}
+	reopen := s.reopen
+	s.reopen = nil
+	stream, reopenErr := reopen(s.ctx)
+	if reopenErr != nil {
+		return err
+	}
+	for _, msg := range s.sent {
+		if err := stream.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+	if s.closed {
+		if err := stream.CloseSend(); err != nil {
+			return err
+		}
+	}
+	s.ClientStream = stream
+	return s.RecvMsg(m)
+}
+
+// outgoingToken return access token from outgoing context metadata.
+func outgoingToken(ctx context.Context) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get("token")
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// withOutgoingToken replace access token in outgoing context metadata.
+func withOutgoingToken(ctx context.Context, token string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set("token", token)
+	return metadata.NewOutgoingContext(ctx, md)
+}
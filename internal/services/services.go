@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -15,30 +16,71 @@ type IdGenerator interface {
 }
 
 // PasswordHasher interface describe required logic for hashing and checking user passwords.
+// NeedsRehash reports hashes of other algorithm or weaker parameters, they are replaced after successful login.
 type PasswordHasher interface {
 	Generate(password string) (string, error)
 	Check(password string, hash string) bool
+	NeedsRehash(hash string) bool
 }
 
 // UserRepository interface describe required logic for storing users.
+// Update methods change only their fields, so concurrent updates of different fields don't undo each other.
+// UpdateSRP clears password hash, users with SRP verifier have no password.
+// SwapOTP should replace two-factor authentication state atomically only while it still equals old one,
+// otherwise repository.ErrOTPChanged is returned, so one-time password can't be used by concurrent requests twice.
 type UserRepository interface {
 	Create(ctx context.Context, user entity.User) error
 	GetByLogin(ctx context.Context, login string) (entity.User, error)
+	UpdatePasswordHash(ctx context.Context, login string, passwordHash string) error
+	UpdateKDF(ctx context.Context, login string, kdf entity.KDF, previousKDF *entity.KDF) error
+	SwapOTP(ctx context.Context, login string, old *entity.OTP, otp *entity.OTP) error
+	UpdateSRP(ctx context.Context, login string, srp *entity.SRP) error
+	Delete(ctx context.Context, login string) error
 }
 
 // TokenRepository interface describe required logic for storing user tokens.
+// RotateToken should replace token hashes atomically only while refresh token hash is still refreshHash,
+// otherwise repository.ErrTokenRotated is returned.
 type TokenRepository interface {
-	CreateToken(ctx context.Context, user entity.User) (string, error)
+	CreateToken(ctx context.Context, token entity.Token) error
 	GetToken(ctx context.Context, id string) (entity.Token, error)
+	UpdateTokenUsage(ctx context.Context, id string, ip string, usedAt time.Time) error
+	RotateToken(ctx context.Context, id string, refreshHash string, accessHash string, newRefreshHash string, expiresAt time.Time) error
+	FindTokensByUser(ctx context.Context, login string) ([]entity.Token, error)
+	DeleteToken(ctx context.Context, id string) error
+	DeleteTokensByUser(ctx context.Context, login string) error
+}
+
+// AttemptRepository interface describe required logic for storing failed login attempts counters.
+// ReserveAttempt should check counter by allowed and increment it atomically, counter older than window
+// starts from scratch. Refused attempt is not counted, current counter is returned for it.
+type AttemptRepository interface {
+	GetAttempts(ctx context.Context, key string) (entity.Attempts, error)
+	ReserveAttempt(ctx context.Context, key string, at time.Time, window time.Duration, allowed func(entity.Attempts) bool) (entity.Attempts, bool, error)
+	ReleaseAttempt(ctx context.Context, key string) error
+	ResetAttempts(ctx context.Context, key string) error
+}
+
+// SRPExchangeRepository interface describe required logic for storing started SRP-6a login exchanges.
+// TakeExchange should return and remove exchange atomically, so every exchange is checked once.
+type SRPExchangeRepository interface {
+	CreateExchange(ctx context.Context, exchange entity.SRPExchange) error
+	TakeExchange(ctx context.Context, id string) (entity.SRPExchange, error)
 }
 
 // ItemRepository interface describe required logic for storing items.
 type ItemRepository interface {
 	Create(ctx context.Context, item entity.Item) error
-	Update(ctx context.Context, item entity.Item) error
+	Update(ctx context.Context, item entity.Item, revision int64) error
+	UpdateDataKey(ctx context.Context, item entity.Item, versionKeys [][]byte, revision int64) error
+	Replace(ctx context.Context, item entity.Item, revision int64) error
 	GetByUserIDAndName(ctx context.Context, userID string, name string) (entity.Item, error)
-	Delete(ctx context.Context, item entity.Item) error
-	FindByUser(ctx context.Context, userID string) ([]string, error)
+	Delete(ctx context.Context, item entity.Item, revision int64) error
+	DeleteByUser(ctx context.Context, userID string) error
+	FindByUser(ctx context.Context, userID string, limit int, after string) ([]entity.ItemSummary, error)
+	FindByFilter(ctx context.Context, userID string, filter entity.ItemFilter) ([]entity.ItemSummary, error)
+	FindVersions(ctx context.Context, userID string, name string) ([]entity.Item, error)
+	FindChanges(ctx context.Context, userID string, since time.Time) ([]entity.Item, []string, error)
 }
 
 // UuidGenerator contains implementation for UUID generation.
@@ -56,19 +98,27 @@ type BCryptPasswordHasher struct {
 
 // Generate creates password hash.
 func (ph *BCryptPasswordHasher) Generate(password string) (string, error) {
-	cost := ph.Cost
-	if cost == 0 {
-		cost = 13
-	}
-	b, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	b, err := bcrypt.GenerateFromPassword([]byte(password), ph.cost())
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
 
-// Check compare password and password hash.
+// Check compare password and password hash, Argon2id hashes are checked too.
 func (ph *BCryptPasswordHasher) Check(password string, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	return checkPasswordHash(password, hash)
+}
+
+// NeedsRehash report that hash is not bcrypt hash or its cost is lower than configured.
+func (ph *BCryptPasswordHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < ph.cost()
+}
+
+func (ph *BCryptPasswordHasher) cost() int {
+	if ph.Cost == 0 {
+		return defaultBCryptCost
+	}
+	return ph.Cost
 }
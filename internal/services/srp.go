@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"keeper/internal/entity"
+	"keeper/internal/repository"
+	"keeper/pkg/srp"
+)
+
+const (
+	srpExchangeLifetime = time.Minute
+	// srpVerifierMaxLength is size in bytes of SRP group prime, verifiers are never longer.
+	srpVerifierMaxLength = 256
+)
+
+var (
+	ErrSRPProofRequired  = errors.New("account uses zero-knowledge login, password proof is required")
+	ErrSRPServerProof    = errors.New("server failed to prove password verifier knowledge")
+	ErrSRPInvalidRequest = errors.New("invalid zero-knowledge login request")
+)
+
+// RegisterSRP creates new user with zero-knowledge login, server stores only SRP-6a password verifier.
+// It returns new user access and refresh tokens issued to device with user vault key derivation parameters.
+func (s *AuthService) RegisterSRP(ctx context.Context, login string, verifier SRPVerifier, device Device) (AuthResult, error) {
+	var fields FieldErrors
+	if len(login) < loginMinLength {
+		field := FieldError{
+			Field: "login",
+			Error: fmt.Sprintf("length should be greater or equal %d", loginMinLength),
+		}
+		fields = append(fields, field)
+	}
+	if err := validateSRPVerifier(verifier); err != nil {
+		fields = append(fields, err...)
+	}
+	if len(fields) > 0 {
+		return AuthResult{}, fields
+	}
+
+	kdf, err := NewKDF()
+	if err != nil {
+		return AuthResult{}, err
+	}
+	user := entity.User{
+		ID:        s.idGenerator.Generate(),
+		Login:     login,
+		KDF:       kdfServiceToKDFEntity(kdf),
+		SRP:       srpVerifierToSRPEntity(verifier),
+		CreatedAt: time.Now(),
+	}
+	if err := s.userRepository.Create(ctx, user); err != nil {
+		return AuthResult{}, err
+	}
+	token, refreshToken, err := s.createToken(ctx, user, device)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("new user token generate: %w", err)
+	}
+	return userEntityToAuthResult(user, token, refreshToken), nil
+}
+
+// StartSRPLogin start SRP-6a exchange for client public value and return exchange ID with password stretching
+// parameters and server public value. Exchange is finished by FinishSRPLogin or proof of sensitive account changes.
+// Every call starts separate exchange, so concurrent logins don't interfere. Unknown logins and accounts
+// without zero-knowledge login get fake challenge, so they can't be told apart before FinishSRPLogin fails.
+func (s *AuthService) StartSRPLogin(ctx context.Context, login string, clientPublic []byte, ip string) (SRPChallenge, error) {
+	if len(clientPublic) == 0 || len(clientPublic) > srpVerifierMaxLength {
+		return SRPChallenge{}, ErrSRPInvalidRequest
+	}
+	if err := s.checkAttempts(ctx, login, ip); err != nil {
+		return SRPChallenge{}, err
+	}
+	user, err := s.userRepository.GetByLogin(ctx, login)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return SRPChallenge{}, err
+	}
+	verifier := user.SRP
+	if verifier == nil {
+		verifier = s.fakeSRPVerifier(login)
+	}
+
+	serverSecret, serverPublic, err := srp.ServerKey(verifier.Verifier)
+	if err != nil {
+		return SRPChallenge{}, fmt.Errorf("srp server key generate: %w", err)
+	}
+	id, err := randomString(tokenIDLength)
+	if err != nil {
+		return SRPChallenge{}, fmt.Errorf("srp exchange id generate: %w", err)
+	}
+	exchange := entity.SRPExchange{
+		ID:           id,
+		UserLogin:    login,
+		ClientPublic: clientPublic,
+		ServerSecret: serverSecret,
+		ExpiresAt:    time.Now().Add(srpExchangeLifetime),
+	}
+	if err := s.srpRepository.CreateExchange(ctx, exchange); err != nil {
+		return SRPChallenge{}, err
+	}
+	return SRPChallenge{
+		ExchangeID:   id,
+		KDF:          kdfEntityToKDFService(verifier.KDF),
+		ServerPublic: serverPublic,
+	}, nil
+}
+
+// FinishSRPLogin check client proof of started SRP-6a exchange and return new user access and refresh tokens
+// issued to device with server proof. Users with two-factor authentication get only login challenge
+// for VerifyOTP call instead. Wrong proofs are counted as failed login attempts.
+func (s *AuthService) FinishSRPLogin(ctx context.Context, login string, proof SRPProof, device Device) (AuthResult, error) {
+	if err := s.reserveAttempt(ctx, login, device.IP); err != nil {
+		return AuthResult{}, err
+	}
+	user, err := s.userRepository.GetByLogin(ctx, login)
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return AuthResult{}, err
+	}
+	// Unknown login fails like wrong password, its exchange was started with fake challenge.
+	serverProof, err := s.checkSRPProof(ctx, login, user.SRP, proof)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if serverProof == nil {
+		return AuthResult{}, ErrUserInvalidPassword
+	}
+
+	if user.OTP != nil && user.OTP.Secret != nil {
+		if err := s.releaseAttempt(ctx, login, device.IP); err != nil {
+			return AuthResult{}, err
+		}
+		challenge, err := s.startOTPChallenge(ctx, user)
+		if err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{OTPChallenge: challenge, SRPServerProof: serverProof}, nil
+	}
+	if err := s.resetAttempts(ctx, login, device.IP); err != nil {
+		return AuthResult{}, err
+	}
+
+	token, refreshToken, err := s.createToken(ctx, user, device)
+	if err != nil {
+		return AuthResult{}, fmt.Errorf("user token generate: %w", err)
+	}
+	result := userEntityToAuthResult(user, token, refreshToken)
+	result.SRPServerProof = serverProof
+	return result, nil
+}
+
+// SetSRPVerifier replace user password with SRP-6a password verifier, so password is not sent to server anymore.
+// Users with password login confirm it by current password, users with zero-knowledge login by proof
+// of started SRP exchange. User sessions except session of access token are revoked.
+func (s *AuthService) SetSRPVerifier(ctx context.Context, login string, token string, password string, proof SRPProof, verifier SRPVerifier, ip string) error {
+	if err := validateSRPVerifier(verifier); err != nil {
+		return err
+	}
+	if _, err := s.verifyCredentials(ctx, login, password, proof, ip); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.UpdateSRP(ctx, login, srpVerifierToSRPEntity(verifier)); err != nil {
+		return err
+	}
+	return s.revokeOtherSessions(ctx, login, token)
+}
+
+// checkSRPProof check client proof of started SRP exchange against user verifier and return server proof,
+// nil server proof means wrong proof. Exchange is removed by check, so every exchange allows one proof attempt.
+func (s *AuthService) checkSRPProof(ctx context.Context, login string, verifier *entity.SRP, proof SRPProof) ([]byte, error) {
+	exchange, err := s.srpRepository.TakeExchange(ctx, proof.ExchangeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSRPExchangeNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if verifier == nil || exchange.UserLogin != login || time.Now().After(exchange.ExpiresAt) {
+		return nil, nil
+	}
+	serverProof, _ := srp.VerifyClient(login, verifier.KDF.Salt, verifier.Verifier, exchange.ClientPublic, exchange.ServerSecret, proof.Proof)
+	return serverProof, nil
+}
+
+// fakeSRPVerifier return verifier of unknown login or account without zero-knowledge login.
+// It is derived from server secret, so repeated challenges for the same login don't differ
+// from challenges of real account.
+func (s *AuthService) fakeSRPVerifier(login string) *entity.SRP {
+	derive := func(purpose string) []byte {
+		mac := hmac.New(sha256.New, s.srpSecret)
+		mac.Write([]byte(purpose + ":" + login))
+		return mac.Sum(nil)
+	}
+	return &entity.SRP{
+		KDF: entity.KDF{
+			Version: KDFVersionArgon2idAD,
+			Salt:    derive("salt")[:kdfSaltLength],
+			Time:    kdfDefaultTime,
+			Memory:  kdfDefaultMemory,
+			Threads: kdfDefaultThreads,
+		},
+		Verifier: derive("verifier"),
+	}
+}
+
+// validateSRPVerifier check verifier is not empty and password stretching parameters are not weaker than minimal ones.
+func validateSRPVerifier(verifier SRPVerifier) FieldErrors {
+	var fields FieldErrors
+	if err := validateKDF(verifier.KDF); err != nil {
+		var kdfFields FieldErrors
+		if errors.As(err, &kdfFields) {
+			for _, f := range kdfFields {
+				fields = append(fields, FieldError{Field: "verifier." + f.Field, Error: f.Error})
+			}
+		}
+	}
+	if len(verifier.Verifier) == 0 || len(verifier.Verifier) > srpVerifierMaxLength {
+		fields = append(fields, FieldError{
+			Field: "verifier.verifier",
+			Error: fmt.Sprintf("length should be between 1 and %d", srpVerifierMaxLength),
+		})
+	}
+	if len(fields) > 0 {
+		return fields
+	}
+	return nil
+}
+
+func srpVerifierToSRPEntity(verifier SRPVerifier) *entity.SRP {
+	return &entity.SRP{
+		KDF:      kdfServiceToKDFEntity(verifier.KDF),
+		Verifier: verifier.Verifier,
+	}
+}
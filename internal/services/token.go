@@ -2,8 +2,26 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
 
 	"keeper/internal/entity"
+	"keeper/internal/repository"
+)
+
+const (
+	// tokenUsageInterval is minimal interval between token last usage updates, so not every request writes to storage.
+	tokenUsageInterval = time.Minute
+	tokenIDLength      = 16
+	tokenSecretLength  = 32
+	// tokenSeparator separates stored token ID and secret in access and refresh tokens.
+	tokenSeparator = "."
 )
 
 // TokenService implement logic for working with user tokens.
@@ -20,11 +38,70 @@ func NewTokenService(userRepository UserRepository, tokenRepository TokenReposit
 	}
 }
 
-// GetUser returns user by token.
-func (s *TokenService) GetUser(ctx context.Context, token string) (entity.User, error) {
-	te, err := s.tokenRepository.GetToken(ctx, token)
+// GetUser returns user by access token and track token usage from client IP.
+func (s *TokenService) GetUser(ctx context.Context, token string, ip string) (entity.User, error) {
+	te, secret, err := getToken(ctx, s.tokenRepository, token)
 	if err != nil {
 		return entity.User{}, err
 	}
+	if !checkTokenSecret(secret, te.AccessHash) {
+		return entity.User{}, repository.ErrTokenNotFound
+	}
+	now := time.Now()
+	if now.After(te.AccessExpiresAt) {
+		return entity.User{}, repository.ErrTokenExpired
+	}
+	if now.Sub(te.LastUsedAt) >= tokenUsageInterval || te.Device.IP != ip {
+		if err := s.tokenRepository.UpdateTokenUsage(ctx, te.ID, ip, now); err != nil {
+			return entity.User{}, err
+		}
+	}
 	return s.userRepository.GetByLogin(ctx, te.UserLogin)
 }
+
+// getToken return stored token and secret of access or refresh token, secret should be checked by caller.
+func getToken(ctx context.Context, tokenRepository TokenRepository, token string) (entity.Token, string, error) {
+	id, secret, ok := strings.Cut(token, tokenSeparator)
+	if !ok || id == "" || secret == "" {
+		return entity.Token{}, "", repository.ErrTokenNotFound
+	}
+	te, err := tokenRepository.GetToken(ctx, id)
+	if err != nil {
+		return entity.Token{}, "", err
+	}
+	return te, secret, nil
+}
+
+// tokenStoredID return ID of stored token access or refresh token belongs to.
+func tokenStoredID(token string) string {
+	id, _, _ := strings.Cut(token, tokenSeparator)
+	return id
+}
+
+// newTokenSecret return token with new random secret for stored token ID and secret hash to store.
+func newTokenSecret(id string) (string, string, error) {
+	secret, err := randomString(tokenSecretLength)
+	if err != nil {
+		return "", "", err
+	}
+	return id + tokenSeparator + secret, tokenHash(secret), nil
+}
+
+// tokenHash return hash of token secret, so token itself is never stored and can't be taken from storage.
+func tokenHash(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// checkTokenSecret compare token secret with stored hash in constant time.
+func checkTokenSecret(secret string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(tokenHash(secret)), []byte(hash)) == 1
+}
+
+func randomString(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,56 @@
+// Package bolt provides a convenience functions for working with embedded bbolt database file.
+package bolt
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+const (
+	// SyncAlways fsync database file on every write transaction commit.
+	SyncAlways = "always"
+	// SyncInterval fsync database file periodically, last writes can be lost on OS crash.
+	SyncInterval = "interval"
+	// SyncNever leave fsync of database file to OS.
+	SyncNever = "never"
+)
+
+// NewDB open or create bbolt database file with provided fsync policy.
+func NewDB(path string, syncPolicy string, syncInterval time.Duration) (*bbolt.DB, error) {
+	options := bbolt.Options{
+		Timeout: time.Second,
+	}
+	switch syncPolicy {
+	case SyncAlways:
+	case SyncInterval, SyncNever:
+		options.NoSync = true
+	default:
+		return nil, fmt.Errorf("not supported sync policy: %s", syncPolicy)
+	}
+
+	db, err := bbolt.Open(path, 0600, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	if syncPolicy == SyncInterval {
+		go func() {
+			ticker := time.NewTicker(syncInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				// Read transaction keeps database open while syncing.
+				err := db.View(func(*bbolt.Tx) error {
+					return db.Sync()
+				})
+				if errors.Is(err, bbolt.ErrDatabaseNotOpen) {
+					return
+				}
+			}
+		}()
+	}
+
+	return db, nil
+}
@@ -0,0 +1,46 @@
+package bolt
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+func TestNewDB(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantNoSync bool
+		wantErr    bool
+	}{
+		{policy: SyncAlways, wantNoSync: false},
+		{policy: SyncInterval, wantNoSync: true},
+		{policy: SyncNever, wantNoSync: true},
+		{policy: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		db, err := NewDB(filepath.Join(t.TempDir(), "keeper.db"), tt.policy, time.Millisecond)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: NewDB() error = %v, wantErr %v", tt.policy, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if db.NoSync != tt.wantNoSync {
+			t.Errorf("%s: NoSync = %v, want %v", tt.policy, db.NoSync, tt.wantNoSync)
+		}
+		err = db.Update(func(tx *bbolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists([]byte("test"))
+			return err
+		})
+		if err != nil {
+			t.Errorf("%s: write to database: %v", tt.policy, err)
+		}
+		// Periodic sync should stop after database is closed.
+		time.Sleep(5 * time.Millisecond)
+		if err := db.Close(); err != nil {
+			t.Errorf("%s: Close() error = %v", tt.policy, err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package certs provides a convenience functions for TLS configuration and development certificates generating.
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Generated development certificate file names.
+const (
+	CAFile        = "ca.pem"
+	CAKeyFile     = "ca-key.pem"
+	ServerFile    = "server.pem"
+	ServerKeyFile = "server-key.pem"
+	ClientFile    = "client.pem"
+	ClientKeyFile = "client-key.pem"
+)
+
+const (
+	organization   = "Keeper Development"
+	serialBitsSize = 128
+)
+
+// ServerConfig construct server TLS config from certificate and key files.
+// Not empty client CA file enables mutual TLS, client certificates signed by one of its CAs are required.
+func ServerConfig(certFile string, keyFile string, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+	cfg := tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pool, err := loadPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return &cfg, nil
+}
+
+// ClientConfig construct client TLS config.
+// Empty CA file means system CA bundle, certificate and key files are required only by servers with mutual TLS,
+// server name overrides host name from server address for certificate verification.
+func ClientConfig(caFile string, certFile string, keyFile string, serverName string) (*tls.Config, error) {
+	cfg := tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if caFile != "" {
+		pool, err := loadPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return &cfg, nil
+}
+
+// Generate write to dir self-signed development CA with server certificate for hosts and client certificate.
+// Hosts can be DNS names or IP addresses, private keys are written readable only by owner.
+func Generate(dir string, hosts []string, validity time.Duration) error {
+	if len(hosts) == 0 {
+		return errors.New("at least one server host is required")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create certificates directory: %w", err)
+	}
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := time.Now().Add(validity)
+
+	caTemplate := x509.Certificate{
+		Subject:               pkix.Name{Organization: []string{organization}, CommonName: "Keeper Development CA"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caCert, caKey, err := generateCert(&caTemplate, nil, nil)
+	if err != nil {
+		return fmt.Errorf("generate CA certificate: %w", err)
+	}
+
+	serverTemplate := x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{organization}, CommonName: hosts[0]},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+	serverCert, serverKey, err := generateCert(&serverTemplate, caCert, caKey)
+	if err != nil {
+		return fmt.Errorf("generate server certificate: %w", err)
+	}
+
+	clientTemplate := x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{organization}, CommonName: "keeper-client"},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientCert, clientKey, err := generateCert(&clientTemplate, caCert, caKey)
+	if err != nil {
+		return fmt.Errorf("generate client certificate: %w", err)
+	}
+
+	files := []struct {
+		name string
+		cert *x509.Certificate
+		key  *ecdsa.PrivateKey
+	}{
+		{name: CAFile, cert: caCert},
+		{name: CAKeyFile, key: caKey},
+		{name: ServerFile, cert: serverCert},
+		{name: ServerKeyFile, key: serverKey},
+		{name: ClientFile, cert: clientCert},
+		{name: ClientKeyFile, key: clientKey},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if f.cert != nil {
+			err = writePEM(path, 0644, "CERTIFICATE", f.cert.Raw)
+		} else {
+			var der []byte
+			der, err = x509.MarshalPKCS8PrivateKey(f.key)
+			if err == nil {
+				err = writePEM(path, 0600, "PRIVATE KEY", der)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("write %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// generateCert create certificate from template signed by parent, nil parent means self-signed certificate.
+func generateCert(template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialBitsSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func writePEM(path string, perm os.FileMode, blockType string, der []byte) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	return os.WriteFile(path, data, perm)
+}
+
+func loadPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA bundle %s", caFile)
+	}
+	return pool, nil
+}
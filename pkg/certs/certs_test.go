@@ -0,0 +1,140 @@
+package certs
+
+import (
+	"crypto/tls"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// handshake run TLS handshake of server and client configs over loopback connection.
+func handshake(t *testing.T, serverCfg *tls.Config, clientCfg *tls.Config) (serverErr error, clientErr error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer listener.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			errs <- err
+			return
+		}
+		//goland:noinspection GoUnhandledErrorResult
+		defer conn.Close()
+		errs <- tls.Server(conn, serverCfg).Handshake()
+	}()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientErr = tls.Client(conn, clientCfg).Handshake()
+	// Client finishes TLS 1.3 handshake before server verifies client certificate.
+	serverErr = <-errs
+	//goland:noinspection GoUnhandledErrorResult
+	conn.Close()
+	return serverErr, clientErr
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	if err := Generate(dir, []string{"localhost", "127.0.0.1"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{CAKeyFile, ServerKeyFile, ClientKeyFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s permissions = %v, want 0600", name, info.Mode().Perm())
+		}
+	}
+	if err := Generate(t.TempDir(), nil, time.Hour); err == nil {
+		t.Error("Generate() without hosts succeeded")
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	dir := t.TempDir()
+	if err := Generate(dir, []string{"localhost", "127.0.0.1"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+	otherDir := t.TempDir()
+	if err := Generate(otherDir, []string{"localhost"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		clientCAFile string
+		caFile       string
+		certFile     string
+		keyFile      string
+		serverName   string
+		wantErr      bool
+	}{
+		{name: "TLS", caFile: path(CAFile), serverName: "localhost"},
+		{name: "TLS with IP address", caFile: path(CAFile), serverName: "127.0.0.1"},
+		{name: "unknown server name", caFile: path(CAFile), serverName: "keeper.example", wantErr: true},
+		{name: "other CA", caFile: filepath.Join(otherDir, CAFile), serverName: "localhost", wantErr: true},
+		{
+			name:         "mutual TLS",
+			clientCAFile: path(CAFile),
+			caFile:       path(CAFile),
+			certFile:     path(ClientFile),
+			keyFile:      path(ClientKeyFile),
+			serverName:   "localhost",
+		},
+		{name: "mutual TLS without client certificate", clientCAFile: path(CAFile), caFile: path(CAFile), serverName: "localhost", wantErr: true},
+		{
+			name:         "mutual TLS with other CA client certificate",
+			clientCAFile: path(CAFile),
+			caFile:       path(CAFile),
+			certFile:     filepath.Join(otherDir, ClientFile),
+			keyFile:      filepath.Join(otherDir, ClientKeyFile),
+			serverName:   "localhost",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		serverCfg, err := ServerConfig(path(ServerFile), path(ServerKeyFile), tt.clientCAFile)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		clientCfg, err := ClientConfig(tt.caFile, tt.certFile, tt.keyFile, tt.serverName)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		serverErr, clientErr := handshake(t, serverCfg, clientCfg)
+		if gotErr := serverErr != nil || clientErr != nil; gotErr != tt.wantErr {
+			t.Errorf("%s: handshake server error = %v, client error = %v, wantErr %v", tt.name, serverErr, clientErr, tt.wantErr)
+		}
+	}
+}
+
+func TestConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := Generate(dir, []string{"localhost"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ServerConfig(filepath.Join(dir, ServerFile), filepath.Join(dir, ClientKeyFile), ""); err == nil {
+		t.Error("ServerConfig() with key of other certificate succeeded")
+	}
+	// Private key is not CA bundle.
+	if _, err := ClientConfig(filepath.Join(dir, CAKeyFile), "", "", ""); err == nil {
+		t.Error("ClientConfig() with bundle without certificates succeeded")
+	}
+	if _, err := ClientConfig("", filepath.Join(dir, ClientFile), "", ""); err == nil {
+		t.Error("ClientConfig() with client certificate without key succeeded")
+	}
+}
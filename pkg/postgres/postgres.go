@@ -0,0 +1,23 @@
+// Package postgres provides a convenience functions for interacting with PostgreSQL database.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	_ "github.com/lib/pq"
+)
+
+// NewDB constructing PostgreSQL connection pool and check database availability.
+func NewDB(ctx context.Context, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		//goland:noinspection GoUnhandledErrorResult
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,207 @@
+// Package srp provides a convenience functions for SRP-6a password authenticated key exchange (RFC 5054)
+// with 2048-bit group and SHA-256, so server verifies user password without receiving it.
+// Functions are stateless, server ephemeral secret is kept by caller between exchange steps.
+package srp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"math/big"
+)
+
+const (
+	// ephemeralLength is length in bytes of random ephemeral secrets.
+	ephemeralLength = 32
+)
+
+var (
+	ErrInvalidPublic = errors.New("invalid srp public value")
+	ErrInvalidProof  = errors.New("invalid srp proof")
+
+	// defaultGroup is 2048-bit group from RFC 5054 appendix A with SHA-256.
+	defaultGroup = newGroup(""+
+		"AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050"+
+		"A37329CBB4A099ED8193E0757767A13DD52312AB4B03310DCD7F48A9DA04FD50"+
+		"E8083969EDB767B0CF6095179A163AB3661A05FBD5FAAAE82918A9962F0B93B8"+
+		"55F97993EC975EEAA80D740ADBF4FF747359D041D5C33EA71D281E446B14773B"+
+		"CA97B43A23FB801676BD207A436C6481F1D2B9078717461A5B9D32E688F87748"+
+		"544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB3786160279004E57AE6"+
+		"AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DBFBB6"+
+		"94B5C803D89F7AE435DE236D525F54759B65E372FCD68EF20FA7111F9E4AFF73", 2, sha256.New)
+)
+
+// group is SRP-6a group parameters with hash function.
+type group struct {
+	n       *big.Int
+	g       *big.Int
+	k       *big.Int
+	newHash func() hash.Hash
+}
+
+// newGroup construct group from hex group prime and generator, multiplier k = H(N | PAD(g)) is calculated.
+func newGroup(prime string, generator int64, newHash func() hash.Hash) *group {
+	n, _ := new(big.Int).SetString(prime, 16)
+	gr := &group{
+		n:       n,
+		g:       big.NewInt(generator),
+		newHash: newHash,
+	}
+	gr.k = new(big.Int).SetBytes(gr.hash(n.Bytes(), gr.pad(gr.g)))
+	return gr
+}
+
+// Verifier return password verifier stored by server instead of password.
+func Verifier(identity string, password []byte, salt []byte) []byte {
+	gr := defaultGroup
+	return gr.verifier(gr.privateKey(identity, password, salt)).Bytes()
+}
+
+// ClientKey return new client ephemeral secret and public value A sent to server.
+func ClientKey() ([]byte, []byte, error) {
+	a, err := randomSecret()
+	if err != nil {
+		return nil, nil, err
+	}
+	return a.Bytes(), defaultGroup.clientPublic(a).Bytes(), nil
+}
+
+// ServerKey return new server ephemeral secret and public value B sent to client for password verifier.
+func ServerKey(verifier []byte) ([]byte, []byte, error) {
+	b, err := randomSecret()
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.Bytes(), defaultGroup.serverPublic(b, new(big.Int).SetBytes(verifier)).Bytes(), nil
+}
+
+// ClientProof return client proof M1 sent to server and server proof M2 expected from server.
+func ClientProof(identity string, password []byte, salt []byte, clientSecret []byte, serverPublic []byte) ([]byte, []byte, error) {
+	gr := defaultGroup
+	a := new(big.Int).SetBytes(clientSecret)
+	clientPublic := gr.clientPublic(a)
+	bigB := new(big.Int).SetBytes(serverPublic)
+	if new(big.Int).Mod(bigB, gr.n).Sign() == 0 {
+		return nil, nil, ErrInvalidPublic
+	}
+	u := gr.scrambling(clientPublic, bigB)
+	if u.Sign() == 0 {
+		return nil, nil, ErrInvalidPublic
+	}
+
+	x := gr.privateKey(identity, password, salt)
+	key := gr.hash(gr.clientPremaster(x, a, u, bigB).Bytes())
+	m1 := gr.clientProof(identity, salt, clientPublic, bigB, key)
+	return m1, gr.serverProof(clientPublic, m1, key), nil
+}
+
+// VerifyClient check client proof M1 and return server proof M2 sent to client.
+func VerifyClient(identity string, salt []byte, verifier []byte, clientPublic []byte, serverSecret []byte, proof []byte) ([]byte, error) {
+	gr := defaultGroup
+	bigA := new(big.Int).SetBytes(clientPublic)
+	if new(big.Int).Mod(bigA, gr.n).Sign() == 0 {
+		return nil, ErrInvalidPublic
+	}
+	b := new(big.Int).SetBytes(serverSecret)
+	v := new(big.Int).SetBytes(verifier)
+	bigB := gr.serverPublic(b, v)
+	u := gr.scrambling(bigA, bigB)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublic
+	}
+
+	key := gr.hash(gr.serverPremaster(bigA, v, u, b).Bytes())
+	expected := gr.clientProof(identity, salt, bigA, bigB, key)
+	if subtle.ConstantTimeCompare(expected, proof) != 1 {
+		return nil, ErrInvalidProof
+	}
+	return gr.serverProof(bigA, proof, key), nil
+}
+
+// VerifyServer check server proof M2 against expected one returned by ClientProof.
+func VerifyServer(expected []byte, proof []byte) bool {
+	return subtle.ConstantTimeCompare(expected, proof) == 1
+}
+
+// privateKey return x = H(s | H(I | ":" | P)).
+func (gr *group) privateKey(identity string, password []byte, salt []byte) *big.Int {
+	inner := gr.hash([]byte(identity), []byte(":"), password)
+	return new(big.Int).SetBytes(gr.hash(salt, inner))
+}
+
+// verifier return v = g^x % N.
+func (gr *group) verifier(x *big.Int) *big.Int {
+	return new(big.Int).Exp(gr.g, x, gr.n)
+}
+
+// clientPublic return A = g^a % N.
+func (gr *group) clientPublic(a *big.Int) *big.Int {
+	return new(big.Int).Exp(gr.g, a, gr.n)
+}
+
+// serverPublic return B = (k * v + g^b) % N.
+func (gr *group) serverPublic(b *big.Int, v *big.Int) *big.Int {
+	public := new(big.Int).Mul(gr.k, v)
+	public.Add(public, new(big.Int).Exp(gr.g, b, gr.n))
+	return public.Mod(public, gr.n)
+}
+
+// scrambling return u = H(PAD(A) | PAD(B)).
+func (gr *group) scrambling(clientPublic *big.Int, serverPublic *big.Int) *big.Int {
+	return new(big.Int).SetBytes(gr.hash(gr.pad(clientPublic), gr.pad(serverPublic)))
+}
+
+// clientPremaster return S = (B - k * g^x) ^ (a + u * x) % N.
+func (gr *group) clientPremaster(x *big.Int, a *big.Int, u *big.Int, serverPublic *big.Int) *big.Int {
+	base := new(big.Int).Mul(gr.k, new(big.Int).Exp(gr.g, x, gr.n))
+	base.Sub(serverPublic, base)
+	base.Mod(base, gr.n)
+	exp := new(big.Int).Mul(u, x)
+	exp.Add(exp, a)
+	return new(big.Int).Exp(base, exp, gr.n)
+}
+
+// serverPremaster return S = (A * v^u) ^ b % N.
+func (gr *group) serverPremaster(clientPublic *big.Int, v *big.Int, u *big.Int, b *big.Int) *big.Int {
+	base := new(big.Int).Mul(clientPublic, new(big.Int).Exp(v, u, gr.n))
+	base.Mod(base, gr.n)
+	return new(big.Int).Exp(base, b, gr.n)
+}
+
+// clientProof return M1 = H(H(N) xor H(g) | H(I) | s | A | B | K).
+func (gr *group) clientProof(identity string, salt []byte, clientPublic *big.Int, serverPublic *big.Int, key []byte) []byte {
+	hn := gr.hash(gr.n.Bytes())
+	hg := gr.hash(gr.g.Bytes())
+	for i := range hn {
+		hn[i] ^= hg[i]
+	}
+	return gr.hash(hn, gr.hash([]byte(identity)), salt, clientPublic.Bytes(), serverPublic.Bytes(), key)
+}
+
+// serverProof return M2 = H(A | M1 | K).
+func (gr *group) serverProof(clientPublic *big.Int, m1 []byte, key []byte) []byte {
+	return gr.hash(clientPublic.Bytes(), m1, key)
+}
+
+// pad return value bytes left padded with zeros to group prime length.
+func (gr *group) pad(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, (gr.n.BitLen()+7)/8))
+}
+
+func (gr *group) hash(parts ...[]byte) []byte {
+	h := gr.newHash()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func randomSecret() (*big.Int, error) {
+	b := make([]byte, ephemeralLength)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,192 @@
+package srp
+
+import (
+	"crypto/sha1"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// rfc5054Group is 1024-bit group with SHA-1 used by RFC 5054 appendix B test vectors.
+var rfc5054Group = newGroup(""+
+	"EEAF0AB9ADB38DD69C33F80AFA8FC5E86072618775FF3C0B9EA2314C9C256576"+
+	"D674DF7496EA81D3383B4813D692C6E0E0D5D8E250B98BE48E495C1D6089DAD1"+
+	"5DC7D7B46154D6B6CE8EF4AD69B15D4982559B297BCF1885C529F566660E57EC"+
+	"68EDBC3C05726CC02FD4CBF4976EAA9AFD5138FE8376435B9FC61D2FC0EB06E3", 2, sha1.New)
+
+func hexInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(strings.ReplaceAll(s, " ", ""), 16)
+	if !ok {
+		t.Fatalf("invalid hex value %q", s)
+	}
+	return v
+}
+
+func TestRFC5054Vectors(t *testing.T) {
+	gr := rfc5054Group
+	identity := "alice"
+	password := []byte("password123")
+	salt := hexInt(t, "BEB25379 D1A8581E B5A72767 3A2441EE").Bytes()
+	a := hexInt(t, "60975527 035CF2AD 1989806F 0407210B C81EDC04 E2762A56 AFD529DD DA2D4393")
+	b := hexInt(t, "E487CB59 D31AC550 471E81F0 0F6928E0 1DDA08E9 74A004F4 9E61F5D1 05284D20")
+
+	x := gr.privateKey(identity, password, salt)
+	v := gr.verifier(x)
+	clientPublic := gr.clientPublic(a)
+	serverPublic := gr.serverPublic(b, v)
+	u := gr.scrambling(clientPublic, serverPublic)
+
+	tests := []struct {
+		name string
+		got  *big.Int
+		want string
+	}{
+		{
+			name: "multiplier k",
+			got:  gr.k,
+			want: "7556AA04 5AEF2CDD 07ABAF0F 665C3E81 8913186F",
+		},
+		{
+			name: "private key x",
+			got:  x,
+			want: "94B7555A ABE9127C C58CCF49 93DB6CF8 4D16C124",
+		},
+		{
+			name: "verifier v",
+			got:  v,
+			want: "7E273DE8 696FFC4F 4E337D05 B4B375BE B0DDE156 9E8FA00A 9886D812 9BADA1F1" +
+				"822223CA 1A605B53 0E379BA4 729FDC59 F105B478 7E5186F5 C671085A 1447B52A" +
+				"48CF1970 B4FB6F84 00BBF4CE BFBB1681 52E08AB5 EA53D15C 1AFF87B2 B9DA6E04" +
+				"E058AD51 CC72BFC9 033B564E 26480D78 E955A5E2 9E7AB245 DB2BE315 E2099AFB",
+		},
+		{
+			name: "client public A",
+			got:  clientPublic,
+			want: "61D5E490 F6F1B795 47B0704C 436F523D D0E560F0 C64115BB 72557EC4 4352E890" +
+				"3211C046 92272D8B 2D1A5358 A2CF1B6E 0BFCF99F 921530EC 8E393561 79EAE45E" +
+				"42BA92AE ACED8251 71E1E8B9 AF6D9C03 E1327F44 BE087EF0 6530E69F 66615261" +
+				"EEF54073 CA11CF58 58F0EDFD FE15EFEA B349EF5D 76988A36 72FAC47B 0769447B",
+		},
+		{
+			name: "server public B",
+			got:  serverPublic,
+			want: "BD0C6151 2C692C0C B6D041FA 01BB152D 4916A1E7 7AF46AE1 05393011 BAF38964" +
+				"DC46A067 0DD125B9 5A981652 236F99D9 B681CBF8 7837EC99 6C6DA044 53728610" +
+				"D0C6DDB5 8B318885 D7D82C7F 8DEB75CE 7BD4FBAA 37089E6F 9C6059F3 88838E7A" +
+				"00030B33 1EB76840 910440B1 B27AAEAE EB4012B7 D7665238 A8E3FB00 4B117B58",
+		},
+		{
+			name: "scrambling u",
+			got:  u,
+			want: "CE38B959 3487DA98 554ED47D 70A7AE5F 462EF019",
+		},
+		{
+			name: "client premaster secret",
+			got:  gr.clientPremaster(x, a, u, serverPublic),
+			want: rfc5054Premaster,
+		},
+		{
+			name: "server premaster secret",
+			got:  gr.serverPremaster(clientPublic, v, u, b),
+			want: rfc5054Premaster,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if want := hexInt(t, tt.want); tt.got.Cmp(want) != 0 {
+				t.Errorf("got %X, want %X", tt.got, want)
+			}
+		})
+	}
+}
+
+const rfc5054Premaster = "" +
+	"B0DC82BA BCF30674 AE450C02 87745E79 90A3381F 63B387AA F271A10D 233861E3" +
+	"59B48220 F7C4693C 9AE12B0A 6F67809F 0876E2D0 13800D6C 41BB59B6 D5979B5C" +
+	"00A172B4 A2A5903A 0BDCAF8A 709585EB 2AFAFA8F 3499B200 210DCC1F 10EB3394" +
+	"3CD67FC8 8A2F39A4 BE5BEC4E C0A3212D C346D7E4 74B29EDE 8A469FFE CA686E5A"
+
+func TestExchange(t *testing.T) {
+	salt := []byte("salt")
+	verifier := Verifier("alice", []byte("password"), salt)
+
+	tests := []struct {
+		name     string
+		identity string
+		password string
+		public   func(clientPublic []byte) []byte
+		wantErr  error
+	}{
+		{
+			name:     "right password",
+			identity: "alice",
+			password: "password",
+		},
+		{
+			name:     "wrong password",
+			identity: "alice",
+			password: "wrong",
+			wantErr:  ErrInvalidProof,
+		},
+		{
+			name:     "wrong identity",
+			identity: "bob",
+			password: "password",
+			wantErr:  ErrInvalidProof,
+		},
+		{
+			name:     "client public is zero",
+			identity: "alice",
+			password: "password",
+			public:   func([]byte) []byte { return []byte{0} },
+			wantErr:  ErrInvalidPublic,
+		},
+		{
+			name:     "client public is group prime",
+			identity: "alice",
+			password: "password",
+			public:   func([]byte) []byte { return defaultGroup.n.Bytes() },
+			wantErr:  ErrInvalidPublic,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientSecret, clientPublic, err := ClientKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverSecret, serverPublic, err := ServerKey(verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proof, expected, err := ClientProof(tt.identity, []byte(tt.password), salt, clientSecret, serverPublic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.public != nil {
+				clientPublic = tt.public(clientPublic)
+			}
+			serverProof, err := VerifyClient("alice", salt, verifier, clientPublic, serverSecret, proof)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyClient() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !VerifyServer(expected, serverProof) {
+				t.Error("server proof is not accepted by client")
+			}
+		})
+	}
+}
+
+func TestClientProofInvalidServerPublic(t *testing.T) {
+	clientSecret, _, err := ClientKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, public := range [][]byte{{0}, defaultGroup.n.Bytes()} {
+		if _, _, err := ClientProof("alice", []byte("password"), []byte("salt"), clientSecret, public); !errors.Is(err, ErrInvalidPublic) {
+			t.Errorf("ClientProof() error = %v, want %v", err, ErrInvalidPublic)
+		}
+	}
+}
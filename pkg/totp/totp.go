@@ -0,0 +1,97 @@
+// Package totp provides a convenience functions for time-based one-time passwords (RFC 6238)
+// compatible with common authenticator applications.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+const (
+	// Digits is number of digits in one-time password.
+	Digits = 6
+	// Period is time step one-time password is valid for.
+	Period = 30 * time.Second
+	// SecretLength is length in bytes of generated secrets, it is HMAC-SHA1 output size recommended by RFC 4226.
+	SecretLength = 20
+
+	modulo = 1_000_000
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret return new random shared secret.
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, SecretLength)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// URI return provisioning URI of secret for authenticator applications, usually shown as QR code.
+func URI(issuer string, account string, secret []byte) string {
+	query := url.Values{}
+	query.Set("secret", encoding.EncodeToString(secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// EncodeSecret return secret in base32 form used for manual entry to authenticator applications.
+func EncodeSecret(secret []byte) string {
+	return encoding.EncodeToString(secret)
+}
+
+// Step return time step number of time.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code return one-time password of secret for time.
+func Code(secret []byte, t time.Time) string {
+	return code(secret, Step(t))
+}
+
+// Validate check one-time password for time allowing skew steps of clock drift in both directions.
+// Matched time step is returned, so caller can reject passwords of already used steps.
+func Validate(secret []byte, password string, t time.Time, skew int) (int64, bool) {
+	if len(password) != Digits {
+		return 0, false
+	}
+	step := Step(t)
+	for i := -int64(skew); i <= int64(skew); i++ {
+		if subtle.ConstantTimeCompare([]byte(code(secret, step+i)), []byte(password)) == 1 {
+			return step + i, true
+		}
+	}
+	return 0, false
+}
+
+// code implement HOTP (RFC 4226) value for counter.
+func code(secret []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%modulo)
+}
@@ -0,0 +1,120 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is SHA-1 secret of RFC 4226 and RFC 6238 test vectors.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCodeRFC6238(t *testing.T) {
+	// RFC 6238 appendix B vectors are 8 digits, 6 digits password is their last 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			want := tt.want[len(tt.want)-Digits:]
+			if got := Code(rfcSecret, time.Unix(tt.unix, 0)); got != want {
+				t.Errorf("Code() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestCodeRFC4226(t *testing.T) {
+	tests := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+	for counter, want := range tests {
+		if got := code(rfcSecret, int64(counter)); got != want {
+			t.Errorf("code(%d) = %s, want %s", counter, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name     string
+		password string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{
+			name:     "current step",
+			password: Code(rfcSecret, now),
+			skew:     1,
+			wantStep: step,
+			wantOK:   true,
+		},
+		{
+			name:     "previous step within skew",
+			password: Code(rfcSecret, now.Add(-Period)),
+			skew:     1,
+			wantStep: step - 1,
+			wantOK:   true,
+		},
+		{
+			name:     "next step within skew",
+			password: Code(rfcSecret, now.Add(Period)),
+			skew:     1,
+			wantStep: step + 1,
+			wantOK:   true,
+		},
+		{
+			name:     "previous step without skew",
+			password: Code(rfcSecret, now.Add(-Period)),
+		},
+		{
+			name:     "step out of skew",
+			password: Code(rfcSecret, now.Add(2*Period)),
+			skew:     1,
+		},
+		{
+			name:     "wrong length",
+			password: Code(rfcSecret, now)[1:],
+			skew:     1,
+		},
+		{
+			name:     "wrong password",
+			password: "000000",
+			skew:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOK := Validate(rfcSecret, tt.password, now, tt.skew)
+			if gotOK != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate() = %d, %v, want %d, %v", gotStep, gotOK, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(URI("Keeper", "user", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Keeper:user" {
+		t.Errorf("unexpected URI %s", u)
+	}
+	if secret := u.Query().Get("secret"); secret != EncodeSecret(rfcSecret) {
+		t.Errorf("secret = %s, want %s", secret, EncodeSecret(rfcSecret))
+	}
+}